@@ -33,6 +33,9 @@ func clientFlags() []cli.Flag {
 		&cli.StringFlag{Name: consts.Template, Usage: "Specify the template path. Currently cwgo supports git templates, such as `--template https://github.com/***/cwgo_template.git`", Destination: &globalArgs.ClientArgument.Template},
 		&cli.StringFlag{Name: consts.Branch, Usage: "Specify the git template's branch, default is main branch.", Destination: &globalArgs.ClientArgument.Branch},
 		&cli.StringFlag{Name: consts.Registry, Usage: "Specify the registry, default is None"},
+		&cli.BoolFlag{Name: consts.TLS, Usage: "Connect to the server with TLS, presenting the client certificate of certs/ for mTLS. (Valid only for RPC clients, HTTP clients take WithTLSConfig)", Destination: &globalArgs.ClientArgument.TLS},
+		&cli.StringFlag{Name: consts.Transport, Usage: "Specify the Kitex transport: ttheader, grpc (protobuf only), framed or buffered (thrift only). (Valid only for RPC clients)", Destination: &globalArgs.ClientArgument.Transport},
+		&cli.StringFlag{Name: consts.Lang, Usage: "Specify the language of the HTTP client: go, ts (TypeScript) or python.", Value: consts.LangGo},
 		&cli.StringSliceFlag{Name: consts.ProtoSearchPath, Aliases: []string{"I"}, Usage: "Add an IDL search path for includes. (Valid only if idl is protobuf)"},
 		&cli.StringSliceFlag{Name: consts.Pass, Usage: "pass param to hz or kitex"},
 		&cli.BoolFlag{Name: consts.Verbose, Usage: "Turn on verbose mode."},
@@ -25,8 +25,11 @@ import (
 	"github.com/cloudwego/cwgo/pkg/curd/doc"
 	"github.com/cloudwego/cwgo/pkg/fallback"
 	"github.com/cloudwego/cwgo/pkg/job"
+	"github.com/cloudwego/cwgo/pkg/mcp"
 	"github.com/cloudwego/cwgo/pkg/model"
 	"github.com/cloudwego/cwgo/pkg/server"
+	"github.com/cloudwego/cwgo/pkg/strategy"
+	"github.com/cloudwego/cwgo/pkg/templates"
 	"github.com/urfave/cli/v2"
 )
 
@@ -118,6 +121,128 @@ func Init() *cli.App {
 				return api_list.Api(globalArgs.ApiArgument)
 			},
 		},
+		{
+			Name:  McpName,
+			Usage: McpUsage,
+			Flags: mcpFlags(),
+			Action: func(c *cli.Context) error {
+				if err := globalArgs.McpArgument.ParseCli(c); err != nil {
+					return err
+				}
+				return mcp.Mcp(globalArgs.McpArgument)
+			},
+		},
+		{
+			Name:  StrategyName,
+			Usage: StrategyUsage,
+			Subcommands: []*cli.Command{
+				{
+					Name:  StrategyAddProcessorName,
+					Usage: StrategyAddProcessorUsage,
+					Flags: strategyAddProcessorFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.StrategyArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return strategy.AddProcessor(globalArgs.StrategyArgument)
+					},
+				},
+				{
+					Name:  StrategyAddStrategyName,
+					Usage: StrategyAddStrategyUsage,
+					Flags: strategyAddStrategyFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.StrategyArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return strategy.AddStrategy(globalArgs.StrategyArgument)
+					},
+				},
+				{
+					Name:  StrategyCheckName,
+					Usage: StrategyCheckUsage,
+					Flags: strategyCommonFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.StrategyArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return strategy.Check(globalArgs.StrategyArgument)
+					},
+				},
+				{
+					Name:  StrategyGraphName,
+					Usage: StrategyGraphUsage,
+					Flags: strategyGraphFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.StrategyArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return strategy.Graph(globalArgs.StrategyArgument)
+					},
+				},
+				{
+					Name:  StrategyListName,
+					Usage: StrategyListUsage,
+					Flags: strategyListFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.StrategyArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return strategy.List(globalArgs.StrategyArgument)
+					},
+				},
+			},
+		},
+		{
+			Name:  TemplateName,
+			Usage: TemplateUsage,
+			Subcommands: []*cli.Command{
+				{
+					Name:  TemplateListName,
+					Usage: TemplateListUsage,
+					Flags: templateListFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.TemplateArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return templates.List(globalArgs.TemplateArgument)
+					},
+				},
+				{
+					Name:  TemplatePullName,
+					Usage: TemplatePullUsage,
+					Flags: templatePullFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.TemplateArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return templates.Pull(globalArgs.TemplateArgument)
+					},
+				},
+				{
+					Name:  TemplateUpdateName,
+					Usage: TemplateUpdateUsage,
+					Flags: templateUpdateFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.TemplateArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return templates.Update(globalArgs.TemplateArgument)
+					},
+				},
+				{
+					Name:  TemplateValidateName,
+					Usage: TemplateValidateUsage,
+					Flags: templateValidateFlags(),
+					Action: func(c *cli.Context) error {
+						if err := globalArgs.TemplateArgument.ParseCli(c); err != nil {
+							return err
+						}
+						return templates.Validate(globalArgs.TemplateArgument)
+					},
+				},
+			},
+		},
 		{
 			Name:  FallbackName,
 			Usage: FallbackUsage,
@@ -215,6 +340,80 @@ Examples:
 Examples:
 	cwgo job --job_name jobOne --job_name jobTwo --module my_job
 `
+	McpName  = "mcp"
+	McpUsage = `generate an MCP server exposing service methods as tools
+
+Examples:
+  # Generate MCP tools forwarding to the kitex client
+  cwgo mcp --type RPC --idl {{path/to/IDL_file.thrift}} --server_name {{svc_name}}
+
+  # Generate MCP tools forwarding to the hertz client of cwgo client --type HTTP
+  cwgo mcp --type HTTP --idl {{path/to/IDL_file.thrift}} --server_name {{svc_name}}
+`
+	StrategyName  = "strategy"
+	StrategyUsage = `manage the processors and strategies of a kitex strategy project
+
+Examples:
+  # Scaffold a processor, register it and append it to the default strategy
+  cwgo strategy add-processor --method {{MethodName}} --name {{processor_name}}
+
+  # Add a strategy built from registered processors
+  cwgo strategy add-strategy --method {{MethodName}} --name {{strategy_name}} --processors log --processors {{processor_name}}
+
+  # List the processors and strategies of every method
+  cwgo strategy list
+
+  # Validate strategy.yaml against the registered processors
+  cwgo strategy check
+
+  # Draw the pipelines of every method as a Graphviz diagram
+  cwgo strategy graph --format dot --out_file strategies.dot
+`
+	StrategyAddProcessorName  = "add-processor"
+	StrategyAddProcessorUsage = "scaffold a processor and register it in the strategies of a method"
+
+	StrategyAddStrategyName  = "add-strategy"
+	StrategyAddStrategyUsage = "add a strategy pipeline of registered processors to strategy.yaml"
+
+	StrategyCheckName  = "check"
+	StrategyCheckUsage = "validate the processors referenced by strategy.yaml against the registered ones"
+
+	StrategyGraphName  = "graph"
+	StrategyGraphUsage = "render the strategy pipelines and their contract violations as Mermaid or DOT diagrams"
+
+	StrategyListName  = "list"
+	StrategyListUsage = "list the registered processors and the strategies of every method"
+
+	TemplateName  = "template"
+	TemplateUsage = `manage the git templates of cwgo server and client
+
+Templates are cached by repository and ref, generating with a cached --template works offline.
+
+Examples:
+  # Cache a template at a tag and pin the project to it in .cwgo.yaml
+  cwgo template pull --repo {{git_url}} --ref v1.0.0 --pin
+
+  # List the builtin and the cached templates
+  cwgo template list
+
+  # Move every cached template to the latest commit of its ref
+  cwgo template update
+
+  # Check a template dir before using it
+  cwgo template validate --dir {{path/to/template}}
+`
+	TemplateListName  = "list"
+	TemplateListUsage = "list the builtin and the cached templates"
+
+	TemplatePullName  = "pull"
+	TemplatePullUsage = "cache a template repository at a ref"
+
+	TemplateUpdateName  = "update"
+	TemplateUpdateUsage = "fetch the cached templates and move them to the latest commit of their ref"
+
+	TemplateValidateName  = "validate"
+	TemplateValidateUsage = "check the layout.yaml, package.yaml or kitex template YAMLs of a template dir"
+
 	FallbackName  = "fallback"
 	FallbackUsage = "fallback to hz or kitex"
 
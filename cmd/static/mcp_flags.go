@@ -0,0 +1,36 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func mcpFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.ServiceType, Usage: "Specify the client the tools forward to. (RPC or HTTP)", Value: consts.RPC},
+		&cli.StringFlag{Name: consts.IDLPath, Usage: "Specify the IDL file path. (.thrift or .proto)"},
+		&cli.StringFlag{Name: consts.Module, Aliases: []string{"mod"}, Usage: "Specify the Go module name, default is read from go.mod."},
+		&cli.StringFlag{Name: consts.OutDir, Usage: "Specify output directory, default is current dir."},
+		&cli.StringFlag{Name: consts.ServerName, Usage: "Specify the name of the service the tools call, used as the kitex destination service and the MCP server name."},
+		&cli.StringFlag{Name: consts.ModelDir, Usage: "Specify the model directory of the module the clients were generated with, default is kitex_gen for RPC and hertz_gen for HTTP."},
+		&cli.StringFlag{Name: consts.ClientDir, Usage: "Specify the directory of the hertz clients generated by cwgo client, default is biz/http. (Valid only for HTTP)"},
+		&cli.StringFlag{Name: consts.Use, Usage: "Specify the import path of the model packages when they are generated in another module."},
+		&cli.StringSliceFlag{Name: consts.ProtoSearchPath, Aliases: []string{"I"}, Usage: "Add an IDL search path for includes."},
+	}
+}
@@ -37,6 +37,16 @@ func serverFlags() []cli.Flag {
 		&cli.StringSliceFlag{Name: consts.Pass, Usage: "Pass param to hz or Kitex."},
 		&cli.BoolFlag{Name: consts.Verbose, Usage: "Turn on verbose mode."},
 		&cli.BoolFlag{Name: consts.HexTag, Usage: "Add HTTP listen for Kitex.", Destination: &globalArgs.Hex},
+		&cli.BoolFlag{Name: consts.Gateway, Usage: "Serve the HTTP routes of the IDL annotations with the Kitex service implementation on the Kitex port, implies --hex.", Destination: &globalArgs.ServerArgument.Gateway},
+		&cli.BoolFlag{Name: consts.TLS, Usage: "Serve TLS (mTLS when ca_file is set) with the certificates configured in conf.yaml, and generate self-signed certificates for development in certs/.", Destination: &globalArgs.ServerArgument.TLS},
+		&cli.StringFlag{Name: consts.Transport, Usage: "Specify the Kitex transport of the clients, written to conf.yaml: ttheader, grpc (gRPC compatible, protobuf only), framed or buffered (thrift only). (Valid only for RPC servers)", Destination: &globalArgs.ServerArgument.Transport},
+		&cli.StringSliceFlag{Name: consts.Dal, Usage: "Specify the data access components of biz/dal: mysql, postgres, redis, mongodb, elasticsearch, kafka or none.", Value: cli.NewStringSlice(consts.DalMySQL, consts.DalRedis)},
+		&cli.BoolFlag{Name: consts.Merge, Usage: "Three-way merge regenerated files with your edits instead of skipping or covering them, the generated versions are kept in .cwgo/. Once enabled, a project keeps being merged.", Destination: &globalArgs.ServerArgument.Merge},
+		&cli.StringFlag{Name: consts.Prune, Usage: "Specify what to do with the code of the methods removed from the IDL: move (to _orphaned/) or delete. Methods renamed with the same signature keep their implementation.", Destination: &globalArgs.ServerArgument.Prune},
+		&cli.BoolFlag{Name: consts.OpenAPI, Usage: "Describe the HTTP routes of the IDL in openapi.yaml, for HTTP servers and Kitex servers with --hex. Disable it with --openapi=false.", Value: true, Destination: &globalArgs.ServerArgument.OpenAPI},
+		&cli.BoolFlag{Name: consts.Deploy, Usage: "Generate a Dockerfile and Kubernetes manifests for the service.", Destination: &globalArgs.ServerArgument.Deploy},
+		&cli.IntFlag{Name: consts.DeployPort, Usage: "Specify the service port used by --deploy, default is the port of conf.yaml.", Destination: &globalArgs.ServerArgument.DeployPort},
+		&cli.StringFlag{Name: consts.DeployEnv, Usage: "Specify the env whose conf.yaml is used by --deploy.", Value: "online", Destination: &globalArgs.ServerArgument.DeployEnv},
 
 		// Eino Integration Flags
 		&cli.BoolFlag{
@@ -77,5 +87,10 @@ func serverFlags() []cli.Flag {
 			Usage:       "Enable RAG (Retrieval Augmented Generation)",
 			Destination: &globalArgs.ServerArgument.EnableRAG,
 		},
+		&cli.StringFlag{
+			Name:        "agent-memory",
+			Usage:       "Generate session memory for the agent: memory, redis (reuses biz/dal/redis)",
+			Destination: &globalArgs.ServerArgument.AgentMemory,
+		},
 	}
 }
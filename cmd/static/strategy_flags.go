@@ -0,0 +1,61 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func strategyCommonFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.ProjectPath, Usage: "Specify the project path.", Value: "."},
+		&cli.StringFlag{Name: consts.Service, Usage: "Specify the service, required when the project has strategies of several services."},
+	}
+}
+
+func strategyAddProcessorFlags() []cli.Flag {
+	return append(strategyCommonFlags(),
+		&cli.StringFlag{Name: consts.Method, Usage: "Specify the method the processor belongs to.", Required: true},
+		&cli.StringFlag{Name: consts.Name, Usage: "Specify the processor name.", Required: true},
+		&cli.StringFlag{Name: consts.StrategyName, Usage: "Specify the strategy the processor is appended to, empty to only register it.", Value: "default"},
+		&cli.StringSliceFlag{Name: consts.Reads, Usage: "Specify the state fields the processor reads, default is Req."},
+		&cli.StringSliceFlag{Name: consts.Writes, Usage: "Specify the state fields the processor writes."},
+	)
+}
+
+func strategyAddStrategyFlags() []cli.Flag {
+	return append(strategyCommonFlags(),
+		&cli.StringFlag{Name: consts.Method, Usage: "Specify the method the strategy belongs to.", Required: true},
+		&cli.StringFlag{Name: consts.Name, Usage: "Specify the strategy name.", Required: true},
+		&cli.StringSliceFlag{Name: consts.Processors, Usage: "Specify the ordered processors of the strategy."},
+	)
+}
+
+func strategyListFlags() []cli.Flag {
+	return append(strategyCommonFlags(),
+		&cli.StringFlag{Name: consts.Method, Usage: "Only list the given method."},
+	)
+}
+
+func strategyGraphFlags() []cli.Flag {
+	return append(strategyCommonFlags(),
+		&cli.StringFlag{Name: consts.Method, Usage: "Only draw the given method."},
+		&cli.StringFlag{Name: consts.Format, Usage: "Specify the diagram format. (mermaid or dot)", Value: "mermaid"},
+		&cli.StringFlag{Name: consts.OutFile, Usage: "Specify the output file, default is stdout."},
+	)
+}
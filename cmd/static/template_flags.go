@@ -0,0 +1,55 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func templateListFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.ProjectPath, Usage: "Specify the project path, its pinned template is marked.", Value: "."},
+	}
+}
+
+func templatePullFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.Repo, Usage: "Specify the git url of the template repository.", Required: true},
+		&cli.StringFlag{Name: consts.Ref, Usage: "Specify the branch, tag or commit, default is the default branch."},
+		&cli.BoolFlag{Name: consts.Pin, Usage: "Pin the project to the pulled commit in .cwgo.yaml."},
+		&cli.StringFlag{Name: consts.ProjectPath, Usage: "Specify the project path.", Value: "."},
+	}
+}
+
+func templateUpdateFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.Repo, Usage: "Specify the git url of the template repository, default is every cached one."},
+		&cli.StringFlag{Name: consts.Ref, Usage: "Specify the branch, tag or commit of --repo."},
+		&cli.BoolFlag{Name: consts.Pin, Usage: "Move the pin of the project to the updated commit."},
+		&cli.StringFlag{Name: consts.ProjectPath, Usage: "Specify the project path.", Value: "."},
+	}
+}
+
+func templateValidateFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.Dir, Usage: "Specify the template dir."},
+		&cli.StringFlag{Name: consts.Repo, Usage: "Validate the cached template of the repository instead of --dir."},
+		&cli.StringFlag{Name: consts.Ref, Usage: "Specify the branch, tag or commit of --repo."},
+		&cli.StringFlag{Name: consts.ServiceType, Usage: "Specify the template type. (RPC or HTTP, default is detected from the files)"},
+	}
+}
@@ -41,6 +41,9 @@ type Argument struct {
 	*JobArgument
 	*ApiArgument
 	*FallbackArgument
+	*McpArgument
+	*StrategyArgument
+	*TemplateArgument
 }
 
 func NewArgument() *Argument {
@@ -52,6 +55,9 @@ func NewArgument() *Argument {
 		JobArgument:      NewJobArgument(),
 		ApiArgument:      NewApiArgument(),
 		FallbackArgument: NewFallbackArgument(),
+		McpArgument:      NewMcpArgument(),
+		StrategyArgument: NewStrategyArgument(),
+		TemplateArgument: NewTemplateArgument(),
 	}
 }
 
@@ -31,13 +31,16 @@ type ClientArgument struct {
 
 	SliceParam *SliceParam
 
-	Verbose  bool
-	Template string
-	Branch   string
-	Cwd      string
-	GoSrc    string
-	GoPkg    string
-	GoPath   string
+	Verbose   bool
+	Template  string
+	Branch    string
+	Lang      string // language of the HTTP client: go, ts or python
+	TLS       bool   // connect to the server with TLS
+	Transport string // kitex transport: ttheader, grpc, framed or buffered
+	Cwd       string
+	GoSrc     string
+	GoPkg     string
+	GoPath    string
 }
 
 func NewClientArgument() *ClientArgument {
@@ -51,6 +54,7 @@ func (c *ClientArgument) ParseCli(ctx *cli.Context) error {
 	c.Type = strings.ToUpper(ctx.String(consts.ServiceType))
 	c.Registry = strings.ToUpper(ctx.String(consts.Registry))
 	c.Verbose = ctx.Bool(consts.Verbose)
+	c.Lang = strings.ToLower(ctx.String(consts.Lang))
 	c.SliceParam.ProtoSearchPath = ctx.StringSlice(consts.ProtoSearchPath)
 	c.SliceParam.Pass = ctx.StringSlice(consts.Pass)
 	// See ServerArgument.ParseCli for why we accept extra positional IDL args.
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type McpArgument struct {
+	Type            string // RPC forwards tool calls to a kitex client, HTTP to a hertz client
+	IdlPath         string
+	GoMod           string
+	OutDir          string
+	ServerName      string
+	ModelDir        string // kitex_gen or hertz_gen directory of the module, ignored with Use
+	ClientDir       string // directory of the generated hertz clients, HTTP only
+	Use             string // import path of the model packages generated elsewhere
+	ProtoSearchPath []string
+}
+
+func NewMcpArgument() *McpArgument {
+	return &McpArgument{}
+}
+
+func (m *McpArgument) ParseCli(ctx *cli.Context) error {
+	m.Type = strings.ToUpper(ctx.String(consts.ServiceType))
+	m.IdlPath = ctx.String(consts.IDLPath)
+	m.GoMod = ctx.String(consts.Module)
+	m.OutDir = ctx.String(consts.OutDir)
+	m.ServerName = ctx.String(consts.ServerName)
+	m.ModelDir = ctx.String(consts.ModelDir)
+	m.ClientDir = ctx.String(consts.ClientDir)
+	m.Use = ctx.String(consts.Use)
+	m.ProtoSearchPath = ctx.StringSlice(consts.ProtoSearchPath)
+	return nil
+}
@@ -33,7 +33,19 @@ type ServerArgument struct {
 	Branch     string
 	SliceParam *SliceParam
 	Verbose    bool
-	Hex        bool // add http listen for kitex
+	Hex        bool     // add http listen for kitex
+	Gateway    bool     // serve the HTTP routes of the IDL with the kitex handlers, implies Hex
+	TLS        bool     // serve TLS with the certificates of conf.yaml, and generate development certificates
+	Transport  string   // kitex transport of the clients: ttheader, grpc, framed or buffered
+	Dal        []string // data access components generated in biz/dal
+	Merge      bool     // merge regenerated files with the edits made to them
+	Prune      string   // move or delete the code of the methods removed from the IDL
+	OpenAPI    bool     // describe the HTTP routes in openapi.yaml
+
+	// Deployment
+	Deploy     bool   // generate Dockerfile and kubernetes manifests
+	DeployPort int    // port of the service, default is the one of conf.yaml
+	DeployEnv  string // conf/<env>/conf.yaml mounted in the container
 
 	// Eino Integration
 	EnableEino    bool
@@ -43,6 +55,7 @@ type ServerArgument struct {
 	ModelName     string   // Model name: gpt-4 / claude-3
 	EnableTools   []string // Enabled tools: search / calculator / custom
 	EnableRAG     bool     // Enable RAG
+	AgentMemory   string   // Session memory store: memory / redis, empty disables it
 
 	Cwd    string
 	GoSrc  string
@@ -72,6 +85,7 @@ func (s *ServerArgument) ParseCli(ctx *cli.Context) error {
 	s.Verbose = ctx.Bool(consts.Verbose)
 	s.SliceParam.ProtoSearchPath = ctx.StringSlice(consts.ProtoSearchPath)
 	s.SliceParam.Pass = ctx.StringSlice(consts.Pass)
+	s.Dal = ctx.StringSlice(consts.Dal)
 	// If user runs `--idl ./dir/*.proto` without quotes, the shell will expand it
 	// into multiple args. urfave/cli will treat the extras as positional args,
 	// which cwgo does not otherwise use. Accept these extra .proto/.thrift args
@@ -102,6 +116,16 @@ func (s *ServerArgument) ParseCli(ctx *cli.Context) error {
 	return nil
 }
 
+// HasDal reports whether the data access component is generated.
+func (s *ServerArgument) HasDal(name string) bool {
+	for _, d := range s.Dal {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *SliceParam) WriteAnswer(name string, value interface{}) error {
 	if name == consts.Pass {
 		s.Pass = strings.Split(value.(string), consts.BlackSpace)
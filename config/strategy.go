@@ -0,0 +1,53 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type StrategyArgument struct {
+	ProjectPath string
+	Service     string // service of the strategy package, required when the project has several
+	Method      string
+	Name        string // processor or strategy name
+	Strategy    string // pipeline the new processor is appended to, empty to only register it
+	Processors  []string
+	Reads       []string
+	Writes      []string
+	Format      string // graph format, mermaid or dot
+	OutFile     string
+}
+
+func NewStrategyArgument() *StrategyArgument {
+	return &StrategyArgument{}
+}
+
+func (s *StrategyArgument) ParseCli(ctx *cli.Context) error {
+	s.ProjectPath = ctx.String(consts.ProjectPath)
+	s.Service = ctx.String(consts.Service)
+	s.Method = ctx.String(consts.Method)
+	s.Name = ctx.String(consts.Name)
+	s.Strategy = ctx.String(consts.StrategyName)
+	s.Processors = ctx.StringSlice(consts.Processors)
+	s.Reads = ctx.StringSlice(consts.Reads)
+	s.Writes = ctx.StringSlice(consts.Writes)
+	s.Format = ctx.String(consts.Format)
+	s.OutFile = ctx.String(consts.OutFile)
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type TemplateArgument struct {
+	Repo        string // git url of the template repository
+	Ref         string // branch, tag or commit, empty for the default branch
+	Dir         string // template dir to validate
+	Type        string // RPC or HTTP, detected from the files when empty
+	Pin         bool   // record the version in the project config
+	ProjectPath string
+}
+
+func NewTemplateArgument() *TemplateArgument {
+	return &TemplateArgument{}
+}
+
+func (t *TemplateArgument) ParseCli(ctx *cli.Context) error {
+	t.Repo = ctx.String(consts.Repo)
+	t.Ref = ctx.String(consts.Ref)
+	t.Dir = ctx.String(consts.Dir)
+	t.Type = strings.ToUpper(ctx.String(consts.ServiceType))
+	t.Pin = ctx.Bool(consts.Pin)
+	t.ProjectPath = ctx.String(consts.ProjectPath)
+	return nil
+}
@@ -45,6 +45,23 @@ func check(ca *config.ClientArgument) error {
 		return errors.New("must specify server name")
 	}
 
+	if ca.Lang == "" {
+		ca.Lang = consts.LangGo
+	}
+	if ca.Lang != consts.LangGo && ca.Lang != consts.LangTS && ca.Lang != consts.LangPython {
+		return fmt.Errorf("unsupported client language '%s', must be %s, %s or %s", ca.Lang, consts.LangGo, consts.LangTS, consts.LangPython)
+	}
+	if ca.Lang != consts.LangGo && ca.Type != consts.HTTP {
+		return fmt.Errorf("--%s %s is only supported for HTTP clients", consts.Lang, ca.Lang)
+	}
+
+	if ca.Transport != "" && ca.Type != consts.RPC {
+		return fmt.Errorf("--%s is only supported by RPC clients", consts.Transport)
+	}
+	if err := utils.CheckTransport(ca.Transport, ca.IdlPath, ca.TLS); err != nil {
+		return err
+	}
+
 	// handle cwd and output dir
 	dir, err := os.Getwd()
 	if err != nil {
@@ -52,7 +69,7 @@ func check(ca *config.ClientArgument) error {
 	}
 	ca.Cwd = dir
 	if ca.OutDir == "" {
-		if strings.ToUpper(ca.Type) == consts.HTTP {
+		if strings.ToUpper(ca.Type) == consts.HTTP && ca.Lang == consts.LangGo {
 			ca.OutDir = consts.DefaultHZClientDir
 		} else {
 			ca.OutDir = dir
@@ -62,6 +79,10 @@ func check(ca *config.ClientArgument) error {
 		ap := filepath.Join(ca.Cwd, ca.OutDir)
 		ca.OutDir = ap
 	}
+	if ca.Lang != consts.LangGo {
+		// clients of other languages do not depend on the Go environment
+		return nil
+	}
 
 	gopath, err := utils.GetGOPATH()
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/sdk"
 
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
@@ -93,6 +94,12 @@ func Client(c *config.ClientArgument) error {
 				return err
 			}
 
+			if isBuiltinKitexTemplate(args.TemplateDir) {
+				if err = utils.RenderTemplates(args.TemplateDir, &cc, nil); err != nil {
+					return err
+				}
+			}
+
 			kx_registry.HandleRegistry(cc.CommonParam, args.TemplateDir)
 			defer kx_registry.RemoveExtension()
 
@@ -114,6 +121,9 @@ func Client(c *config.ClientArgument) error {
 		utils.ReplaceThriftVersion()
 		utils.UpgradeGolangProtobuf()
 	case consts.HTTP:
+		if c.Lang != consts.LangGo {
+			return sdk.Generate(c)
+		}
 		args := hzConfig.NewArgument()
 		utils.SetHzVerboseLog(c.Verbose)
 		err = convertHzArgument(c, args)
@@ -126,6 +136,11 @@ func Client(c *config.ClientArgument) error {
 		if err != nil {
 			return cli.Exit(err, meta.PluginError)
 		}
+		if isBuiltinHzClient(args.CustomizePackage) {
+			if err = generateErrorDecoders(c, args); err != nil {
+				return cli.Exit(err, meta.PluginError)
+			}
+		}
 	}
 	return nil
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/cloudwego/cwgo/config"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/templates"
 	"github.com/cloudwego/cwgo/tpl"
 	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
 )
@@ -53,18 +54,10 @@ func convertHzArgument(ca *config.ClientArgument, hzArgument *hzConfig.Argument)
 	}
 
 	if strings.HasSuffix(ca.Template, consts.SuffixGit) {
-		err = utils.GitClone(ca.Template, path.Join(tpl.HertzDir, consts.Client))
+		gitPath, err := templates.Prepare(ca.Template, ca.Branch, ca.Cwd, path.Join(tpl.HertzDir, consts.Client))
 		if err != nil {
 			return err
 		}
-		gitPath, err := utils.GitPath(ca.Template)
-		if err != nil {
-			return err
-		}
-		gitPath = path.Join(tpl.HertzDir, consts.Client, gitPath)
-		if err = utils.GitCheckout(ca.Branch, gitPath); err != nil {
-			return err
-		}
 		hzArgument.CustomizePackage = path.Join(gitPath, consts.PackageLayoutFile)
 	} else {
 		if len(ca.Template) != 0 {
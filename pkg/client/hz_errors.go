@@ -0,0 +1,243 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
+	hzutil "github.com/cloudwego/hertz/cmd/hz/util"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const (
+	hertzClientFile  = "hertz_client.go"
+	errorsFileSuffix = "_errors.go"
+)
+
+// identifiers declared by the builtin client templates, which the error types must not reuse
+var clientIdents = map[string]bool{
+	"Client": true, "Option": true, "Options": true, "ResponseResultDecider": true,
+	"BaseURLResolver": true, "RetryPolicy": true, "StatusError": true,
+}
+
+type errorsData struct {
+	PackageName string
+	Imports     map[string]string // import path by package alias
+	Errors      []*errorData
+	Methods     []*methodErrorsData
+}
+
+type errorData struct {
+	Name    string // type of the error in the client package
+	Field   string // embedded model type, e.g. BizError
+	GoType  string // e.g. example.BizError
+	Comment string
+}
+
+type methodErrorsData struct {
+	Name   string
+	Errors []string
+}
+
+// isBuiltinHzClient reports whether the hertz client templates are the ones of cwgo.
+func isBuiltinHzClient(customizePackage string) bool {
+	return customizePackage == path.Join(tpl.HertzDir, consts.Client, consts.Standard, consts.PackageLayoutFile)
+}
+
+// generateErrorDecoders writes the error types of each service client, so that the error responses
+// are decoded into the exceptions thrown by the methods (thrift) or the messages named *Error (proto).
+func generateErrorDecoders(c *config.ClientArgument, args *hzConfig.Argument) error {
+	module := c.GoMod
+	if m, _, ok := utils.SearchGoMod(consts.CurrentDir, false); ok {
+		module = m
+	}
+	modelPrefix := module + "/" + args.ModelDir
+	if args.Use != "" {
+		modelPrefix = args.Use
+	}
+
+	for _, p := range args.IdlPaths {
+		idl, err := parser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return err
+		}
+		for _, svc := range idl.Services {
+			dir := filepath.Join(args.ClientDir, hzutil.ToSnakeCase(svc.Name))
+			data := buildErrorsData(idl, svc, module, modelPrefix)
+			data.PackageName = hzutil.ToSnakeCase(filepath.Base(dir))
+			if err = writeErrors(filepath.Join(dir, hzutil.ToSnakeCase(svc.Name)+errorsFileSuffix), data); err != nil {
+				return err
+			}
+			checkHertzClient(filepath.Join(dir, hertzClientFile))
+		}
+	}
+	return nil
+}
+
+func buildErrorsData(idl *parser.IDL, svc *parser.Service, module, modelPrefix string) *errorsData {
+	data := &errorsData{Imports: make(map[string]string)}
+	aliases := make(map[string]string)  // alias by import path
+	errs := make(map[string]*errorData) // by owner IDL and struct name
+	taken := make(map[string]bool, len(clientIdents))
+	for k := range clientIdents {
+		taken[k] = true
+	}
+	taken[hzutil.ToCamelCase(svc.Name)+"Client"] = true
+
+	errorOf := func(owner *parser.IDL, st *parser.Struct) string {
+		key := owner.Path + "#" + st.Name
+		if e, ok := errs[key]; ok {
+			return e.Name
+		}
+		pkgPath, pkgName := owner.GoImport(module, modelPrefix)
+		alias, ok := aliases[pkgPath]
+		if !ok {
+			alias = pkgName
+			for i := 2; data.Imports[alias] != ""; i++ {
+				alias = pkgName + strconv.Itoa(i)
+			}
+			aliases[pkgPath] = alias
+			data.Imports[alias] = pkgPath
+		}
+		field := parser.GoName(owner.Type, st.Name)
+		name := field
+		for taken[name] {
+			name += "Error"
+		}
+		taken[name] = true
+		e := &errorData{Name: name, Field: field, GoType: alias + "." + field, Comment: st.Comment}
+		errs[key] = e
+		data.Errors = append(data.Errors, e)
+		return e.Name
+	}
+
+	// proto methods can not declare their errors, all the messages named *Error may be returned
+	var protoErrors []*parser.Struct
+	if idl.Type == consts.Proto {
+		for _, st := range idl.Structs {
+			if strings.HasSuffix(st.Name, "Error") {
+				protoErrors = append(protoErrors, st)
+			}
+		}
+	}
+
+	for _, m := range svc.Methods {
+		md := &methodErrorsData{Name: hzutil.CamelString(m.Name)}
+		for _, ex := range m.Exceptions {
+			owner, local := idl.Resolve(ex.Type.Name)
+			if st := owner.LookupStruct(local); st != nil {
+				md.Errors = append(md.Errors, errorOf(owner, st))
+			}
+		}
+		for _, st := range protoErrors {
+			md.Errors = append(md.Errors, errorOf(idl, st))
+		}
+		if len(md.Errors) > 0 {
+			data.Methods = append(data.Methods, md)
+		}
+	}
+	return data
+}
+
+func writeErrors(path string, data *errorsData) error {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+		"split": func(s string) []string { return strings.Split(s, "\n") },
+	}).Parse(errorsTpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format %s failed: %v", path, err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// checkHertzClient warns when hertz_client.go, which hz does not overwrite, lacks what the regenerated clients use.
+func checkHertzClient(path string) {
+	content, err := os.ReadFile(path)
+	if err != nil || bytes.Contains(content, []byte("func (r *request) setMethodName(")) {
+		return
+	}
+	log.Warnf("%s was generated by an older cwgo and lacks retries, timeouts and error decoding, remove it and generate the client again\n", path)
+}
+
+var errorsTpl = `// Code generated by cwgo. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+{{- if .Errors}}
+	"fmt"
+{{end}}
+{{- range $alias, $path := .Imports}}
+	{{$alias}} "{{$path}}"
+{{- end}}
+)
+{{range .Errors}}
+// {{.Name}} is returned for the error responses whose body is a {{.GoType}}.
+{{- if .Comment}}
+//
+{{- range (split .Comment)}}
+// {{.}}
+{{- end}}
+{{- end}}
+type {{.Name}} struct {
+	StatusCode int
+	*{{.GoType}}
+}
+
+func (e *{{.Name}}) Error() string {
+	return fmt.Sprintf("status %d: %v", e.StatusCode, e.{{.Field}})
+}
+
+func decode{{.Name}}(statusCode int, body []byte) error {
+	e := &{{.Name}}{StatusCode: statusCode, {{.Field}}: new({{.GoType}})}
+	if !decodeErrorBody(body, e.{{.Field}}) {
+		return nil
+	}
+	return e
+}
+{{end}}
+// errorDecoders decode the error responses of the methods, in the order the IDL declares the errors.
+var errorDecoders = map[string][]errorDecoder{
+{{- range .Methods}}
+	"{{.Name}}": { {{- range $i, $e := .Errors}}{{if $i}}, {{end}}decode{{$e}}{{end -}} },
+{{- end}}
+}
+`
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildErrorsData(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.thrift": `
+namespace go base
+exception BizError { 1: i32 Code; 2: string Message }
+`,
+		"hello.thrift": `
+namespace go hello.example
+include "base.thrift"
+
+exception Client { 1: string Reason }
+struct Req {}
+struct Resp {}
+
+service hello_service {
+    Resp Get(1: Req req) throws (1: base.BizError biz, 2: Client client) (api.get="/get");
+    Resp Set(1: Req req) throws (1: base.BizError biz) (api.post="/set");
+    Resp Ping(1: Req req) (api.get="/ping");
+}
+`,
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	idl, err := parser.ParseIDL(filepath.Join(dir, "hello.thrift"), nil)
+	assert.NoError(t, err)
+
+	data := buildErrorsData(idl, idl.Services[0], "example.com/a", "example.com/a/biz/model")
+	assert.Equal(t, map[string]string{
+		"base":    "example.com/a/biz/model/base",
+		"example": "example.com/a/biz/model/hello/example",
+	}, data.Imports)
+	if assert.Len(t, data.Errors, 2) {
+		assert.Equal(t, errorData{Name: "BizError", Field: "BizError", GoType: "base.BizError"}, *data.Errors[0])
+		// Client is declared by the client templates
+		assert.Equal(t, errorData{Name: "ClientError", Field: "Client", GoType: "example.Client"}, *data.Errors[1])
+	}
+	assert.Equal(t, []*methodErrorsData{
+		{Name: "Get", Errors: []string{"BizError", "ClientError"}},
+		{Name: "Set", Errors: []string{"BizError"}},
+	}, data.Methods)
+
+	data.PackageName = "hello_service"
+	path := filepath.Join(dir, "hello_service", "hello_service_errors.go")
+	assert.NoError(t, writeErrors(path, data))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"Get": {decodeBizError, decodeClientError},`)
+	assert.Contains(t, string(content), "func (e *ClientError) Error() string {")
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/cloudwego/cwgo/config"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/templates"
 	"github.com/cloudwego/cwgo/tpl"
 	"github.com/cloudwego/kitex"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
@@ -108,18 +109,10 @@ Flags:
 
 	// Non-standard template
 	if strings.HasSuffix(sa.Template, consts.SuffixGit) {
-		err = utils.GitClone(sa.Template, path.Join(tpl.KitexDir, consts.Client))
+		gitPath, err := templates.Prepare(sa.Template, sa.Branch, sa.Cwd, path.Join(tpl.KitexDir, consts.Client))
 		if err != nil {
 			return err
 		}
-		gitPath, err := utils.GitPath(sa.Template)
-		if err != nil {
-			return err
-		}
-		gitPath = path.Join(tpl.KitexDir, consts.Client, gitPath)
-		if err = utils.GitCheckout(sa.Branch, gitPath); err != nil {
-			return err
-		}
 		kitexArgument.TemplateDir = gitPath
 	} else {
 		if len(sa.Template) != 0 {
@@ -210,3 +203,8 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 	a.PackagePrefix = strings.ReplaceAll(a.PackagePrefix, consts.BackSlash, consts.Slash)
 	return nil
 }
+
+// isBuiltinKitexTemplate reports whether the kitex templates are the ones of cwgo.
+func isBuiltinKitexTemplate(dir string) bool {
+	return dir == path.Join(tpl.KitexDir, consts.Client, consts.Standard)
+}
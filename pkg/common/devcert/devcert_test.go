@@ -0,0 +1,77 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package devcert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	generated, err := Generate(dir, "hello", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, generated)
+
+	caPEM, err := os.ReadFile(filepath.Join(dir, CAFile))
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	assert.True(t, roots.AppendCertsFromPEM(caPEM))
+
+	server, err := tls.LoadX509KeyPair(filepath.Join(dir, ServerCertFile), filepath.Join(dir, ServerKeyFile))
+	assert.NoError(t, err)
+	serverCert, err := x509.ParseCertificate(server.Certificate[0])
+	assert.NoError(t, err)
+	for _, host := range []string{"localhost", "127.0.0.1", "hello", "10.0.0.1"} {
+		_, err = serverCert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err, host)
+	}
+
+	client, err := tls.LoadX509KeyPair(filepath.Join(dir, ClientCertFile), filepath.Join(dir, ClientKeyFile))
+	assert.NoError(t, err)
+	clientCert, err := x509.ParseCertificate(client.Certificate[0])
+	assert.NoError(t, err)
+	_, err = clientCert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+
+	// the certificates are kept once generated
+	generated, err = Generate(dir)
+	assert.NoError(t, err)
+	assert.False(t, generated)
+	again, err := os.ReadFile(filepath.Join(dir, CAFile))
+	assert.NoError(t, err)
+	assert.Equal(t, caPEM, again)
+}
+
+func TestGenerateMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Generate(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(filepath.Join(dir, ServerKeyFile)))
+
+	// the certificates are generated again when one of them is missing
+	generated, err := Generate(dir)
+	assert.NoError(t, err)
+	assert.True(t, generated)
+	_, err = tls.LoadX509KeyPair(filepath.Join(dir, ServerCertFile), filepath.Join(dir, ServerKeyFile))
+	assert.NoError(t, err)
+}
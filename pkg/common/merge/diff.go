@@ -0,0 +1,131 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package merge
+
+import "bytes"
+
+// splitLines splits b into lines, each keeping its trailing newline.
+func splitLines(b []byte) [][]byte {
+	var lines [][]byte
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			lines = append(lines, b)
+			break
+		}
+		lines = append(lines, b[:i+1])
+		b = b[i+1:]
+	}
+	return lines
+}
+
+// match returns, for each line of a, the index of the line of b it is matched with
+// in a longest common subsequence of a and b, or -1 if the line is not matched.
+// It implements the greedy algorithm of Myers, "An O(ND) Difference Algorithm and Its Variations".
+func match(a, b [][]byte) []int {
+	ids := make(map[string]int)
+	id := func(lines [][]byte) []int {
+		r := make([]int, len(lines))
+		for i, l := range lines {
+			v, ok := ids[string(l)]
+			if !ok {
+				v = len(ids)
+				ids[string(l)] = v
+			}
+			r[i] = v
+		}
+		return r
+	}
+	x, y := id(a), id(b)
+	n, m := len(x), len(y)
+
+	res := make([]int, n)
+	for i := range res {
+		res[i] = -1
+	}
+
+	// matching common prefix and suffix keeps the search below small for files barely changed
+	pre := 0
+	for pre < n && pre < m && x[pre] == y[pre] {
+		res[pre] = pre
+		pre++
+	}
+	suf := 0
+	for suf < n-pre && suf < m-pre && x[n-1-suf] == y[m-1-suf] {
+		res[n-1-suf] = m - 1 - suf
+		suf++
+	}
+	x, y = x[pre:n-suf], y[pre:m-suf]
+	n, m = len(x), len(y)
+	if n == 0 || m == 0 {
+		return res
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	// walk the trace back from (n, m) to record the diagonals, that is the matched lines
+	i, j := n, m
+	for d := len(trace) - 1; d >= 0 && (i > 0 || j > 0); d-- {
+		v := trace[d]
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevI := v[offset+prevK]
+		prevJ := prevI - prevK
+		for i > prevI && j > prevJ {
+			i--
+			j--
+			res[pre+i] = pre + j
+		}
+		if d > 0 {
+			i, j = prevI, prevJ
+		}
+	}
+	return res
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package merge merges the edits of regenerated files line by line, the way diff3 does.
+package merge
+
+import "bytes"
+
+// Labels name the sides of a conflict in the conflict markers.
+type Labels struct {
+	Ours   string
+	Theirs string
+}
+
+// Result of a three-way merge.
+type Result struct {
+	Content   []byte
+	Conflicts int // number of conflicting hunks, marked in Content
+}
+
+// Merge merges the changes made to base by ours and by theirs. Hunks changed on one side
+// only take that side, hunks changed the same way on both sides are taken once, and hunks
+// changed differently are written with both sides between conflict markers.
+func Merge(base, ours, theirs []byte, labels Labels) Result {
+	o, a, b := splitLines(base), splitLines(ours), splitLines(theirs)
+	ma, mb := match(o, a), match(o, b)
+
+	m := &merger{labels: labels}
+	i, x, y := 0, 0, 0
+	for i < len(o) {
+		if ma[i] == x && mb[i] == y {
+			m.write(o[i])
+			i, x, y = i+1, x+1, y+1
+			continue
+		}
+		// the next base line kept on both sides ends the unstable hunk
+		k := i
+		for k < len(o) && (ma[k] < 0 || mb[k] < 0) {
+			k++
+		}
+		if k == len(o) {
+			break
+		}
+		m.hunk(o[i:k], a[x:ma[k]], b[y:mb[k]])
+		i, x, y = k, ma[k], mb[k]
+	}
+	m.hunk(o[i:], a[x:], b[y:])
+	return Result{Content: m.buf.Bytes(), Conflicts: m.conflicts}
+}
+
+type merger struct {
+	labels    Labels
+	buf       bytes.Buffer
+	conflicts int
+}
+
+func (m *merger) write(lines ...[]byte) {
+	for _, l := range lines {
+		m.buf.Write(l)
+	}
+}
+
+// hunk resolves the hunk o of base, changed into a by ours and into b by theirs.
+func (m *merger) hunk(o, a, b [][]byte) {
+	switch {
+	case equal(a, o):
+		m.write(b...)
+	case equal(b, o), equal(a, b):
+		m.write(a...)
+	default:
+		// only the lines differing on both sides are put between the markers
+		pre := 0
+		for pre < len(a) && pre < len(b) && bytes.Equal(a[pre], b[pre]) {
+			pre++
+		}
+		suf := 0
+		for suf < len(a)-pre && suf < len(b)-pre && bytes.Equal(a[len(a)-1-suf], b[len(b)-1-suf]) {
+			suf++
+		}
+		m.write(a[:pre]...)
+		m.conflict(a[pre:len(a)-suf], b[pre:len(b)-suf])
+		m.write(a[len(a)-suf:]...)
+	}
+}
+
+func (m *merger) conflict(a, b [][]byte) {
+	m.conflicts++
+	m.marker("<<<<<<<", m.labels.Ours)
+	m.side(a)
+	m.marker("=======", "")
+	m.side(b)
+	m.marker(">>>>>>>", m.labels.Theirs)
+}
+
+func (m *merger) marker(marker, label string) {
+	m.buf.WriteString(marker)
+	if label != "" {
+		m.buf.WriteByte(' ')
+		m.buf.WriteString(label)
+	}
+	m.buf.WriteByte('\n')
+}
+
+// side writes the lines of a side of a conflict, ending it with a newline so that the next marker starts a line.
+func (m *merger) side(lines [][]byte) {
+	m.write(lines...)
+	if n := len(lines); n > 0 && !bytes.HasSuffix(lines[n-1], []byte{'\n'}) {
+		m.buf.WriteByte('\n')
+	}
+}
+
+func equal(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
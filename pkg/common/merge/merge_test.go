@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package merge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lines(s ...string) []byte {
+	return []byte(strings.Join(s, "\n") + "\n")
+}
+
+func TestMatch(t *testing.T) {
+	a := splitLines(lines("a", "b", "c", "a", "b", "b", "a"))
+	b := splitLines(lines("c", "b", "a", "b", "a", "c"))
+	m := match(a, b)
+
+	// the matches are an increasing common subsequence of the longest length, 4
+	n, last := 0, -1
+	for i, j := range m {
+		if j < 0 {
+			continue
+		}
+		assert.Greater(t, j, last)
+		assert.Equal(t, string(a[i]), string(b[j]))
+		last = j
+		n++
+	}
+	assert.Equal(t, 4, n)
+
+	assert.Equal(t, []int{-1, -1}, match(splitLines(lines("a", "b")), nil))
+	assert.Equal(t, []int{0, 1, 2}, match(splitLines(lines("a", "b", "c")), splitLines(lines("a", "b", "c"))))
+}
+
+func TestMerge(t *testing.T) {
+	base := lines("package main", "", "func A() {", "\t// todo", "}", "", "func B() {", "}")
+	tests := []struct {
+		name      string
+		ours      []byte
+		theirs    []byte
+		want      []byte
+		conflicts int
+	}{
+		{
+			name:   "unchanged",
+			ours:   base,
+			theirs: base,
+			want:   base,
+		},
+		{
+			name:   "template changed",
+			ours:   base,
+			theirs: lines("package main", "", "func A() {", "\t// todo", "}", "", "func B() {", "}", "", "func C() {", "}"),
+			want:   lines("package main", "", "func A() {", "\t// todo", "}", "", "func B() {", "}", "", "func C() {", "}"),
+		},
+		{
+			name:   "both changed apart",
+			ours:   lines("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "}"),
+			theirs: lines("package main", "", "func A() {", "\t// todo", "}", "", "func B() {", "\t// todo", "}"),
+			want:   lines("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "\t// todo", "}"),
+		},
+		{
+			name:   "both changed the same",
+			ours:   lines("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "}"),
+			theirs: lines("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "}"),
+			want:   lines("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "}"),
+		},
+		{
+			name:   "conflict",
+			ours:   lines("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "}"),
+			theirs: lines("package main", "", "func A() {", "\tpanic(\"todo\")", "}", "", "func B() {", "}"),
+			want: lines("package main", "", "func A() {",
+				"<<<<<<< yours", "\treturn", "=======", "\tpanic(\"todo\")", ">>>>>>> generated",
+				"}", "", "func B() {", "}"),
+			conflicts: 1,
+		},
+		{
+			name:   "deleted by ours",
+			ours:   lines("package main", "", "func A() {", "\t// todo", "}"),
+			theirs: lines("// Package main", "package main", "", "func A() {", "\t// todo", "}", "", "func B() {", "}"),
+			want:   lines("// Package main", "package main", "", "func A() {", "\t// todo", "}"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Merge(base, tt.ours, tt.theirs, labels)
+			assert.Equal(t, string(tt.want), string(res.Content))
+			assert.Equal(t, tt.conflicts, res.Conflicts)
+		})
+	}
+}
+
+func TestMergeNoTrailingNewline(t *testing.T) {
+	res := Merge([]byte("a\nb"), []byte("a\nc"), []byte("a\nd"), Labels{})
+	assert.Equal(t, "a\n<<<<<<<\nc\n=======\nd\n>>>>>>>\n", string(res.Content))
+	assert.Equal(t, 1, res.Conflicts)
+
+	res = Merge([]byte("a"), []byte("a"), []byte("a\nb"), Labels{})
+	assert.Equal(t, "a\nb", string(res.Content))
+	assert.Equal(t, 0, res.Conflicts)
+}
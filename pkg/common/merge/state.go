@@ -0,0 +1,318 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package merge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/openapi"
+)
+
+const baseDir = "base"
+
+// ReportFile lists the files with conflicts of the last merge, relative to the project dir.
+var ReportFile = filepath.Join(consts.CwgoStateDir, "merge_report.txt")
+
+// untracked are the files maintained by the tools or by hand rather than by the templates.
+var untracked = map[string]bool{
+	"go.mod":                 true,
+	"go.sum":                 true,
+	consts.HzFile:            true,
+	consts.ProjectConfigFile: true,
+	openapi.FileName:         true,
+}
+
+// untrackedDirs hold the code generated from the IDL.
+var untrackedDirs = []string{consts.DefaultKitexModelDir + "/", consts.DefaultHZModelDir + "/"}
+
+// generatedCode matches the header of Go files that must not be edited, such as kitex_gen or hz models.
+var generatedCode = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+var labels = Labels{Ours: "yours", Theirs: "generated"}
+
+// Enabled reports whether the files generated in dir are tracked for merging.
+func Enabled(dir string) bool {
+	fi, err := os.Stat(filepath.Join(dir, consts.CwgoStateDir, baseDir))
+	return err == nil && fi.IsDir()
+}
+
+// Session merges a regeneration of dir with the edits made to the files generated by the previous one.
+//
+// The last generated version of each file is kept in .cwgo/base. Begin moves the tracked files
+// out of the way so that the generators write them from scratch whatever their update behavior,
+// then Finish merges each of them with the edited version and records the new generated versions.
+// Files start being tracked when a generator creates them, so an existing project adopts merging
+// file by file, as they are removed and regenerated.
+type Session struct {
+	dir      string
+	state    string
+	before   map[string]bool
+	base     map[string][]byte
+	ours     map[string]file
+	finished bool
+}
+
+type file struct {
+	data []byte
+	mode fs.FileMode
+}
+
+// Report of a merge.
+type Report struct {
+	Merged    []string // files edited on both sides, merged cleanly
+	Conflicts []string // files with conflicting hunks between conflict markers
+	Deleted   []string // files deleted since they were generated, kept deleted
+}
+
+// Begin starts a merge session in dir.
+func Begin(dir string) (*Session, error) {
+	s := &Session{
+		dir:   dir,
+		state: filepath.Join(dir, consts.CwgoStateDir, baseDir),
+		base:  make(map[string][]byte),
+		ours:  make(map[string]file),
+	}
+	err := filepath.WalkDir(s.state, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == s.state {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(s.state, p)
+		s.base[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read merge state failed: %w", err)
+	}
+
+	for rel := range s.base {
+		p := s.path(rel)
+		fi, err := os.Stat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		s.ours[rel] = file{data: data, mode: fi.Mode().Perm()}
+	}
+	// files are removed only once all of them are read, an error leaves dir untouched
+	for rel := range s.ours {
+		if err = os.Remove(s.path(rel)); err != nil {
+			s.Abort()
+			return nil, err
+		}
+	}
+
+	if s.before, err = s.snapshot(); err != nil {
+		s.Abort()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Abort puts back the tracked files as they were before the session, dropping what was generated.
+func (s *Session) Abort() error {
+	if s.finished {
+		return nil
+	}
+	s.finished = true
+	var errs []error
+	for rel, f := range s.ours {
+		if err := os.WriteFile(s.path(rel), f.data, f.mode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Finish merges the generated files with the tracked ones and records the generated versions.
+func (s *Session) Finish() (*Report, error) {
+	if s.finished {
+		return nil, errors.New("merge session is finished")
+	}
+	s.finished = true
+
+	after, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	report := new(Report)
+	rels := make([]string, 0, len(after)+len(s.base))
+	for rel := range after {
+		rels = append(rels, rel)
+	}
+	for rel := range s.base {
+		if _, ok := after[rel]; !ok {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+
+	for _, rel := range rels {
+		base, tracked := s.base[rel]
+		_, generated := after[rel]
+		if generated && !tracked && s.before[rel] {
+			// an existing file appended to or covered may hold edits, it can't be
+			// the base of a merge, only the files created by the generators are tracked
+			continue
+		}
+		ours, kept := s.ours[rel]
+
+		if !generated {
+			// the generators don't write the file anymore, keep it as it is
+			if kept {
+				if err = os.WriteFile(s.path(rel), ours.data, ours.mode); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		theirs, err := os.ReadFile(s.path(rel))
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !trackable(rel, theirs):
+			// simply regenerated from now on
+			if tracked {
+				if err = os.Remove(filepath.Join(s.state, filepath.FromSlash(rel))); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		case tracked && !kept:
+			report.Deleted = append(report.Deleted, rel)
+			if err = os.Remove(s.path(rel)); err != nil {
+				return nil, err
+			}
+		case tracked:
+			res := Merge(base, ours.data, theirs, labels)
+			if res.Conflicts > 0 {
+				report.Conflicts = append(report.Conflicts, rel)
+			} else if !bytes.Equal(ours.data, base) && !bytes.Equal(theirs, base) && !bytes.Equal(ours.data, theirs) {
+				report.Merged = append(report.Merged, rel)
+			}
+			if err = os.WriteFile(s.path(rel), res.Content, ours.mode); err != nil {
+				return nil, err
+			}
+		}
+		if err = s.record(rel, theirs); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, s.writeReport(report)
+}
+
+// record keeps the generated version of the file as the base of the next merge.
+func (s *Session) record(rel string, data []byte) error {
+	p := filepath.Join(s.state, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func (s *Session) writeReport(r *Report) error {
+	p := filepath.Join(s.dir, ReportFile)
+	if len(r.Conflicts) == 0 {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(p, []byte(r.String()), 0o644)
+}
+
+// snapshot lists the files of dir, the state dir and VCS dirs excluded.
+func (s *Session) snapshot() (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != s.dir && (d.Name() == consts.CwgoStateDir || d.Name() == ".git") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, _ := filepath.Rel(s.dir, p)
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return files, err
+}
+
+func (s *Session) path(rel string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(rel))
+}
+
+// trackable reports whether the generated file is merged on regeneration,
+// the files not meant to be edited by hand are simply regenerated.
+func trackable(rel string, data []byte) bool {
+	if untracked[rel] || bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	for _, dir := range untrackedDirs {
+		if strings.HasPrefix(rel, dir) {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rel, ".go") || !generatedCode.Match(data)
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	write := func(title string, files []string) {
+		if len(files) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", title)
+		for _, f := range files {
+			fmt.Fprintf(&b, "  %s\n", f)
+		}
+	}
+	write("merged", r.Merged)
+	write("deleted, not regenerated", r.Deleted)
+	write("conflicts, resolve the hunks between the <<<<<<< and >>>>>>> markers", r.Conflicts)
+	return b.String()
+}
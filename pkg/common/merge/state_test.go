@@ -0,0 +1,136 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package merge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+// generate writes files into dir the way a generator would, an empty content skips the file.
+func generate(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil && content == "" {
+			continue
+		}
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+}
+
+func text(s ...string) string {
+	return string(lines(s...))
+}
+
+func read(t *testing.T, dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func run(t *testing.T, dir string, files map[string]string) *Report {
+	s, err := Begin(dir)
+	assert.NoError(t, err)
+	generate(t, dir, files)
+	r, err := s.Finish()
+	assert.NoError(t, err)
+	return r
+}
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	generate(t, dir, map[string]string{"go.mod": "module example.com/a\n", "user.go": "package main\n", "main.go": "package main // edited\n"})
+	assert.False(t, Enabled(dir))
+
+	gen := "// Code generated by Kitex. DO NOT EDIT.\npackage gen\n"
+	r := run(t, dir, map[string]string{
+		"handler.go":      text("package main", "", "func A() {", "\t// todo", "}"),
+		"conf/conf.yaml":  text("port: 8888"),
+		"removed.go":      text("package main"),
+		"kitex_gen/a.go":  gen,
+		"go.mod":          "module example.com/a\n\nrequire x v1\n",
+		"build.sh":        "#!/bin/bash\n",
+		"biz/dal/init.go": text("package dal"),
+		"main.go":         "package main // edited\n\nfunc main() {}\n",
+	})
+	assert.Equal(t, &Report{}, r)
+	assert.True(t, Enabled(dir))
+	for _, name := range []string{"handler.go", "conf/conf.yaml", "removed.go", "build.sh", "biz/dal/init.go"} {
+		assert.FileExists(t, filepath.Join(dir, consts.CwgoStateDir, baseDir, name))
+	}
+	for _, name := range []string{"kitex_gen/a.go", "go.mod", "user.go", "main.go"} {
+		assert.NoFileExists(t, filepath.Join(dir, consts.CwgoStateDir, baseDir, name))
+	}
+
+	// edit the generated files
+	generate(t, dir, map[string]string{
+		"handler.go":     text("package main", "", "func A() {", "\treturn", "}"),
+		"conf/conf.yaml": text("port: 9999"),
+		"build.sh":       "#!/bin/sh\n",
+	})
+	assert.NoError(t, os.Remove(filepath.Join(dir, "biz/dal/init.go")))
+
+	r = run(t, dir, map[string]string{
+		"handler.go":      text("package main", "", "func A() {", "\t// todo", "}", "", "func B() {", "\t// todo", "}"),
+		"conf/conf.yaml":  text("port: 8000"),
+		"build.sh":        "#!/bin/bash\n",
+		"biz/dal/init.go": text("package dal", "", "func Init() {}"),
+		"kitex_gen/a.go":  gen,
+		"go.mod":          "",
+	})
+	assert.Equal(t, &Report{
+		Merged:    []string{"handler.go"},
+		Conflicts: []string{"conf/conf.yaml"},
+		Deleted:   []string{"biz/dal/init.go"},
+	}, r)
+	assert.Equal(t, text("package main", "", "func A() {", "\treturn", "}", "", "func B() {", "\t// todo", "}"), read(t, dir, "handler.go"))
+	assert.Equal(t, "<<<<<<< yours\nport: 9999\n=======\nport: 8000\n>>>>>>> generated\n", read(t, dir, "conf/conf.yaml"))
+	assert.Equal(t, "#!/bin/sh\n", read(t, dir, "build.sh"))
+	assert.Equal(t, text("package main"), read(t, dir, "removed.go"), "files not generated anymore are kept")
+	assert.NoFileExists(t, filepath.Join(dir, "biz/dal/init.go"))
+	assert.Equal(t, "package main\n", read(t, dir, "user.go"))
+	assert.Equal(t, "module example.com/a\n\nrequire x v1\n", read(t, dir, "go.mod"))
+	assert.Contains(t, read(t, dir, ReportFile), "conf/conf.yaml")
+	assert.Equal(t, text("port: 8000"), read(t, dir, filepath.Join(consts.CwgoStateDir, baseDir, "conf/conf.yaml")))
+
+	// resolved conflicts are not reported again
+	generate(t, dir, map[string]string{"conf/conf.yaml": text("port: 9999")})
+	r = run(t, dir, map[string]string{"conf/conf.yaml": text("port: 8000")})
+	assert.Empty(t, r.Conflicts)
+	assert.Equal(t, text("port: 9999"), read(t, dir, "conf/conf.yaml"))
+	assert.NoFileExists(t, filepath.Join(dir, ReportFile))
+}
+
+func TestSessionAbort(t *testing.T) {
+	dir := t.TempDir()
+	run(t, dir, map[string]string{"handler.go": "package main\n"})
+	generate(t, dir, map[string]string{"handler.go": "package main // edited\n"})
+
+	s, err := Begin(dir)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "handler.go"))
+	generate(t, dir, map[string]string{"handler.go": "package broken\n"})
+	assert.NoError(t, s.Abort())
+	assert.Equal(t, "package main // edited\n", read(t, dir, "handler.go"))
+	_, err = s.Finish()
+	assert.Error(t, err)
+}
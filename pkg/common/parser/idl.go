@@ -0,0 +1,293 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+// IDL is a language neutral view of a thrift or proto file, used by the
+// generators which only need services, structs and their annotations.
+type IDL struct {
+	Path      string
+	Type      string // consts.Thrift or consts.Proto
+	GoPackage string // thrift "namespace go" (or the file name without it) or proto "go_package"
+	Services  []*Service
+	Structs   []*Struct
+	Enums     []*Enum
+	Includes  map[string]*IDL // keyed by the name used to reference it, e.g. "base" for base.thrift
+}
+
+type Service struct {
+	Name        string
+	Methods     []*Method
+	Annotations Annotations
+	Comment     string
+}
+
+type Method struct {
+	Name            string
+	Args            []*Field // thrift arguments; proto methods always have a single "req" argument
+	Response        *Type    // nil if the method is void
+	Exceptions      []*Field
+	Oneway          bool
+	ClientStreaming bool
+	ServerStreaming bool
+	Annotations     Annotations
+	Comment         string
+}
+
+type Struct struct {
+	Name        string
+	Category    string // struct, union, exception or message
+	Fields      []*Field
+	Annotations Annotations
+	Comment     string
+}
+
+type Field struct {
+	ID          int32
+	Name        string
+	Type        *Type
+	Required    bool
+	Optional    bool
+	Oneof       bool // proto field of a oneof, set through its wrapper type in Go
+	Default     string
+	Annotations Annotations
+	Comment     string
+}
+
+type Enum struct {
+	Name        string
+	Values      []*EnumValue
+	Annotations Annotations
+	Comment     string
+}
+
+type EnumValue struct {
+	Name        string
+	Value       int64
+	Annotations Annotations
+	Comment     string
+}
+
+type TypeKind int
+
+const (
+	KindBase TypeKind = iota
+	KindStruct
+	KindEnum
+	KindList
+	KindSet
+	KindMap
+)
+
+// Type names of base types are normalized to the thrift spelling (bool, byte,
+// i8, i16, i32, i64, double, string, binary), plus u32, u64 and float for proto.
+// Struct and enum types keep the name used in the IDL, e.g. "base.BaseResp".
+type Type struct {
+	Name      string
+	Kind      TypeKind
+	KeyType   *Type
+	ValueType *Type
+}
+
+// Annotations holds thrift annotations and proto custom options.
+// Proto option keys are the dotted option path, e.g. "api.get" or "validate.rules.string.min_len".
+type Annotations map[string][]string
+
+func (a Annotations) Get(key string) (string, bool) {
+	v, ok := a[key]
+	if !ok || len(v) == 0 {
+		return "", false
+	}
+	return v[0], true
+}
+
+func (a Annotations) add(key, value string) {
+	a[key] = append(a[key], value)
+}
+
+// ParseIDL parses the IDL file and, for thrift, its includes.
+func ParseIDL(path string, includeDirs []string) (*IDL, error) {
+	idlType, err := utils.GetIdlType(path)
+	if err != nil {
+		return nil, err
+	}
+	var idl *IDL
+	switch idlType {
+	case consts.Thrift:
+		idl, err = parseThriftIDL(path, includeDirs)
+	case consts.Proto:
+		idl, err = parseProtoIDL(path, includeDirs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse idl '%s' failed, err: %v", path, err)
+	}
+	idl.resolveKinds()
+	return idl, nil
+}
+
+// Resolve returns the IDL declaring the referenced type and the type's name in that IDL.
+func (i *IDL) Resolve(name string) (*IDL, string) {
+	return i.lookup(name)
+}
+
+// GoImport returns the import path and the package name of the Go code generated from the IDL,
+// prefix is the package the generated code is written to, e.g. "<module>/kitex_gen".
+func (i *IDL) GoImport(module, prefix string) (string, string) {
+	pkg, name := i.GoPackage, ""
+	if i.Type == consts.Thrift {
+		pkg = strings.ReplaceAll(pkg, ".", "/")
+	} else if idx := strings.Index(pkg, ";"); idx >= 0 {
+		pkg, name = pkg[:idx], pkg[idx+1:]
+	}
+	if !strings.HasPrefix(pkg, module+"/") {
+		pkg = prefix + "/" + strings.TrimPrefix(pkg, "/")
+	}
+	if name == "" {
+		name = pkg[strings.LastIndex(pkg, "/")+1:]
+	}
+	return pkg, strings.ReplaceAll(name, "-", "_")
+}
+
+// GoName converts an IDL identifier the way thriftgo and protoc-gen-go do.
+func GoName(idlType, name string) string {
+	var b strings.Builder
+	upper := true
+	for i, r := range name {
+		if r == '_' {
+			next := i + 1
+			// protoc-gen-go keeps an underscore unless a lower case letter follows
+			if idlType == consts.Proto && (next >= len(name) || !unicode.IsLower(rune(name[next]))) {
+				b.WriteRune(r)
+				continue
+			}
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// LookupStruct finds a struct by the name it is referenced with, following thrift includes.
+func (i *IDL) LookupStruct(name string) *Struct {
+	idl, local := i.lookup(name)
+	if idl == nil {
+		return nil
+	}
+	for _, s := range idl.Structs {
+		if s.Name == local {
+			return s
+		}
+	}
+	return nil
+}
+
+// LookupEnum finds an enum by the name it is referenced with, following thrift includes.
+func (i *IDL) LookupEnum(name string) *Enum {
+	idl, local := i.lookup(name)
+	if idl == nil {
+		return nil
+	}
+	for _, e := range idl.Enums {
+		if e.Name == local {
+			return e
+		}
+	}
+	return nil
+}
+
+// IsLocal reports whether the referenced type is declared in this IDL rather than an include.
+func (i *IDL) IsLocal(name string) bool {
+	idl, _ := i.lookup(name)
+	return idl == i
+}
+
+func (i *IDL) lookup(name string) (*IDL, string) {
+	name = strings.TrimPrefix(name, ".")
+	if idx := strings.LastIndex(name, "."); idx > 0 {
+		if inc, ok := i.Includes[name[:idx]]; ok {
+			return inc, name[idx+1:]
+		}
+		// proto references may be qualified with the file's own package
+		name = name[idx+1:]
+	}
+	return i, name
+}
+
+func (i *IDL) resolveKinds() {
+	var resolve func(t *Type)
+	resolve = func(t *Type) {
+		if t == nil {
+			return
+		}
+		resolve(t.KeyType)
+		resolve(t.ValueType)
+		if t.Kind != KindBase || isBaseType(t.Name) {
+			return
+		}
+		if i.LookupEnum(t.Name) != nil {
+			t.Kind = KindEnum
+		} else {
+			t.Kind = KindStruct
+		}
+	}
+	for _, s := range i.Structs {
+		for _, f := range s.Fields {
+			resolve(f.Type)
+		}
+	}
+	for _, svc := range i.Services {
+		for _, m := range svc.Methods {
+			for _, f := range m.Args {
+				resolve(f.Type)
+			}
+			for _, f := range m.Exceptions {
+				resolve(f.Type)
+			}
+			resolve(m.Response)
+		}
+	}
+	for _, inc := range i.Includes {
+		inc.resolveKinds()
+	}
+}
+
+var baseTypes = map[string]bool{
+	"bool": true, "byte": true, "i8": true, "i16": true, "i32": true, "i64": true, "u32": true, "u64": true,
+	"float": true, "double": true, "string": true, "binary": true,
+}
+
+func isBaseType(name string) bool {
+	return baseTypes[name]
+}
+
+func includeName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// source code info paths, see descriptor.proto
+const (
+	protoMessagePath = 4
+	protoEnumPath    = 5
+	protoServicePath = 6
+	protoFieldPath   = 2
+	protoNestedPath  = 3
+	protoNestedEnum  = 4
+	protoMethodPath  = 2
+	protoValuePath   = 2
+)
+
+var protoBaseTypes = map[descriptorpb.FieldDescriptorProto_Type]string{
+	descriptorpb.FieldDescriptorProto_TYPE_BOOL:     "bool",
+	descriptorpb.FieldDescriptorProto_TYPE_INT32:    "i32",
+	descriptorpb.FieldDescriptorProto_TYPE_SINT32:   "i32",
+	descriptorpb.FieldDescriptorProto_TYPE_SFIXED32: "i32",
+	descriptorpb.FieldDescriptorProto_TYPE_UINT32:   "u32",
+	descriptorpb.FieldDescriptorProto_TYPE_FIXED32:  "u32",
+	descriptorpb.FieldDescriptorProto_TYPE_INT64:    "i64",
+	descriptorpb.FieldDescriptorProto_TYPE_SINT64:   "i64",
+	descriptorpb.FieldDescriptorProto_TYPE_SFIXED64: "i64",
+	descriptorpb.FieldDescriptorProto_TYPE_UINT64:   "u64",
+	descriptorpb.FieldDescriptorProto_TYPE_FIXED64:  "u64",
+	descriptorpb.FieldDescriptorProto_TYPE_FLOAT:    "float",
+	descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:   "double",
+	descriptorpb.FieldDescriptorProto_TYPE_STRING:   "string",
+	descriptorpb.FieldDescriptorProto_TYPE_BYTES:    "binary",
+}
+
+// parseProtoIDL parses the proto file without linking, so that imports such as
+// api.proto or validate.proto need not be resolvable; custom options are kept
+// uninterpreted and exposed as annotations.
+func parseProtoIDL(path string, includeDirs []string) (*IDL, error) {
+	dir := filepath.Dir(path)
+	p := protoparse.Parser{
+		ImportPaths: append([]string{dir}, includeDirs...),
+		Accessor: func(name string) (io.ReadCloser, error) {
+			return os.Open(filepath.Join(dir, name))
+		},
+		IncludeSourceCodeInfo:           true,
+		InterpretOptionsInUnlinkedFiles: true,
+	}
+	fds, err := p.ParseFilesButDoNotLink(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	fd := fds[0]
+
+	c := &protoConverter{
+		idl: &IDL{
+			Path:      path,
+			Type:      consts.Proto,
+			GoPackage: fd.GetOptions().GetGoPackage(),
+			Includes:  make(map[string]*IDL),
+		},
+		pkg:      fd.GetPackage(),
+		comments: make(map[string]string),
+	}
+	for _, loc := range fd.GetSourceCodeInfo().GetLocation() {
+		if comment := cleanComment(loc.GetLeadingComments() + loc.GetTrailingComments()); comment != "" {
+			c.comments[pathKey(loc.GetPath())] = comment
+		}
+	}
+
+	for i, m := range fd.GetMessageType() {
+		c.convertMessage(m, "", []int32{protoMessagePath, int32(i)})
+	}
+	for i, e := range fd.GetEnumType() {
+		c.convertEnum(e, "", []int32{protoEnumPath, int32(i)})
+	}
+	for i, s := range fd.GetService() {
+		spath := []int32{protoServicePath, int32(i)}
+		svc := &Service{
+			Name:        s.GetName(),
+			Annotations: protoAnnotations(s.GetOptions().GetUninterpretedOption()),
+			Comment:     c.comments[pathKey(spath)],
+		}
+		for j, m := range s.GetMethod() {
+			svc.Methods = append(svc.Methods, &Method{
+				Name:            m.GetName(),
+				Args:            []*Field{{ID: 1, Name: "req", Type: &Type{Name: trimProtoPackage(m.GetInputType(), fd.GetPackage()), Kind: KindStruct}}},
+				Response:        &Type{Name: trimProtoPackage(m.GetOutputType(), fd.GetPackage()), Kind: KindStruct},
+				ClientStreaming: m.GetClientStreaming(),
+				ServerStreaming: m.GetServerStreaming(),
+				Annotations:     protoAnnotations(m.GetOptions().GetUninterpretedOption()),
+				Comment:         c.comments[pathKey(append(spath, protoMethodPath, int32(j)))],
+			})
+		}
+		c.idl.Services = append(c.idl.Services, svc)
+	}
+	c.resolveScopes()
+	return c.idl, nil
+}
+
+type protoConverter struct {
+	idl      *IDL
+	pkg      string
+	comments map[string]string
+	// references by message fields, resolved against the enclosing messages once all types are known
+	refs []scopedRef
+}
+
+type scopedRef struct {
+	typ   *Type
+	scope string
+}
+
+// convertMessage flattens nested messages, naming them Outer_Inner the way protoc-gen-go does.
+func (c *protoConverter) convertMessage(m *descriptorpb.DescriptorProto, prefix string, path []int32) {
+	name := prefix + m.GetName()
+	mapEntries := make(map[string]*descriptorpb.DescriptorProto)
+	for i, nested := range m.GetNestedType() {
+		if nested.GetOptions().GetMapEntry() {
+			mapEntries[nested.GetName()] = nested
+			continue
+		}
+		c.convertMessage(nested, name+"_", append(append([]int32{}, path...), protoNestedPath, int32(i)))
+	}
+	for i, e := range m.GetEnumType() {
+		c.convertEnum(e, name+"_", append(append([]int32{}, path...), protoNestedEnum, int32(i)))
+	}
+
+	s := &Struct{
+		Name:        name,
+		Category:    "message",
+		Annotations: protoAnnotations(m.GetOptions().GetUninterpretedOption()),
+		Comment:     c.comments[pathKey(path)],
+	}
+	for i, f := range m.GetField() {
+		field := &Field{
+			ID:          f.GetNumber(),
+			Name:        f.GetName(),
+			Type:        c.fieldType(f, name, mapEntries),
+			Optional:    f.GetProto3Optional(),
+			Oneof:       f.OneofIndex != nil && !f.GetProto3Optional(),
+			Required:    f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED,
+			Default:     f.GetDefaultValue(),
+			Annotations: protoAnnotations(f.GetOptions().GetUninterpretedOption()),
+			Comment:     c.comments[pathKey(append(append([]int32{}, path...), protoFieldPath, int32(i)))],
+		}
+		s.Fields = append(s.Fields, field)
+	}
+	c.idl.Structs = append(c.idl.Structs, s)
+}
+
+func (c *protoConverter) convertEnum(e *descriptorpb.EnumDescriptorProto, prefix string, path []int32) {
+	enum := &Enum{
+		Name:        prefix + e.GetName(),
+		Annotations: protoAnnotations(e.GetOptions().GetUninterpretedOption()),
+		Comment:     c.comments[pathKey(path)],
+	}
+	for i, v := range e.GetValue() {
+		enum.Values = append(enum.Values, &EnumValue{
+			Name:        v.GetName(),
+			Value:       int64(v.GetNumber()),
+			Annotations: protoAnnotations(v.GetOptions().GetUninterpretedOption()),
+			Comment:     c.comments[pathKey(append(append([]int32{}, path...), protoValuePath, int32(i)))],
+		})
+	}
+	c.idl.Enums = append(c.idl.Enums, enum)
+}
+
+func (c *protoConverter) fieldType(f *descriptorpb.FieldDescriptorProto, scope string, mapEntries map[string]*descriptorpb.DescriptorProto) *Type {
+	var t *Type
+	if name, ok := protoBaseTypes[f.GetType()]; ok && f.Type != nil {
+		t = &Type{Name: name}
+	} else {
+		typeName := f.GetTypeName()
+		short := typeName[strings.LastIndex(typeName, ".")+1:]
+		if entry, ok := mapEntries[short]; ok && f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+			return &Type{
+				Name:      "map",
+				Kind:      KindMap,
+				KeyType:   c.fieldType(entry.GetField()[0], scope, nil),
+				ValueType: c.fieldType(entry.GetField()[1], scope, nil),
+			}
+		}
+		// nested references are flattened the same way as in convertMessage
+		t = &Type{Name: strings.ReplaceAll(trimProtoPackage(typeName, c.pkg), ".", "_")}
+		if !strings.HasPrefix(typeName, ".") {
+			c.refs = append(c.refs, scopedRef{typ: t, scope: scope})
+		}
+	}
+	if f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return &Type{Name: "list", Kind: KindList, ValueType: t}
+	}
+	return t
+}
+
+// resolveScopes qualifies relative references with the innermost enclosing message
+// which declares them, e.g. "Sub" used in message Req becomes "Req_Sub".
+func (c *protoConverter) resolveScopes() {
+	declared := make(map[string]bool)
+	for _, s := range c.idl.Structs {
+		declared[s.Name] = true
+	}
+	for _, e := range c.idl.Enums {
+		declared[e.Name] = true
+	}
+	for _, ref := range c.refs {
+		for scope := ref.scope; scope != ""; {
+			if name := scope + "_" + ref.typ.Name; declared[name] {
+				ref.typ.Name = name
+				break
+			}
+			idx := strings.LastIndex(scope, "_")
+			if idx < 0 {
+				break
+			}
+			scope = scope[:idx]
+		}
+	}
+}
+
+func trimProtoPackage(name, pkg string) string {
+	name = strings.TrimPrefix(name, ".")
+	if pkg != "" {
+		name = strings.TrimPrefix(name, pkg+".")
+	}
+	return name
+}
+
+func protoAnnotations(opts []*descriptorpb.UninterpretedOption) Annotations {
+	ret := make(Annotations, len(opts))
+	for _, opt := range opts {
+		parts := make([]string, 0, len(opt.GetName()))
+		for _, p := range opt.GetName() {
+			parts = append(parts, p.GetNamePart())
+		}
+		var value string
+		switch {
+		case opt.StringValue != nil:
+			value = string(opt.GetStringValue())
+		case opt.IdentifierValue != nil:
+			value = opt.GetIdentifierValue()
+		case opt.PositiveIntValue != nil:
+			value = strconv.FormatUint(opt.GetPositiveIntValue(), 10)
+		case opt.NegativeIntValue != nil:
+			value = strconv.FormatInt(opt.GetNegativeIntValue(), 10)
+		case opt.DoubleValue != nil:
+			value = strconv.FormatFloat(opt.GetDoubleValue(), 'g', -1, 64)
+		case opt.AggregateValue != nil:
+			value = opt.GetAggregateValue()
+		}
+		ret.add(strings.Join(parts, "."), value)
+	}
+	return ret
+}
+
+func pathKey(path []int32) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = strconv.Itoa(int(p))
+	}
+	return strings.Join(parts, ",")
+}
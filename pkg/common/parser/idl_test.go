@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeIDL(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestParseThriftIDL(t *testing.T) {
+	dir := t.TempDir()
+	writeIDL(t, dir, "base.thrift", `
+namespace go base
+struct BaseResp { 1: i32 Code }
+service BaseService { BaseResp Ping() }
+`)
+	path := writeIDL(t, dir, "hello.thrift", `
+namespace go hello.example
+include "base.thrift"
+
+typedef i64 ID
+enum Color { RED = 1 }
+
+// request of hello
+struct HelloReq {
+    1: required string Name (api.query="name", api.vd="len($)>0");
+    2: optional list<ID> Ids;
+    3: map<string, Color> Colors;
+}
+
+service HelloService extends base.BaseService {
+    base.BaseResp Hello(1: HelloReq req) (api.get="/hello");
+}
+`)
+
+	idl, err := ParseIDL(path, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello.example", idl.GoPackage)
+
+	req := idl.LookupStruct("HelloReq")
+	assert.NotNil(t, req)
+	assert.Equal(t, "request of hello", req.Comment)
+	assert.True(t, req.Fields[0].Required)
+	assert.Equal(t, []string{"name"}, req.Fields[0].Annotations["api.query"])
+	assert.Equal(t, KindList, req.Fields[1].Type.Kind)
+	assert.Equal(t, "i64", req.Fields[1].Type.ValueType.Name)
+	assert.Equal(t, KindEnum, req.Fields[2].Type.ValueType.Kind)
+
+	svc := idl.Services[0]
+	assert.Len(t, svc.Methods, 2)
+	assert.Equal(t, "Ping", svc.Methods[0].Name)
+	hello := svc.Methods[1]
+	path, ok := hello.Annotations.Get("api.get")
+	assert.True(t, ok)
+	assert.Equal(t, "/hello", path)
+	assert.Equal(t, KindStruct, hello.Response.Kind)
+	assert.False(t, idl.IsLocal(hello.Response.Name))
+	assert.NotNil(t, idl.LookupStruct(hello.Response.Name))
+}
+
+func TestParseProtoIDL(t *testing.T) {
+	dir := t.TempDir()
+	path := writeIDL(t, dir, "hello.proto", `
+syntax = "proto3";
+package hello;
+option go_package = "hello/pb";
+import "api.proto";
+
+// request of hello
+message HelloReq {
+  string name = 1 [(api.query) = "name", (validate.rules).string.min_len = 1];
+  map<string, int64> scores = 2;
+  message Item { int32 id = 1; }
+  repeated Item items = 3;
+}
+message HelloResp { string msg = 1; }
+
+service HelloService {
+  rpc Hello(HelloReq) returns (HelloResp) { option (api.post) = "/hello"; }
+  rpc Watch(HelloReq) returns (stream HelloResp);
+}
+`)
+
+	idl, err := ParseIDL(path, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello/pb", idl.GoPackage)
+
+	req := idl.LookupStruct("HelloReq")
+	assert.NotNil(t, req)
+	assert.Equal(t, "request of hello", req.Comment)
+	assert.Equal(t, []string{"name"}, req.Fields[0].Annotations["api.query"])
+	assert.Equal(t, []string{"1"}, req.Fields[0].Annotations["validate.rules.string.min_len"])
+	assert.Equal(t, KindMap, req.Fields[1].Type.Kind)
+	assert.Equal(t, "i64", req.Fields[1].Type.ValueType.Name)
+	assert.Equal(t, "HelloReq_Item", req.Fields[2].Type.ValueType.Name)
+	assert.NotNil(t, idl.LookupStruct(req.Fields[2].Type.ValueType.Name))
+
+	methods := idl.Services[0].Methods
+	assert.Equal(t, "HelloReq", methods[0].Args[0].Type.Name)
+	assert.Equal(t, []string{"/hello"}, methods[0].Annotations["api.post"])
+	assert.True(t, methods[1].ServerStreaming)
+}
+
+func TestGoImport(t *testing.T) {
+	thrift := &IDL{Type: "thrift", GoPackage: "hello.example"}
+	pkg, name := thrift.GoImport("example.com/a", "example.com/a/kitex_gen")
+	assert.Equal(t, "example.com/a/kitex_gen/hello/example", pkg)
+	assert.Equal(t, "example", name)
+
+	proto := &IDL{Type: "proto", GoPackage: "example.com/a/biz/model/hello;hello_pb"}
+	pkg, name = proto.GoImport("example.com/a", "example.com/a/biz/model")
+	assert.Equal(t, "example.com/a/biz/model/hello", pkg)
+	assert.Equal(t, "hello_pb", name)
+
+	proto.GoPackage = "hello/v1"
+	pkg, name = proto.GoImport("example.com/a", "example.com/a/biz/model")
+	assert.Equal(t, "example.com/a/biz/model/hello/v1", pkg)
+	assert.Equal(t, "v1", name)
+}
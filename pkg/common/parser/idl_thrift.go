@@ -0,0 +1,206 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+func parseThriftIDL(path string, includeDirs []string) (*IDL, error) {
+	ast, err := parser.ParseFile(path, includeDirs, true)
+	if err != nil {
+		return nil, err
+	}
+	return convertThrift(ast), nil
+}
+
+func convertThrift(ast *parser.Thrift) *IDL {
+	idl := &IDL{
+		Path:      ast.Filename,
+		Type:      consts.Thrift,
+		GoPackage: ast.GetNamespaceOrReferenceName("go"),
+		Includes:  make(map[string]*IDL),
+	}
+	for _, inc := range ast.Includes {
+		if inc.Reference != nil {
+			idl.Includes[includeName(inc.Path)] = convertThrift(inc.Reference)
+		}
+	}
+
+	typedefs := make(map[string]*parser.Type, len(ast.Typedefs))
+	for _, td := range ast.Typedefs {
+		typedefs[td.Alias] = td.Type
+	}
+	conv := func(t *parser.Type) *Type { return convertThriftType(t, typedefs) }
+	fields := func(fs []*parser.Field) []*Field {
+		ret := make([]*Field, 0, len(fs))
+		for _, f := range fs {
+			ret = append(ret, &Field{
+				ID:          f.ID,
+				Name:        f.Name,
+				Type:        conv(f.Type),
+				Required:    f.Requiredness == parser.FieldType_Required,
+				Optional:    f.Requiredness == parser.FieldType_Optional,
+				Default:     thriftConstString(f.Default),
+				Annotations: thriftAnnotations(f.Annotations),
+				Comment:     cleanComment(f.ReservedComments),
+			})
+		}
+		return ret
+	}
+
+	structs := [][]*parser.StructLike{ast.Structs, ast.Unions, ast.Exceptions}
+	for _, group := range structs {
+		for _, s := range group {
+			idl.Structs = append(idl.Structs, &Struct{
+				Name:        s.Name,
+				Category:    s.Category,
+				Fields:      fields(s.Fields),
+				Annotations: thriftAnnotations(s.Annotations),
+				Comment:     cleanComment(s.ReservedComments),
+			})
+		}
+	}
+
+	for _, e := range ast.Enums {
+		enum := &Enum{
+			Name:        e.Name,
+			Annotations: thriftAnnotations(e.Annotations),
+			Comment:     cleanComment(e.ReservedComments),
+		}
+		for _, v := range e.Values {
+			enum.Values = append(enum.Values, &EnumValue{
+				Name:        v.Name,
+				Value:       v.Value,
+				Annotations: thriftAnnotations(v.Annotations),
+				Comment:     cleanComment(v.ReservedComments),
+			})
+		}
+		idl.Enums = append(idl.Enums, enum)
+	}
+
+	for _, s := range ast.Services {
+		svc := &Service{
+			Name:        s.Name,
+			Annotations: thriftAnnotations(s.Annotations),
+			Comment:     cleanComment(s.ReservedComments),
+		}
+		for _, f := range s.Functions {
+			m := &Method{
+				Name:        f.Name,
+				Args:        fields(f.Arguments),
+				Exceptions:  fields(f.Throws),
+				Oneway:      f.Oneway,
+				Annotations: thriftAnnotations(f.Annotations),
+				Comment:     cleanComment(f.ReservedComments),
+			}
+			if !f.Void {
+				m.Response = conv(f.FunctionType)
+			}
+			svc.Methods = append(svc.Methods, m)
+		}
+		idl.Services = append(idl.Services, svc)
+	}
+
+	// methods of the extended service are served by the child service as well
+	for i, s := range ast.Services {
+		if s.Extends == "" {
+			continue
+		}
+		parentIDL, parentName := idl.lookup(s.Extends)
+		for _, parent := range parentIDL.Services {
+			if parent.Name == parentName {
+				idl.Services[i].Methods = append(append([]*Method{}, parent.Methods...), idl.Services[i].Methods...)
+			}
+		}
+	}
+	return idl
+}
+
+func convertThriftType(t *parser.Type, typedefs map[string]*parser.Type) *Type {
+	if t == nil {
+		return nil
+	}
+	if real, ok := typedefs[t.Name]; ok {
+		return convertThriftType(real, typedefs)
+	}
+	ret := &Type{Name: t.Name}
+	switch t.Name {
+	case "list":
+		ret.Kind = KindList
+	case "set":
+		ret.Kind = KindSet
+	case "map":
+		ret.Kind = KindMap
+		ret.KeyType = convertThriftType(t.KeyType, typedefs)
+	}
+	if ret.Kind != KindBase {
+		ret.ValueType = convertThriftType(t.ValueType, typedefs)
+	}
+	return ret
+}
+
+func thriftAnnotations(annos parser.Annotations) Annotations {
+	ret := make(Annotations, len(annos))
+	for _, a := range annos {
+		for _, v := range a.Values {
+			ret.add(a.Key, v)
+		}
+	}
+	return ret
+}
+
+func thriftConstString(c *parser.ConstValue) string {
+	if c == nil || c.TypedValue == nil {
+		return ""
+	}
+	v := c.TypedValue
+	switch {
+	case v.Literal != nil:
+		return *v.Literal
+	case v.Int != nil:
+		return strconv.FormatInt(*v.Int, 10)
+	case v.Double != nil:
+		return strconv.FormatFloat(*v.Double, 'g', -1, 64)
+	case v.Identifier != nil:
+		return *v.Identifier
+	}
+	return ""
+}
+
+// cleanComment strips comment markers, keeping the text of each line.
+func cleanComment(comment string) string {
+	lines := strings.Split(comment, "\n")
+	ret := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "/**")
+		line = strings.TrimPrefix(line, "/*")
+		line = strings.TrimSuffix(line, "*/")
+		line = strings.TrimPrefix(line, "//")
+		line = strings.TrimPrefix(line, "#")
+		line = strings.TrimPrefix(line, "*")
+		if line = strings.TrimSpace(line); line != "" {
+			ret = append(ret, line)
+		}
+	}
+	return strings.Join(ret, "\n")
+}
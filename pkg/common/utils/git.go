@@ -17,6 +17,8 @@
 package utils
 
 import (
+	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
@@ -61,3 +63,61 @@ func GitPath(gitURL string) (string, error) {
 	path := p[len(p)-1]
 	return path[:len(path)-4], nil
 }
+
+// GitCloneTo clones the repository into dir.
+func GitCloneTo(gitURL, dir string) error {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		return err
+	}
+	c := exec.Command("git", "clone", "--quiet", gitURL, dir)
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// GitFetch fetches the branches and tags of origin.
+func GitFetch(path string) error {
+	c := exec.Command("git", "fetch", "--quiet", "--tags", "--force", "origin")
+	c.Dir = path
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// GitRevParse resolves the revision to a commit hash.
+func GitRevParse(rev, path string) (string, error) {
+	c := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	c.Dir = path
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision '%s'", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitCheckoutCommit checks out the commit as a detached HEAD.
+func GitCheckoutCommit(commit, path string) error {
+	c := exec.Command("git", "-c", "advice.detachedHead=false", "checkout", "--quiet", commit)
+	c.Dir = path
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// GitArchive writes the tree of the revision to w as a tar archive, the checkout is left as is.
+func GitArchive(rev, path string, w io.Writer) error {
+	c := exec.Command("git", "archive", "--format=tar", rev)
+	c.Dir = path
+	c.Stdout = w
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// GitRemoteURL returns the url of origin.
+func GitRemoteURL(path string) (string, error) {
+	c := exec.Command("git", "remote", "get-url", "origin")
+	c.Dir = path
+	out, err := c.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// RenderTemplates renders the [[ ]] actions of the yaml templates in dir with data, e.g. the options
+// of the command selecting parts of the templates. The {{ }} actions are left to kitex and hz.
+// Templates rendering empty are removed, so the wrapped files are not generated.
+// Only the builtin templates should be rendered, they are extracted again on every run.
+func RenderTemplates(dir string, data interface{}, funcs template.FuncMap) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(content, []byte("[[")) {
+			continue
+		}
+		tmpl, err := template.New(filepath.Base(file)).Delims("[[", "]]").Funcs(funcs).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("parse template %s failed: %v", file, err)
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("render template %s failed: %v", file, err)
+		}
+		if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+			err = os.Remove(file)
+		} else {
+			err = os.WriteFile(file, buf.Bytes(), 0o644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTemplate renders the go template to path, formatting .go files. An existing file is
+// kept unless overwrite is set.
+func WriteTemplate(path, tplContent string, data interface{}, overwrite bool) error {
+	if !overwrite {
+		exist, err := PathExist(path)
+		if err != nil {
+			return err
+		}
+		if exist {
+			return nil
+		}
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Parse(tplContent)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	content := buf.Bytes()
+	if filepath.Ext(path) == ".go" {
+		if content, err = format.Source(content); err != nil {
+			return fmt.Errorf("format %s failed: %v", path, err)
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/meta"
+)
+
+// CheckTransport checks the kitex transport of --transport against the IDLs of idlPath: gRPC is
+// generated for protobuf and buffered for thrift only. gRPC is not served by the go net transport
+// of the TLS connections either.
+func CheckTransport(transport, idlPath string, tls bool) error {
+	var idlType string
+	switch transport {
+	case "", consts.TransportTTHeader, consts.TransportFramed:
+		return nil
+	case consts.TransportGRPC:
+		if tls {
+			return fmt.Errorf("--%s %s can't be combined with --%s", consts.Transport, transport, consts.TLS)
+		}
+		idlType = meta.IdlProto
+	case consts.TransportBuffered:
+		idlType = meta.IdlThrift
+	default:
+		return fmt.Errorf("unsupported transport '%s', supported: %s, %s, %s, %s", transport,
+			consts.TransportTTHeader, consts.TransportGRPC, consts.TransportFramed, consts.TransportBuffered)
+	}
+
+	idls, err := ExpandIDLPaths(idlPath)
+	if err != nil {
+		return err
+	}
+	for _, idl := range idls {
+		t, err := GetIdlType(idl)
+		if err != nil {
+			return err
+		}
+		if t != idlType {
+			return fmt.Errorf("--%s %s is only supported by %s IDLs, %s is not", consts.Transport, transport, idlType, idl)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckTransport(t *testing.T) {
+	dir := t.TempDir()
+	thrift := filepath.Join(dir, "a.thrift")
+	proto := filepath.Join(dir, "b.proto")
+	for _, p := range []string{thrift, proto} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		transport string
+		idl       string
+		tls       bool
+		ok        bool
+	}{
+		{"", thrift, false, true},
+		{"ttheader", proto, true, true},
+		{"framed", thrift, false, true},
+		{"grpc", proto, false, true},
+		{"grpc", thrift, false, false},
+		{"grpc", proto, true, false},
+		{"grpc", filepath.Join(dir, "*"), false, false},
+		{"buffered", thrift, false, true},
+		{"buffered", proto, false, false},
+		{"quic", thrift, false, false},
+	}
+	for _, c := range cases {
+		err := CheckTransport(c.transport, c.idl, c.tls)
+		if (err == nil) != c.ok {
+			t.Errorf("CheckTransport(%q, %q, %v) err: %v", c.transport, c.idl, c.tls, err)
+		}
+	}
+}
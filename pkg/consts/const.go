@@ -70,6 +70,7 @@ const (
 	DefaultHZClientDir    = "biz/http"
 	DefaultKitexModelDir  = "kitex_gen"
 	DefaultDbOutDir       = "biz/dal/query"
+	DevCertDir            = "certs"
 	DefaultDocModelOutDir = "biz/doc/model"
 	DefaultDocDaoOutDir   = "biz/doc/dao"
 	Standard              = "standard"
@@ -128,8 +129,10 @@ const (
 	Branch   = "branch"
 	Name     = "name"
 
-	ModelDir = "model_dir"
-	DaoDir   = "dao_dir"
+	ModelDir  = "model_dir"
+	DaoDir    = "dao_dir"
+	ClientDir = "client_dir"
+	Use       = "use"
 
 	Service         = "service"
 	ServerName      = "server_name"
@@ -159,6 +162,53 @@ const (
 	TypeTag       = "type_tag"
 	HexTag        = "hex"
 	SQLDir        = "sql_dir"
+	Deploy        = "deploy"
+	DeployPort    = "deploy_port"
+	DeployEnv     = "deploy_env"
+	Dal           = "dal"
+	Merge         = "merge"
+	Prune         = "prune"
+	OpenAPI       = "openapi"
+	Gateway       = "gateway"
+	TLS           = "tls"
+	Transport     = "transport"
+	Lang          = "lang"
+)
+
+// Kitex transports of --transport
+const (
+	TransportTTHeader = "ttheader"
+	TransportGRPC     = "grpc"
+	TransportFramed   = "framed"
+	TransportBuffered = "buffered"
+)
+
+// Languages of the HTTP clients of --lang
+const (
+	LangGo     = "go"
+	LangTS     = "ts"
+	LangPython = "python"
+)
+
+// Modes of --prune
+const (
+	PruneMove   = "move"
+	PruneDelete = "delete"
+
+	// OrphanedDir receives the code of the methods removed from the IDL with --prune move,
+	// the go tool ignores directories starting with an underscore.
+	OrphanedDir = "_orphaned"
+)
+
+// Data access components of --dal
+const (
+	DalMySQL         = "mysql"
+	DalPostgres      = "postgres"
+	DalRedis         = "redis"
+	DalMongoDB       = "mongodb"
+	DalElasticsearch = "elasticsearch"
+	DalKafka         = "kafka"
+	DalNone          = "none"
 )
 
 const (
@@ -169,6 +219,29 @@ const (
 	JobName = "job_name"
 )
 
+const (
+	Method       = "method"
+	StrategyName = "strategy"
+	Processors   = "processors"
+	Reads        = "reads"
+	Writes       = "writes"
+	Format       = "format"
+)
+
+const (
+	Repo = "repo"
+	Ref  = "ref"
+	Dir  = "dir"
+	Pin  = "pin"
+
+	// CwgoTemplateCache overrides the directory of the template cache.
+	CwgoTemplateCache = "CWGO_TEMPLATE_CACHE"
+	// ProjectConfigFile records the template version a project is pinned to.
+	ProjectConfigFile = ".cwgo.yaml"
+	// CwgoStateDir keeps the last generated version of the files merged on regeneration.
+	CwgoStateDir = ".cwgo"
+)
+
 const (
 	BashAutocomplete = `#! /bin/bash
 
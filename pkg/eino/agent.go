@@ -8,6 +8,8 @@ import (
 	"text/template"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
 )
 
 type AgentTemplateData struct {
@@ -17,6 +19,8 @@ type AgentTemplateData struct {
 	ModelName     string
 	Tools         []string
 	EnableRAG     bool
+	EnableMemory  bool
+	MemoryStore   string
 }
 
 func GenerateEinoAgentModule(c *config.ServerArgument) error {
@@ -40,18 +44,15 @@ func GenerateEinoAgentModule(c *config.ServerArgument) error {
 }
 
 func generateReActAgent(c *config.ServerArgument, outDir string) error {
-	serviceName := c.ServerName
-	if serviceName == "" {
-		serviceName = "Service"
-	}
-
 	data := AgentTemplateData{
 		GoModule:      c.GoMod,
-		AgentName:     toCamel(serviceName) + "Agent",
+		AgentName:     agentName(c),
 		ModelProvider: c.ModelProvider,
 		ModelName:     c.ModelName,
 		Tools:         c.EnableTools,
 		EnableRAG:     c.EnableRAG,
+		EnableMemory:  c.AgentMemory != "",
+		MemoryStore:   c.AgentMemory,
 	}
 
 	tmpl, err := template.New("agent").Parse(ReActAgentTemplate)
@@ -65,7 +66,85 @@ func generateReActAgent(c *config.ServerArgument, outDir string) error {
 	}
 
 	filename := filepath.Join(outDir, "agent.go")
-	return os.WriteFile(filename, buf.Bytes(), 0644)
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	if data.EnableMemory {
+		return generateAgentMemory(outDir, data)
+	}
+	return nil
+}
+
+func generateAgentMemory(outDir string, data AgentTemplateData) error {
+	memoryDir := filepath.Join(outDir, "memory")
+	files := map[string]string{
+		"memory.go":   AgentMemoryTemplate,
+		"inmemory.go": AgentMemoryInMemoryTemplate,
+	}
+	if data.MemoryStore == "redis" {
+		files["redis.go"] = AgentMemoryRedisTemplate
+	}
+	for name, tpl := range files {
+		if err := utils.WriteTemplate(filepath.Join(memoryDir, name), tpl, data, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinishEinoAgentModule runs after the kitex/hertz layout has been generated:
+// it adds the agent_memory section to conf.yaml, and serves the agent over HTTP
+// when there is a hertz engine (--type HTTP or --hex).
+func FinishEinoAgentModule(c *config.ServerArgument) error {
+	if c.AgentMemory != "" {
+		if err := patchAgentMemoryConf(c.OutDir, c.AgentMemory); err != nil {
+			return err
+		}
+	}
+	if c.Type == consts.HTTP || c.Hex {
+		return GenerateEinoHTTPEndpoint(c)
+	}
+	return nil
+}
+
+// patchAgentMemoryConf appends the agent_memory section to every conf/<env>/conf.yaml that lacks it.
+func patchAgentMemoryConf(outDir, store string) error {
+	confFiles, err := filepath.Glob(filepath.Join(outDir, "conf", "*", "conf.yaml"))
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("agent_memory").Parse(AgentMemoryConfTemplate)
+	if err != nil {
+		return err
+	}
+	var section bytes.Buffer
+	if err = tmpl.Execute(&section, map[string]string{"Store": store}); err != nil {
+		return err
+	}
+
+	for _, f := range confFiles {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(content, []byte("agent_memory:")) {
+			continue
+		}
+		if err = os.WriteFile(f, append(append(bytes.TrimRight(content, "\n"), '\n'), section.Bytes()...), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func agentName(c *config.ServerArgument) string {
+	serviceName := c.ServerName
+	if serviceName == "" {
+		serviceName = "Service"
+	}
+	return toCamel(serviceName) + "Agent"
 }
 
 func toCamel(name string) string {
@@ -0,0 +1,431 @@
+package eino
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// chatFixture completes the generated agent into a module whose test chats twice in a session.
+// The eino packages used by the agent are replaced by a stub, the test runs without network.
+var chatFixture = map[string]string{
+	"go.mod": `module example.com/bot
+
+go 1.18
+
+require (
+	github.com/cloudwego/eino v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/cloudwego/eino => ./eino
+`,
+	"eino/go.mod": `module github.com/cloudwego/eino
+
+go 1.18
+`,
+	"eino/schema/schema.go": `package schema
+
+import "io"
+
+type RoleType string
+
+type Message struct {
+	Role    RoleType
+	Content string
+}
+
+type ToolCall struct{}
+
+func UserMessage(content string) *Message { return &Message{Role: "user", Content: content} }
+
+func SystemMessage(content string) *Message { return &Message{Role: "system", Content: content} }
+
+func AssistantMessage(content string, _ []ToolCall) *Message {
+	return &Message{Role: "assistant", Content: content}
+}
+
+type StreamReader[T any] struct {
+	ch     chan item[T]
+	closed chan struct{}
+}
+
+type StreamWriter[T any] struct {
+	ch     chan item[T]
+	closed chan struct{}
+}
+
+type item[T any] struct {
+	v   T
+	err error
+}
+
+func Pipe[T any](cap int) (*StreamReader[T], *StreamWriter[T]) {
+	ch, closed := make(chan item[T], cap), make(chan struct{})
+	return &StreamReader[T]{ch: ch, closed: closed}, &StreamWriter[T]{ch: ch, closed: closed}
+}
+
+func StreamReaderFromArray[T any](arr []T) *StreamReader[T] {
+	r, w := Pipe[T](len(arr))
+	for _, v := range arr {
+		w.Send(v, nil)
+	}
+	w.Close()
+	return r
+}
+
+func (r *StreamReader[T]) Recv() (T, error) {
+	it, ok := <-r.ch
+	if !ok {
+		var zero T
+		return zero, io.EOF
+	}
+	return it.v, it.err
+}
+
+func (r *StreamReader[T]) Close() {
+	select {
+	case <-r.closed:
+	default:
+		close(r.closed)
+	}
+}
+
+func (w *StreamWriter[T]) Send(v T, err error) (closed bool) {
+	select {
+	case <-w.closed:
+		return true
+	case w.ch <- item[T]{v: v, err: err}:
+		return false
+	}
+}
+
+func (w *StreamWriter[T]) Close() { close(w.ch) }
+
+func ConcatMessages(msgs []*Message) (*Message, error) {
+	m := &Message{Role: msgs[0].Role}
+	for _, msg := range msgs {
+		m.Content += msg.Content
+	}
+	return m, nil
+}
+`,
+	"eino/flow/agent/react/react.go": `package react
+
+type Agent struct{}
+`,
+	"conf/conf.go": `package conf
+
+func GetEnv() string { return "test" }
+`,
+	// read from the working directory of the test, the package dir
+	"internal/agent/conf/test/conf.yaml": `agent_memory:
+  store: memory
+  max_messages: 3
+  summarize: true
+`,
+	"internal/agent/memory/store_test.go": `package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// testStore appends to a session concurrently, then compacts it.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	m := New(Config{}, store)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := m.Append(ctx, "s", schema.UserMessage(fmt.Sprint(i)), schema.AssistantMessage(fmt.Sprint(i), nil)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	history, err := m.History(ctx, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 40 {
+		t.Fatalf("%d messages are kept, want 40", len(history))
+	}
+
+	before := history
+	m = New(Config{MaxMessages: 3, Summarize: true}, store).WithSummarizer(func(ctx context.Context, dropped []*schema.Message) (*schema.Message, error) {
+		return schema.SystemMessage(fmt.Sprintf("summary of %d", len(dropped))), nil
+	})
+	if err = m.Append(ctx, "s", schema.UserMessage("q"), schema.AssistantMessage("a", nil)); err != nil {
+		t.Fatal(err)
+	}
+	history, err = m.History(ctx, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].Content != "summary of 40" || history[1].Content != "q" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	// the messages are compacted already
+	if ok, err := store.Compact(ctx, "s", before[:2], schema.SystemMessage("stale")); err != nil || ok {
+		t.Fatalf("stale compaction = %v, %v", ok, err)
+	}
+}
+
+func TestInMemoryStore(t *testing.T) {
+	testStore(t, NewInMemoryStore(0))
+}
+`,
+	"internal/agent/chat_test.go": `package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestChat(t *testing.T) {
+	ctx := context.Background()
+	a, err := NewBotAgent(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inputs [][]string
+	generate := a.generate
+	a.generate = func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+		var contents []string
+		for _, m := range input {
+			contents = append(contents, m.Content)
+		}
+		inputs = append(inputs, contents)
+		return generate(ctx, input)
+	}
+
+	first, err := a.Chat(ctx, "s1", "my name is cwgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = a.Chat(ctx, "s1", "what is my name"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(inputs[1], "|"), "my name is cwgo|"+first+"|what is my name"; got != want {
+		t.Fatalf("second turn input = %q, want %q", got, want)
+	}
+
+	// 4 messages exceed max_messages: the first turn is summarized
+	if len(inputs) != 3 || !strings.HasPrefix(inputs[2][0], "Summarize") {
+		t.Fatalf("the first turn is not summarized, inputs %q", inputs)
+	}
+	history, err := a.memory.History(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || !strings.HasPrefix(history[0].Content, "Summary") || history[1].Content != "what is my name" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	if _, err = a.Chat(ctx, "s2", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(inputs[3], "|"); got != "hello" {
+		t.Fatalf("another session sees %q", got)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	ctx := context.Background()
+	a, err := NewBotAgent(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inputs [][]string
+	a.stream = func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+		var contents []string
+		for _, m := range input {
+			contents = append(contents, m.Content)
+		}
+		inputs = append(inputs, contents)
+		return schema.StreamReaderFromArray([]*schema.Message{
+			schema.AssistantMessage("hello, ", nil),
+			schema.AssistantMessage("cwgo", nil),
+		}), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		sr, err := a.ChatStream(ctx, "s1", "hi")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var answer string
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			answer += chunk.Content
+		}
+		sr.Close()
+		if answer != "hello, cwgo" {
+			t.Fatalf("answer = %q", answer)
+		}
+	}
+	// the streamed answer is saved to the session
+	if got, want := strings.Join(inputs[1], "|"), "hi|hello, cwgo|hi"; got != want {
+		t.Fatalf("second turn input = %q, want %q", got, want)
+	}
+}
+`,
+}
+
+// redisFixture adds a redis memory store, tested with miniredis, to chatFixture.
+var redisFixture = map[string]string{
+	"go.mod": `module example.com/bot
+
+go 1.18
+
+require (
+	github.com/alicebob/miniredis/v2 v2.33.0
+	github.com/cloudwego/eino v0.0.0
+	github.com/redis/go-redis/v9 v9.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/cloudwego/eino => ./eino
+`,
+	"biz/dal/redis/init.go": `package redis
+
+import "github.com/redis/go-redis/v9"
+
+var RedisClient *redis.Client
+`,
+	"internal/agent/memory/redis_test.go": `package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisStore(t *testing.T) {
+	s := miniredis.RunT(t)
+	testStore(t, NewRedisStore(redis.NewClient(&redis.Options{Addr: s.Addr()}), "", time.Hour))
+}
+`,
+}
+
+func TestGenerateReActAgentChat(t *testing.T) {
+	testGenerateReActAgent(t, "memory", chatFixture)
+}
+
+func TestGenerateReActAgentRedisMemory(t *testing.T) {
+	fixture := make(map[string]string)
+	for name, content := range chatFixture {
+		fixture[name] = content
+	}
+	for name, content := range redisFixture {
+		fixture[name] = content
+	}
+	testGenerateReActAgent(t, "redis", fixture)
+}
+
+// testGenerateReActAgent generates the agent with the memory store, completes it with the fixture
+// and runs its tests.
+func testGenerateReActAgent(t *testing.T, store string, fixture map[string]string) {
+	if testing.Short() {
+		t.Skip("builds a generated agent")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	dir := t.TempDir()
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/bot"
+	c.ServerName = "bot"
+	c.OutDir = dir
+	c.AgentMemory = store
+	assert.NoError(t, GenerateEinoAgentModule(c))
+	for name, content := range fixture {
+		p := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	cmd := exec.Command(goBin, "test", "./internal/agent/...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOSUMDB=off", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, "go test:\n%s", out)
+}
+
+func TestGenerateEinoHTTPEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "hello.thrift"), []byte("namespace go hello\n"), 0o644))
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/bot"
+	c.ServerName = "bot"
+	c.OutDir = dir
+	c.IdlPath = filepath.Join(dir, "hello.thrift")
+
+	// --hex registers the agent routes in the register.go of hz
+	assert.Error(t, GenerateEinoHTTPEndpoint(c))
+	register := filepath.Join(dir, registerFile)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(register), 0o755))
+	assert.NoError(t, os.WriteFile(register, []byte("package router\n\nimport (\n\t\"github.com/cloudwego/hertz/pkg/app/server\"\n)\n\nfunc GeneratedRegister(r *server.Hertz) {\n\t//INSERT_POINT: DO NOT DELETE THIS LINE!\n}\n"), 0o644))
+	assert.NoError(t, GenerateEinoHTTPEndpoint(c))
+	content, err := os.ReadFile(register)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "agent.Register(r)")
+
+	// --gateway has no register.go, the gateway registers the routes
+	dir = t.TempDir()
+	c.OutDir = dir
+	c.Gateway = true
+	assert.NoError(t, GenerateEinoHTTPEndpoint(c))
+	for _, f := range []string{"biz/handler/agent/agent.thrift", "biz/handler/agent/agent.go", "biz/handler/agent/call.go", "biz/router/agent/agent.go"} {
+		_, err = os.Stat(filepath.Join(dir, f))
+		assert.NoError(t, err, f)
+	}
+	_, err = os.Stat(filepath.Join(dir, registerFile))
+	assert.True(t, os.IsNotExist(err))
+	assert.False(t, legacyAgentHandler(dir))
+
+	// the kept handler follows a later --agent-memory through call.go
+	call := filepath.Join(dir, "biz", "handler", "agent", "call.go")
+	content, err = os.ReadFile(call)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "return a.Stream(ctx, req.Query)")
+	handler := filepath.Join(dir, "biz", "handler", "agent", "agent.go")
+	assert.NoError(t, os.WriteFile(handler, []byte("package agent\n\n// edited\n"), 0o644))
+	c.AgentMemory = "memory"
+	assert.NoError(t, GenerateEinoHTTPEndpoint(c))
+	content, err = os.ReadFile(call)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "return a.Chat(ctx, req.SessionID, req.Query)")
+	assert.Contains(t, string(content), "return a.ChatStream(ctx, req.SessionID, req.Query)")
+	content, err = os.ReadFile(handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "package agent\n\n// edited\n", string(content))
+	// a handler calling the agent itself is reported
+	assert.True(t, legacyAgentHandler(dir))
+}
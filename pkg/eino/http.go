@@ -0,0 +1,121 @@
+package eino
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	hzUtil "github.com/cloudwego/hertz/cmd/hz/util"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const (
+	agentRouterAlias = "agent"
+	registerFile     = "biz/router/register.go"
+	agentHandlerDir  = "biz/handler/agent"
+)
+
+// same insert point that hz uses in biz/router/register.go
+var regInsertPoint = regexp.MustCompile(`//INSERT_POINT\: DO NOT DELETE THIS LINE\!`)
+
+type AgentHTTPTemplateData struct {
+	GoModule     string
+	AgentName    string
+	IdlPath      string
+	EnableMemory bool
+}
+
+// GenerateEinoHTTPEndpoint generates hertz routes that serve the agent's Run and
+// Stream (as Server-Sent Events), and a reference IDL describing them next to the handlers.
+// The handlers are written once, the calls of the agent they make follow --agent-memory.
+// It must run after the hertz layout exists, i.e. for --type HTTP or --hex.
+func GenerateEinoHTTPEndpoint(c *config.ServerArgument) error {
+	idls, err := utils.ExpandIDLPaths(c.IdlPath)
+	if err != nil {
+		return err
+	}
+	idlType, err := utils.GetIdlType(idls[0])
+	if err != nil {
+		return err
+	}
+
+	// the reference IDL stays out of idl/, no code is generated from it
+	idlPath := filepath.Join(agentHandlerDir, "agent.thrift")
+	idlTpl := AgentThriftTemplate
+	if idlType == consts.Proto {
+		idlPath = filepath.Join(agentHandlerDir, "agent.proto")
+		idlTpl = AgentProtoTemplate
+	}
+
+	data := AgentHTTPTemplateData{
+		GoModule:  c.GoMod,
+		AgentName: agentName(c),
+		IdlPath:   filepath.ToSlash(idlPath),
+
+		EnableMemory: c.AgentMemory != "",
+	}
+
+	files := []struct {
+		path      string
+		tpl       string
+		overwrite bool
+	}{
+		{path: idlPath, tpl: idlTpl, overwrite: true},
+		{path: filepath.Join(agentHandlerDir, "agent.go"), tpl: AgentHandlerTemplate},
+		{path: filepath.Join(agentHandlerDir, "call.go"), tpl: AgentCallTemplate, overwrite: true},
+		{path: filepath.Join("biz", "router", "agent", "agent.go"), tpl: AgentRouterTemplate, overwrite: true},
+	}
+	for _, f := range files {
+		if err = utils.WriteTemplate(filepath.Join(c.OutDir, f.path), f.tpl, data, f.overwrite); err != nil {
+			return err
+		}
+	}
+	if legacyAgentHandler(c.OutDir) {
+		log.Warnf("%s calls the agent itself and does not follow --agent-memory: call runAgent and streamAgent of %s instead\n",
+			filepath.Join(agentHandlerDir, "agent.go"), filepath.Join(agentHandlerDir, "call.go"))
+	}
+
+	// the gateway has no register.go of hz, registerGateway registers the agent routes
+	if c.Gateway {
+		return nil
+	}
+	return registerAgentRouter(filepath.Join(c.OutDir, registerFile), c.GoMod+"/biz/router/agent")
+}
+
+// legacyAgentHandler reports whether the kept handler predates call.go, calling the agent with the
+// memory wiring of the generation that wrote it.
+func legacyAgentHandler(outDir string) bool {
+	content, err := os.ReadFile(filepath.Join(outDir, agentHandlerDir, "agent.go"))
+	return err == nil && !bytes.Contains(content, []byte("runAgent("))
+}
+
+// registerAgentRouter adds "agent.Register(r)" to GeneratedRegister, the same way hz registers IDL routers.
+func registerAgentRouter(registerPath, routerPkg string) error {
+	file, err := os.ReadFile(registerPath)
+	if err != nil {
+		return fmt.Errorf("read register '%s' failed, err: %v", registerPath, err)
+	}
+	if bytes.Contains(file, []byte(routerPkg)) {
+		return nil
+	}
+
+	file, err = hzUtil.AddImport(registerPath, agentRouterAlias, routerPkg)
+	if err != nil {
+		return err
+	}
+	subIndexReg := regInsertPoint.FindSubmatchIndex(file)
+	if len(subIndexReg) != 2 || subIndexReg[0] < 1 {
+		return fmt.Errorf("wrong format %s: insert-point not found", registerPath)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.Write(file[:subIndexReg[1]])
+	buf.WriteString("\n\t" + agentRouterAlias + ".Register(r)\n")
+	buf.Write(file[subIndexReg[1]:])
+	return os.WriteFile(registerPath, buf.Bytes(), 0o644)
+}
@@ -4,14 +4,33 @@ const ReActAgentTemplate = `package agent
 
 import (
 	"context"
+	{{- if .EnableMemory}}
+	"errors"
+	{{- end}}
 	"fmt"
+	{{- if .EnableMemory}}
+	"io"
+	{{- end}}
 
 	"github.com/cloudwego/eino/flow/agent/react"
 	"github.com/cloudwego/eino/schema"
+	{{- if eq .MemoryStore "redis"}}
+	"{{.GoModule}}/biz/dal/redis"
+	{{- end}}
+	{{- if .EnableMemory}}
+	"{{.GoModule}}/internal/agent/memory"
+	{{- end}}
 )
 
 type {{.AgentName}} struct {
 	agent *react.Agent
+	// generate answers the conversation of input, whose last message is the query.
+	generate func(ctx context.Context, input []*schema.Message) (*schema.Message, error)
+	// stream answers the conversation of input chunk by chunk.
+	stream func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error)
+	{{- if .EnableMemory}}
+	memory *memory.Memory
+	{{- end}}
 }
 
 func New{{.AgentName}}(ctx context.Context) (*{{.AgentName}}, error) {
@@ -39,20 +58,737 @@ func New{{.AgentName}}(ctx context.Context) (*{{.AgentName}}, error) {
 	// 	return nil, err
 	// }
 
-	return &{{.AgentName}}{}, nil
+	a := &{{.AgentName}}{
+		generate: func(ctx context.Context, input []*schema.Message) (*schema.Message, error) {
+			// return agent.Generate(ctx, input)
+			return schema.AssistantMessage(fmt.Sprintf("AI Agent ({{.ModelProvider}}/{{.ModelName}}) response to: %s", input[len(input)-1].Content), nil), nil
+		},
+	}
+	a.stream = func(ctx context.Context, input []*schema.Message) (*schema.StreamReader[*schema.Message], error) {
+		// return agent.Stream(ctx, input)
+		result, err := a.generate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return schema.StreamReaderFromArray([]*schema.Message{result}), nil
+	}
+	{{- if .EnableMemory}}
+
+	// 4. Initialize session memory from the "agent_memory" section of conf.yaml
+	memCfg, err := memory.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	store, err := memory.NewStore(memCfg{{if eq .MemoryStore "redis"}}, redis.RedisClient{{end}})
+	if err != nil {
+		return nil, err
+	}
+	mem := memory.New(memCfg, store)
+	if memCfg.Summarize {
+		mem.WithSummarizer(a.summarize)
+	}
+	a.memory = mem
+	{{- end}}
+
+	return a, nil
 }
 
 func (a *{{.AgentName}}) Run(ctx context.Context, query string) (string, error) {
-	// result, err := a.agent.Generate(ctx, query)
-	return fmt.Sprintf("AI Agent ({{.ModelProvider}}/{{.ModelName}}) response to: %s", query), nil
+	result, err := a.generate(ctx, []*schema.Message{schema.UserMessage(query)})
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
 }
 
 func (a *{{.AgentName}}) Stream(ctx context.Context, query string) (*schema.StreamReader[*schema.Message], error) {
-	// stream, err := a.agent.Stream(ctx, query)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	// return stream, nil
-	return nil, fmt.Errorf("Stream method not implemented")
+	return a.stream(ctx, []*schema.Message{schema.UserMessage(query)})
+}
+{{- if .EnableMemory}}
+
+// Chat answers query within a session, the history is loaded from and saved to the session memory.
+func (a *{{.AgentName}}) Chat(ctx context.Context, sessionID, query string) (string, error) {
+	history, err := a.memory.History(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	result, err := a.generate(ctx, append(history, schema.UserMessage(query)))
+	if err != nil {
+		return "", err
+	}
+	if err = a.memory.Append(ctx, sessionID, schema.UserMessage(query), result); err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// ChatStream streams the answer of query within a session like Chat, the answer is saved to the
+// session memory once the stream is read to the end.
+func (a *{{.AgentName}}) ChatStream(ctx context.Context, sessionID, query string) (*schema.StreamReader[*schema.Message], error) {
+	history, err := a.memory.History(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := a.stream(ctx, append(history, schema.UserMessage(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	out, w := schema.Pipe[*schema.Message](1)
+	go func() {
+		var err error
+		defer sr.Close()
+		defer w.Close()
+		var chunks []*schema.Message
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				w.Send(nil, err)
+				return
+			}
+			chunks = append(chunks, chunk)
+			if closed := w.Send(chunk, nil); closed {
+				// the reader stopped, the partial answer is not saved
+				return
+			}
+		}
+		answer := schema.AssistantMessage("", nil)
+		if len(chunks) > 0 {
+			if answer, err = schema.ConcatMessages(chunks); err != nil {
+				w.Send(nil, err)
+				return
+			}
+		}
+		if err = a.memory.Append(ctx, sessionID, schema.UserMessage(query), answer); err != nil {
+			w.Send(nil, err)
+		}
+	}()
+	return out, nil
+}
+
+// ClearSession drops the history of a session.
+func (a *{{.AgentName}}) ClearSession(ctx context.Context, sessionID string) error {
+	return a.memory.Clear(ctx, sessionID)
+}
+
+// summarize asks the model to summarize the history dropped by the session memory, when "summarize" is on.
+func (a *{{.AgentName}}) summarize(ctx context.Context, dropped []*schema.Message) (*schema.Message, error) {
+	input := append([]*schema.Message{
+		schema.SystemMessage("Summarize the conversation below in a few sentences, keep the facts and the requests of the user."),
+	}, dropped...)
+	result, err := a.generate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return schema.SystemMessage("Summary of the earlier conversation: " + result.Content), nil
+}
+{{- end}}
+`
+
+const AgentHandlerTemplate = `package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/hertz-contrib/sse"
+	"{{.GoModule}}/internal/agent"
+)
+
+// AgentRunReq mirrors AgentRunReq in {{.IdlPath}}.
+type AgentRunReq struct {
+	Query     string ` + "`json:\"query\" vd:\"len($)>0\"`" + `
+	SessionID string ` + "`json:\"session_id\"`" + `
+}
+
+// AgentRunResp mirrors AgentRunResp in {{.IdlPath}}.
+type AgentRunResp struct {
+	Answer string ` + "`json:\"answer\"`" + `
+}
+
+// AgentStreamEvent mirrors AgentStreamEvent in {{.IdlPath}}.
+type AgentStreamEvent struct {
+	Content string ` + "`json:\"content\"`" + `
+}
+
+var (
+	instance *agent.{{.AgentName}}
+	initErr  error
+	once     sync.Once
+)
+
+func getAgent(ctx context.Context) (*agent.{{.AgentName}}, error) {
+	once.Do(func() {
+		instance, initErr = agent.New{{.AgentName}}(ctx)
+	})
+	return instance, initErr
+}
+
+// Run calls the agent and returns the whole answer.
+// @router /agent/run [POST]
+func Run(ctx context.Context, c *app.RequestContext) {
+	var req AgentRunReq
+	if err := c.BindAndValidate(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	a, err := getAgent(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	answer, err := runAgent(ctx, a, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	c.JSON(consts.StatusOK, &AgentRunResp{Answer: answer})
+}
+
+// Stream calls the agent and pushes every chunk as a Server-Sent Event.
+// @router /agent/stream [POST]
+func Stream(ctx context.Context, c *app.RequestContext) {
+	var req AgentRunReq
+	if err := c.BindAndValidate(&req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	a, err := getAgent(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	reader, err := streamAgent(ctx, a, &req)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	c.SetStatusCode(consts.StatusOK)
+	stream := sse.NewStream(c)
+	for {
+		msg, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			hlog.CtxErrorf(ctx, "agent stream recv failed: %v", err)
+			_ = stream.Publish(&sse.Event{Event: "error", Data: []byte(err.Error())})
+			return
+		}
+		data, err := json.Marshal(&AgentStreamEvent{Content: msg.Content})
+		if err != nil {
+			hlog.CtxErrorf(ctx, "agent stream marshal failed: %v", err)
+			return
+		}
+		if err = stream.Publish(&sse.Event{Event: "message", Data: data}); err != nil {
+			hlog.CtxErrorf(ctx, "agent stream publish failed: %v", err)
+			return
+		}
+	}
+	_ = stream.Publish(&sse.Event{Event: "done", Data: []byte("[DONE]")})
+}
+`
+
+// AgentCallTemplate calls the agent for the handlers, it is regenerated so that the handlers
+// follow --agent-memory.
+const AgentCallTemplate = `// Code generated by cwgo, regenerated with --agent-memory. DO NOT EDIT.
+
+package agent
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/schema"
+	"{{.GoModule}}/internal/agent"
+)
+
+// runAgent answers req{{if .EnableMemory}} within its session, see {{.AgentName}}.Chat{{end}}.
+func runAgent(ctx context.Context, a *agent.{{.AgentName}}, req *AgentRunReq) (string, error) {
+	{{- if .EnableMemory}}
+	return a.Chat(ctx, req.SessionID, req.Query)
+	{{- else}}
+	return a.Run(ctx, req.Query)
+	{{- end}}
+}
+
+// streamAgent streams the answer of req{{if .EnableMemory}} within its session, see {{.AgentName}}.ChatStream{{end}}.
+func streamAgent(ctx context.Context, a *agent.{{.AgentName}}, req *AgentRunReq) (*schema.StreamReader[*schema.Message], error) {
+	{{- if .EnableMemory}}
+	return a.ChatStream(ctx, req.SessionID, req.Query)
+	{{- else}}
+	return a.Stream(ctx, req.Query)
+	{{- end}}
+}
+`
+
+const AgentRouterTemplate = `package agent
+
+import (
+	"github.com/cloudwego/hertz/pkg/app/server"
+	agent "{{.GoModule}}/biz/handler/agent"
+)
+
+// Register registers the eino agent routes.
+func Register(r *server.Hertz) {
+	root := r.Group("/agent")
+	root.POST("/run", agent.Run)
+	root.POST("/stream", agent.Stream)
+}
+`
+
+const AgentThriftTemplate = `// Reference only: the routes of /agent and their payloads, served by the handlers of biz/handler/agent.
+// No code is generated from this file, keep it out of the IDLs given to cwgo.
+namespace go agent
+
+struct AgentRunReq {
+    1: string query (api.body="query", api.vd="len($)>0");
+    2: string session_id (api.body="session_id");
+}
+
+struct AgentRunResp {
+    1: string answer;
+}
+
+// AgentStreamEvent is the payload of every "message" event sent by /agent/stream.
+struct AgentStreamEvent {
+    1: string content;
+}
+
+service AgentService {
+    AgentRunResp Run(1: AgentRunReq req) (api.post="/agent/run");
+    // Stream answers with "text/event-stream", each event carries an AgentStreamEvent.
+    AgentStreamEvent Stream(1: AgentRunReq req) (api.post="/agent/stream");
+}
+`
+
+const AgentProtoTemplate = `// Reference only: the routes of /agent and their payloads, served by the handlers of biz/handler/agent.
+// No code is generated from this file, keep it out of the IDLs given to cwgo.
+syntax = "proto3";
+
+package agent;
+
+option go_package = "agent";
+
+import "api.proto";
+
+message AgentRunReq {
+  string query = 1 [(api.body) = "query", (api.vd) = "len($)>0"];
+  string session_id = 2 [(api.body) = "session_id"];
+}
+
+message AgentRunResp {
+  string answer = 1;
+}
+
+// AgentStreamEvent is the payload of every "message" event sent by /agent/stream.
+message AgentStreamEvent {
+  string content = 1;
+}
+
+service AgentService {
+  rpc Run(AgentRunReq) returns (AgentRunResp) {
+    option (api.post) = "/agent/run";
+  }
+  // Stream answers with "text/event-stream", each event carries an AgentStreamEvent.
+  rpc Stream(AgentRunReq) returns (AgentStreamEvent) {
+    option (api.post) = "/agent/stream";
+  }
+}
+`
+
+const AgentMemoryTemplate = `package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	{{- if eq .MemoryStore "redis"}}
+	"github.com/redis/go-redis/v9"
+	{{- end}}
+	"gopkg.in/yaml.v3"
+	"{{.GoModule}}/conf"
+)
+
+// Config is the "agent_memory" section of conf/<env>/conf.yaml.
+type Config struct {
+	Store       string        ` + "`yaml:\"store\"`" + `        // memory or redis
+	MaxMessages int           ` + "`yaml:\"max_messages\"`" + ` // keep at most N messages per session, 0 means unlimited
+	Summarize   bool          ` + "`yaml:\"summarize\"`" + `    // summarize the truncated history instead of dropping it
+	TTL         time.Duration ` + "`yaml:\"ttl\"`" + `          // session expiration, 0 means never
+	KeyPrefix   string        ` + "`yaml:\"key_prefix\"`" + `   // redis key prefix
+}
+
+// Store persists the chat history of a session, its methods are atomic so that the requests of
+// a session may run concurrently.
+type Store interface {
+	Load(ctx context.Context, sessionID string) ([]*schema.Message, error)
+	// Append appends msgs to the history of the session, keeping the last max messages if max > 0,
+	// and returns the history after the append.
+	Append(ctx context.Context, sessionID string, max int, msgs ...*schema.Message) ([]*schema.Message, error)
+	// Compact replaces the dropped messages with summary if the history still starts with them,
+	// it reports false if another request compacted or cleared the history meanwhile.
+	Compact(ctx context.Context, sessionID string, dropped []*schema.Message, summary *schema.Message) (bool, error)
+	Clear(ctx context.Context, sessionID string) error
+}
+
+// Summarizer compresses the messages dropped by truncation into a single message,
+// it is usually backed by the same ChatModel as the agent.
+type Summarizer func(ctx context.Context, dropped []*schema.Message) (*schema.Message, error)
+
+// Memory applies the truncation/summarization policy on top of a Store.
+type Memory struct {
+	store      Store
+	cfg        Config
+	summarizer Summarizer
+}
+
+// NewStore creates the Store of the "agent_memory" config.
+{{- if eq .MemoryStore "redis"}}
+// The redis store uses client, usually the one initialized by biz/dal.
+func NewStore(cfg Config, client *redis.Client) (Store, error) {
+{{- else}}
+func NewStore(cfg Config) (Store, error) {
+{{- end}}
+	switch cfg.Store {
+	case "", "memory":
+		return NewInMemoryStore(cfg.TTL), nil
+	{{- if eq .MemoryStore "redis"}}
+	case "redis":
+		if client == nil {
+			return nil, fmt.Errorf("agent memory store redis: the redis client is not initialized, call dal.Init first")
+		}
+		return NewRedisStore(client, cfg.KeyPrefix, cfg.TTL), nil
+	{{- end}}
+	default:
+		return nil, fmt.Errorf("unsupported agent memory store %q", cfg.Store)
+	}
+}
+
+// New creates a Memory keeping the sessions in store.
+func New(cfg Config, store Store) *Memory {
+	return &Memory{store: store, cfg: cfg}
+}
+
+// WithSummarizer enables summarization of truncated history when "summarize" is on.
+func (m *Memory) WithSummarizer(s Summarizer) *Memory {
+	m.summarizer = s
+	return m
+}
+
+// History returns the chat history of a session.
+func (m *Memory) History(ctx context.Context, sessionID string) ([]*schema.Message, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+	return m.store.Load(ctx, sessionID)
+}
+
+// Append appends messages to a session and applies the truncation policy.
+func (m *Memory) Append(ctx context.Context, sessionID string, msgs ...*schema.Message) error {
+	if sessionID == "" {
+		return nil
+	}
+	summarize := m.cfg.Summarize && m.summarizer != nil
+	max := m.cfg.MaxMessages
+	if summarize {
+		// the dropped messages are summarized below
+		max = 0
+	}
+	history, err := m.store.Append(ctx, sessionID, max, msgs...)
+	if err != nil || !summarize || m.cfg.MaxMessages <= 0 || len(history) <= m.cfg.MaxMessages {
+		return err
+	}
+
+	// keep room for the summary message
+	keep := m.cfg.MaxMessages - 1
+	dropped := history[:len(history)-keep]
+	summary, err := m.summarizer(ctx, dropped)
+	if err != nil {
+		return fmt.Errorf("summarize agent memory failed: %w", err)
+	}
+	// a concurrent request of the session may have compacted it first, the next append summarizes
+	// what is still exceeding
+	_, err = m.store.Compact(ctx, sessionID, dropped, summary)
+	return err
+}
+
+// Clear drops the chat history of a session.
+func (m *Memory) Clear(ctx context.Context, sessionID string) error {
+	return m.store.Clear(ctx, sessionID)
+}
+
+// LoadConfig reads the "agent_memory" section from conf/<env>/conf.yaml.
+func LoadConfig() (Config, error) {
+	cfg := Config{Store: "memory"}
+	content, err := os.ReadFile(filepath.Join("conf", conf.GetEnv(), "conf.yaml"))
+	if err != nil {
+		return cfg, err
+	}
+	var c struct {
+		AgentMemory *Config ` + "`yaml:\"agent_memory\"`" + `
+	}
+	if err = yaml.Unmarshal(content, &c); err != nil {
+		return cfg, err
+	}
+	if c.AgentMemory != nil {
+		cfg = *c.AgentMemory
+	}
+	return cfg, nil
 }
 `
+
+const AgentMemoryInMemoryTemplate = `package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type session struct {
+	mu       sync.Mutex // serializes the updates of the session
+	history  []*schema.Message
+	expireAt time.Time
+}
+
+func (s *session) expired() bool {
+	return !s.expireAt.IsZero() && time.Now().After(s.expireAt)
+}
+
+// InMemoryStore keeps sessions in process memory, it is meant for local testing
+// and single instance deployments.
+type InMemoryStore struct {
+	mu       sync.Mutex // guards sessions
+	ttl      time.Duration
+	sessions map[string]*session
+}
+
+func NewInMemoryStore(ttl time.Duration) *InMemoryStore {
+	return &InMemoryStore{ttl: ttl, sessions: make(map[string]*session)}
+}
+
+// lock returns the session locked, created if missing or expired.
+func (s *InMemoryStore) lock(sessionID string) *session {
+	s.mu.Lock()
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		sess = &session{}
+		s.sessions[sessionID] = sess
+	}
+	s.mu.Unlock()
+
+	sess.mu.Lock()
+	if sess.expired() {
+		sess.history = nil
+	}
+	return sess
+}
+
+func (s *InMemoryStore) Load(_ context.Context, sessionID string) ([]*schema.Message, error) {
+	sess := s.lock(sessionID)
+	defer sess.mu.Unlock()
+	return append([]*schema.Message(nil), sess.history...), nil
+}
+
+func (s *InMemoryStore) Append(_ context.Context, sessionID string, max int, msgs ...*schema.Message) ([]*schema.Message, error) {
+	sess := s.lock(sessionID)
+	defer sess.mu.Unlock()
+	history := append(sess.history, msgs...)
+	if max > 0 && len(history) > max {
+		history = history[len(history)-max:]
+	}
+	sess.history = append([]*schema.Message(nil), history...)
+	s.touch(sess)
+	return append([]*schema.Message(nil), history...), nil
+}
+
+func (s *InMemoryStore) Compact(_ context.Context, sessionID string, dropped []*schema.Message, summary *schema.Message) (bool, error) {
+	sess := s.lock(sessionID)
+	defer sess.mu.Unlock()
+	if len(sess.history) < len(dropped) {
+		return false, nil
+	}
+	for i, msg := range dropped {
+		if sess.history[i] != msg {
+			return false, nil
+		}
+	}
+	sess.history = append([]*schema.Message{summary}, sess.history[len(dropped):]...)
+	s.touch(sess)
+	return true, nil
+}
+
+func (s *InMemoryStore) Clear(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+func (s *InMemoryStore) touch(sess *session) {
+	if s.ttl > 0 {
+		sess.expireAt = time.Now().Add(s.ttl)
+	}
+}
+`
+
+const AgentMemoryRedisTemplate = `package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultKeyPrefix = "agent:session:"
+
+// RedisStore keeps every session as a redis list of JSON encoded messages.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore creates a RedisStore on client, usually the one initialized by biz/dal/redis.
+func NewRedisStore(client *redis.Client, prefix string, ttl time.Duration) *RedisStore {
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+	return &RedisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (s *RedisStore) Load(ctx context.Context, sessionID string) ([]*schema.Message, error) {
+	values, err := s.client.LRange(ctx, s.prefix+sessionID, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decode(values)
+}
+
+// Append pushes msgs and trims the list in a transaction, the concurrent appends of the session
+// are all kept.
+func (s *RedisStore) Append(ctx context.Context, sessionID string, max int, msgs ...*schema.Message) ([]*schema.Message, error) {
+	key := s.prefix + sessionID
+	values, err := encode(msgs)
+	if err != nil {
+		return nil, err
+	}
+	var history *redis.StringSliceCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(values) > 0 {
+			pipe.RPush(ctx, key, values...)
+		}
+		if max > 0 {
+			pipe.LTrim(ctx, key, int64(-max), -1)
+		}
+		if s.ttl > 0 {
+			pipe.Expire(ctx, key, s.ttl)
+		}
+		history = pipe.LRange(ctx, key, 0, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decode(history.Val())
+}
+
+// Compact replaces the dropped messages in a transaction watching the session, it fails if
+// another request updated the session meanwhile.
+func (s *RedisStore) Compact(ctx context.Context, sessionID string, dropped []*schema.Message, summary *schema.Message) (bool, error) {
+	key := s.prefix + sessionID
+	want, err := encode(dropped)
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(summary)
+	if err != nil {
+		return false, err
+	}
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		head, err := tx.LRange(ctx, key, 0, int64(len(dropped)-1)).Result()
+		if err != nil {
+			return err
+		}
+		if len(head) != len(want) {
+			return redis.TxFailedErr
+		}
+		for i, v := range head {
+			if v != string(want[i].([]byte)) {
+				return redis.TxFailedErr
+			}
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LTrim(ctx, key, int64(len(dropped)), -1)
+			pipe.LPush(ctx, key, b)
+			if s.ttl > 0 {
+				pipe.Expire(ctx, key, s.ttl)
+			}
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *RedisStore) Clear(ctx context.Context, sessionID string) error {
+	return s.client.Del(ctx, s.prefix+sessionID).Err()
+}
+
+func encode(msgs []*schema.Message) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, b)
+	}
+	return values, nil
+}
+
+func decode(values []string) ([]*schema.Message, error) {
+	history := make([]*schema.Message, 0, len(values))
+	for _, v := range values {
+		msg := new(schema.Message)
+		if err := json.Unmarshal([]byte(v), msg); err != nil {
+			return nil, err
+		}
+		history = append(history, msg)
+	}
+	return history, nil
+}
+`
+
+const AgentMemoryConfTemplate = `
+agent_memory:
+  store: {{.Store}}
+  max_messages: 20
+  summarize: false
+  ttl: 24h
+  key_prefix: "agent:session:"
+`
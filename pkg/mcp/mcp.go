@@ -0,0 +1,380 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	hzutil "github.com/cloudwego/hertz/cmd/hz/util"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const (
+	toolsFile   = "biz/mcpserver/tools.go"
+	forwardFile = "biz/mcpserver/forward.go"
+	mainFile    = "cmd/mcp/main.go"
+)
+
+var httpMethods = []string{"get", "post", "put", "delete", "patch", "head", "options"}
+
+type templateData struct {
+	GoModule      string
+	ServerName    string
+	Type          string
+	GenImport     string // kitex_gen or hertz_gen package of the IDL
+	GenPkg        string
+	DefaultTarget string
+	Services      []*serviceData
+}
+
+type serviceData struct {
+	Name          string
+	ClientImport  string
+	ClientPkg     string
+	ClientName    string // e.g. HelloService of hello_service.NewHelloServiceClient, HTTP only
+	ToolsTypeName string // e.g. HelloServiceTools
+	ToolsVarName  string
+	Tools         []*toolData
+}
+
+type toolData struct {
+	Name        string
+	Description string
+	SchemaConst string
+	Schema      string // Go literal of the input schema
+	HandlerName string
+	GoMethod    string
+
+	// either the single request struct is the tool input, or each argument is a property of it (RPC only)
+	Flat    bool
+	ReqType string
+	Args    []argData
+	Void    bool
+}
+
+type argData struct {
+	Field string
+	Type  string
+	JSON  string
+}
+
+// Mcp generates an MCP server which exposes each method of the IDL services as a
+// tool, and forwards tool calls to the service through the kitex client or the hertz client
+// generated by cwgo client.
+func Mcp(c *config.McpArgument) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	idl, err := parser.ParseIDL(c.IdlPath, c.ProtoSearchPath)
+	if err != nil {
+		return err
+	}
+	if len(idl.Services) == 0 {
+		return fmt.Errorf("no service found in idl '%s'", c.IdlPath)
+	}
+	if c.ServerName == "" {
+		c.ServerName = strings.ToLower(idl.Services[len(idl.Services)-1].Name)
+	}
+
+	data, err := buildTemplateData(c, idl)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		path      string
+		tpl       string
+		overwrite bool
+	}{
+		{path: toolsFile, tpl: toolsTpl, overwrite: true},
+		{path: forwardFile, tpl: forwardTpl, overwrite: true},
+		{path: mainFile, tpl: mainTpl},
+	}
+	for _, f := range files {
+		if err = utils.WriteTemplate(filepath.Join(c.OutDir, f.path), f.tpl, data, f.overwrite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func check(c *config.McpArgument) (err error) {
+	if c.IdlPath == "" {
+		return errors.New("idl path is empty")
+	}
+	if c.Type != consts.RPC && c.Type != consts.HTTP {
+		return errors.New("generate type not supported")
+	}
+	if c.OutDir, err = filepath.Abs(c.OutDir); err != nil {
+		return err
+	}
+	if c.GoMod == "" {
+		module, _, ok := utils.SearchGoMod(c.OutDir, true)
+		if !ok {
+			return errors.New("go.mod not found, please specify a module name with the '-module' flag")
+		}
+		c.GoMod = module
+	}
+	if c.ModelDir == "" {
+		c.ModelDir = consts.DefaultKitexModelDir
+		if c.Type == consts.HTTP {
+			c.ModelDir = consts.DefaultHZModelDir
+		}
+	}
+	if c.ClientDir == "" {
+		c.ClientDir = consts.DefaultHZClientDir
+	}
+	return nil
+}
+
+func buildTemplateData(c *config.McpArgument, idl *parser.IDL) (*templateData, error) {
+	data := &templateData{
+		GoModule:      c.GoMod,
+		ServerName:    c.ServerName,
+		Type:          c.Type,
+		DefaultTarget: "127.0.0.1:8888",
+	}
+	modelPrefix := c.GoMod + "/" + filepath.ToSlash(c.ModelDir)
+	if c.Use != "" {
+		modelPrefix = c.Use
+	}
+	data.GenImport, data.GenPkg = idl.GoImport(c.GoMod, modelPrefix)
+	if c.Type == consts.HTTP {
+		data.DefaultTarget = "http://127.0.0.1:8888"
+	}
+
+	schemas := newSchemaBuilder(idl)
+	for _, svc := range idl.Services {
+		sd := &serviceData{
+			Name:          svc.Name,
+			ClientImport:  data.GenImport + "/" + strings.ToLower(svc.Name),
+			ClientPkg:     strings.ToLower(svc.Name),
+			ToolsTypeName: parser.GoName(idl.Type, svc.Name) + "Tools",
+		}
+		if c.Type == consts.HTTP {
+			// the package of the service in the client dir, as cwgo client generates it
+			sd.ClientPkg = hzutil.ToSnakeCase(svc.Name)
+			sd.ClientImport = c.GoMod + "/" + filepath.ToSlash(c.ClientDir) + "/" + sd.ClientPkg
+			sd.ClientName = hzutil.ToCamelCase(svc.Name)
+		}
+		sd.ToolsVarName = lowerFirst(sd.ToolsTypeName)
+		for _, m := range svc.Methods {
+			tool, err := buildTool(c, idl, schemas, data.GenPkg, svc, m)
+			if err != nil {
+				log.Warnf("skip method %s.%s: %v\n", svc.Name, m.Name, err)
+				continue
+			}
+			if len(idl.Services) > 1 {
+				tool.Name = snakeName(svc.Name) + "_" + tool.Name
+			}
+			tool.SchemaConst = lowerFirst(parser.GoName(idl.Type, svc.Name)) + tool.GoMethod + "Schema"
+			sd.Tools = append(sd.Tools, tool)
+		}
+		if len(sd.Tools) > 0 {
+			data.Services = append(data.Services, sd)
+		}
+	}
+	if len(data.Services) == 0 {
+		return nil, fmt.Errorf("no method of idl '%s' can be exposed as a tool", c.IdlPath)
+	}
+	return data, nil
+}
+
+func buildTool(c *config.McpArgument, idl *parser.IDL, schemas *schemaBuilder, genPkg string, svc *parser.Service, m *parser.Method) (*toolData, error) {
+	if m.ClientStreaming || m.ServerStreaming {
+		return nil, errors.New("streaming methods are not supported")
+	}
+	tool := &toolData{
+		Name:        snakeName(m.Name),
+		Description: m.Comment,
+		GoMethod:    parser.GoName(idl.Type, m.Name),
+		Void:        m.Response == nil || m.Oneway,
+	}
+	tool.HandlerName = lowerFirst(tool.GoMethod)
+	if tool.Description == "" {
+		tool.Description = fmt.Sprintf("Call %s.%s", svc.Name, m.Name)
+	}
+
+	var reqStruct *parser.Struct
+	if len(m.Args) == 1 && m.Args[0].Type.Kind == parser.KindStruct {
+		reqStruct = idl.LookupStruct(m.Args[0].Type.Name)
+	}
+
+	var schema *jsonSchema
+	if reqStruct != nil {
+		schema = schemas.structSchema(m.Args[0].Type.Name, reqStruct)
+	} else {
+		schema = schemas.fieldsSchema(m.Args, "")
+	}
+	schema.Description = ""
+
+	if c.Type == consts.HTTP {
+		// the hertz client takes the request struct and returns the response struct
+		if reqStruct == nil || tool.Void {
+			return nil, errors.New("the hertz client needs a request and a response struct")
+		}
+		tool.GoMethod = hzutil.CamelString(m.Name)
+		paths, err := httpPathFields(m, reqStruct)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range paths {
+			if !containsString(schema.Required, f) {
+				schema.Required = append(schema.Required, f)
+			}
+		}
+	}
+	if reqStruct != nil {
+		if !idl.IsLocal(m.Args[0].Type.Name) {
+			return nil, fmt.Errorf("request type %s is declared in an include", m.Args[0].Type.Name)
+		}
+		tool.Flat = true
+		tool.ReqType = genPkg + "." + parser.GoName(idl.Type, reqStruct.Name)
+	} else {
+		for _, arg := range m.Args {
+			typ, err := goType(idl, genPkg, arg.Type)
+			if err != nil {
+				return nil, err
+			}
+			tool.Args = append(tool.Args, argData{Field: parser.GoName(idl.Type, arg.Name), Type: typ, JSON: arg.Name})
+		}
+	}
+
+	raw, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tool.Schema = goStringLiteral(string(raw))
+	return tool, nil
+}
+
+// httpPathFields returns the fields of the request bound to the path of the route, the hertz
+// client is only generated for the methods with a route.
+func httpPathFields(m *parser.Method, req *parser.Struct) ([]string, error) {
+	routed := false
+	for _, method := range httpMethods {
+		if _, ok := m.Annotations.Get("api." + method); ok {
+			routed = true
+			break
+		}
+	}
+	if !routed {
+		return nil, errors.New("no api.{method} annotation")
+	}
+	var fields []string
+	for _, f := range req.Fields {
+		if _, ok := f.Annotations.Get("api.path"); ok {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields, nil
+}
+
+func goType(idl *parser.IDL, genPkg string, t *parser.Type) (string, error) {
+	switch t.Kind {
+	case parser.KindList, parser.KindSet:
+		elem, err := goType(idl, genPkg, t.ValueType)
+		return "[]" + elem, err
+	case parser.KindMap:
+		key, err := goType(idl, genPkg, t.KeyType)
+		if err != nil {
+			return "", err
+		}
+		val, err := goType(idl, genPkg, t.ValueType)
+		return "map[" + key + "]" + val, err
+	case parser.KindStruct, parser.KindEnum:
+		if !idl.IsLocal(t.Name) {
+			return "", fmt.Errorf("type %s is declared in an include", t.Name)
+		}
+		name := genPkg + "." + parser.GoName(idl.Type, t.Name)
+		if t.Kind == parser.KindStruct {
+			name = "*" + name
+		}
+		return name, nil
+	}
+	switch t.Name {
+	case "byte", "i8":
+		return "int8", nil
+	case "i16":
+		return "int16", nil
+	case "i32":
+		return "int32", nil
+	case "i64":
+		return "int64", nil
+	case "u32":
+		return "uint32", nil
+	case "u64":
+		return "uint64", nil
+	case "float":
+		return "float32", nil
+	case "double":
+		return "float64", nil
+	case "binary":
+		return "[]byte", nil
+	}
+	return t.Name, nil
+}
+
+func snakeName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && runes[i-1] != '_')) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func goStringLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
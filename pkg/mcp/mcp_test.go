@@ -0,0 +1,169 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+const helloIDL = `
+namespace go hello.example
+
+struct HelloReq {
+    1: required string Name (api.query="name");
+    2: i64 Id (api.path="id");
+    3: string Token (api.header="X-Token");
+}
+
+struct HelloResp { 1: string Msg }
+
+service HelloService {
+    // say hello
+    HelloResp Hello(1: HelloReq req) (api.get="/hello/:id");
+    HelloResp Add(1: i32 a, 2: list<i64> b) (api.post="/add");
+    void Ping(1: HelloReq req);
+}
+
+service EchoService {
+    HelloResp Echo(1: HelloReq req) (api.put="/echo/:name");
+}
+`
+
+func generate(t *testing.T, typ string) (*config.McpArgument, map[string]string) {
+	_, path := parseIDL(t, "hello.thrift", helloIDL)
+	c := &config.McpArgument{Type: typ, IdlPath: path, GoMod: "example.com/hello", OutDir: t.TempDir()}
+	assert.NoError(t, Mcp(c))
+	files := make(map[string]string)
+	for _, f := range []string{toolsFile, forwardFile, mainFile} {
+		content, err := os.ReadFile(filepath.Join(c.OutDir, f))
+		assert.NoError(t, err)
+		files[f] = string(content)
+	}
+	return c, files
+}
+
+func TestMcpRPC(t *testing.T) {
+	_, files := generate(t, consts.RPC)
+	tools := files[toolsFile]
+
+	// the import of kitex_gen follows the namespace, like the kitex generated code
+	assert.Contains(t, tools, `example "example.com/hello/kitex_gen/hello/example"`)
+	assert.Contains(t, tools, `"example.com/hello/kitex_gen/hello/example/helloservice"`)
+	assert.Contains(t, tools, `"example.com/hello/kitex_gen/hello/example/echoservice"`)
+
+	// several services prefix the tool names with the service
+	assert.Contains(t, tools, `mcp.NewToolWithRawSchema("hello_service_hello", "say hello", json.RawMessage(helloServiceHelloSchema))`)
+	assert.Contains(t, tools, `mcp.NewToolWithRawSchema("echo_service_echo", "Call EchoService.Echo", json.RawMessage(echoServiceEchoSchema))`)
+
+	// a single request struct is the tool input, other arguments are its properties
+	assert.Contains(t, tools, "req := new(example.HelloReq)")
+	assert.Contains(t, tools, "A int32   `json:\"a\"`")
+	assert.Contains(t, tools, "B []int64 `json:\"b\"`")
+	assert.Contains(t, tools, "resp, err := t.client.Add(ctx, args.A, args.B)")
+	assert.Contains(t, tools, "if err := t.client.Ping(ctx, req); err != nil {")
+
+	assert.Contains(t, files[forwardFile], "func jsonResult(")
+	assert.Contains(t, files[mainFile], `"127.0.0.1:8888"`)
+}
+
+func TestMcpHTTP(t *testing.T) {
+	c, files := generate(t, consts.HTTP)
+	tools := files[toolsFile]
+
+	assert.NotContains(t, tools, "kitex")
+	assert.Contains(t, files[mainFile], `"http://127.0.0.1:8888"`)
+
+	// the tools call the hertz clients generated by cwgo client
+	assert.Contains(t, tools, `example "example.com/hello/hertz_gen/hello/example"`)
+	assert.Contains(t, tools, `"example.com/hello/biz/http/hello_service"`)
+	assert.Contains(t, tools, `"example.com/hello/biz/http/echo_service"`)
+	assert.Contains(t, tools, "cli, err := hello_service.NewHelloServiceClient(target)")
+	assert.Contains(t, tools, "client hello_service.Client")
+	assert.Contains(t, tools, "req := new(example.HelloReq)")
+	assert.Contains(t, tools, "resp, _, err := t.client.Hello(ctx, req)")
+	assert.Contains(t, tools, "resp, _, err := t.client.Echo(ctx, req)")
+	assert.NotContains(t, files[forwardFile], "hertz")
+
+	// path params are required
+	assert.Contains(t, tools, "\"required\": [\n    \"Name\",\n    \"Id\"\n  ]")
+
+	// Add has no request struct and Ping no route, the hertz client has no method for them
+	assert.NotContains(t, tools, "t.client.Add(")
+	assert.NotContains(t, tools, "ping")
+
+	// main.go is kept, the tools are regenerated
+	main := filepath.Join(c.OutDir, mainFile)
+	assert.NoError(t, os.WriteFile(main, []byte("package main\n"), 0o644))
+	assert.NoError(t, Mcp(c))
+	content, err := os.ReadFile(main)
+	assert.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+}
+
+func TestMcpModelDir(t *testing.T) {
+	_, path := parseIDL(t, "hello.thrift", helloIDL)
+	tests := []struct {
+		arg   config.McpArgument
+		model string
+		cli   string
+	}{
+		{
+			arg:   config.McpArgument{Type: consts.RPC, ModelDir: "rpc/gen"},
+			model: `example "example.com/hello/rpc/gen/hello/example"`,
+			cli:   `"example.com/hello/rpc/gen/hello/example/helloservice"`,
+		},
+		{
+			arg:   config.McpArgument{Type: consts.RPC, Use: "example.com/shared/kitex_gen"},
+			model: `example "example.com/shared/kitex_gen/hello/example"`,
+			cli:   `"example.com/shared/kitex_gen/hello/example/helloservice"`,
+		},
+		{
+			arg:   config.McpArgument{Type: consts.HTTP, ModelDir: "biz/model", ClientDir: "biz/client"},
+			model: `example "example.com/hello/biz/model/hello/example"`,
+			cli:   `"example.com/hello/biz/client/hello_service"`,
+		},
+	}
+	for _, tt := range tests {
+		c := tt.arg
+		c.IdlPath, c.GoMod, c.OutDir = path, "example.com/hello", t.TempDir()
+		assert.NoError(t, Mcp(&c))
+		content, err := os.ReadFile(filepath.Join(c.OutDir, toolsFile))
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), tt.model)
+		assert.Contains(t, string(content), tt.cli)
+	}
+}
+
+func TestMcpNoTool(t *testing.T) {
+	_, path := parseIDL(t, "hello.thrift", `
+namespace go hello
+service HelloService {
+    void Ping()
+}
+`)
+	c := &config.McpArgument{Type: consts.HTTP, IdlPath: path, GoMod: "example.com/hello", OutDir: t.TempDir()}
+	assert.Error(t, Mcp(c))
+
+	c.Type = "GRPC"
+	assert.Error(t, Mcp(c))
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+)
+
+// jsonSchema is the subset of JSON Schema used to describe tool inputs.
+type jsonSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []int64                `json:"enum,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+}
+
+type schemaBuilder struct {
+	idl *parser.IDL
+	// structs being expanded, recursive references are left as plain objects
+	visiting map[string]bool
+}
+
+func newSchemaBuilder(idl *parser.IDL) *schemaBuilder {
+	return &schemaBuilder{idl: idl, visiting: make(map[string]bool)}
+}
+
+// structSchema describes the struct, its own name is not expanded again in recursive fields.
+func (b *schemaBuilder) structSchema(name string, st *parser.Struct) *jsonSchema {
+	b.visiting[name] = true
+	defer delete(b.visiting, name)
+	return b.fieldsSchema(st.Fields, st.Comment)
+}
+
+// fieldsSchema describes an object whose properties are the given fields.
+func (b *schemaBuilder) fieldsSchema(fields []*parser.Field, description string) *jsonSchema {
+	s := &jsonSchema{
+		Type:        "object",
+		Description: description,
+		Properties:  make(map[string]*jsonSchema, len(fields)),
+	}
+	for _, f := range fields {
+		fs := b.typeSchema(f.Type)
+		if f.Comment != "" {
+			fs.Description = f.Comment
+		}
+		s.Properties[f.Name] = fs
+		if f.Required {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
+
+func (b *schemaBuilder) typeSchema(t *parser.Type) *jsonSchema {
+	switch t.Kind {
+	case parser.KindList, parser.KindSet:
+		return &jsonSchema{Type: "array", Items: b.typeSchema(t.ValueType), UniqueItems: t.Kind == parser.KindSet}
+	case parser.KindMap:
+		return &jsonSchema{Type: "object", AdditionalProperties: b.typeSchema(t.ValueType)}
+	case parser.KindEnum:
+		s := &jsonSchema{Type: "integer"}
+		if e := b.idl.LookupEnum(t.Name); e != nil {
+			s.Description = e.Comment
+			for _, v := range e.Values {
+				s.Enum = append(s.Enum, v.Value)
+				s.Description = joinDescription(s.Description, fmt.Sprintf("%d: %s", v.Value, v.Name))
+			}
+		}
+		return s
+	case parser.KindStruct:
+		st := b.idl.LookupStruct(t.Name)
+		if st == nil || b.visiting[t.Name] {
+			return &jsonSchema{Type: "object"}
+		}
+		return b.structSchema(t.Name, st)
+	}
+
+	switch t.Name {
+	case "bool":
+		return &jsonSchema{Type: "boolean"}
+	case "byte", "i8", "i16", "i32", "i64", "u32", "u64":
+		return &jsonSchema{Type: "integer"}
+	case "float", "double":
+		return &jsonSchema{Type: "number"}
+	case "binary":
+		return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+func joinDescription(desc, line string) string {
+	if desc == "" {
+		return line
+	}
+	return desc + "\n" + line
+}
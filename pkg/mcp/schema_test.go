@@ -0,0 +1,109 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseIDL(t *testing.T, name, content string) (*parser.IDL, string) {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	idl, err := parser.ParseIDL(path, nil)
+	assert.NoError(t, err)
+	return idl, path
+}
+
+func TestStructSchema(t *testing.T) {
+	idl, _ := parseIDL(t, "tree.thrift", `
+namespace go tree
+
+enum Color {
+    RED = 1
+    BLUE = 2
+}
+
+// a node of the tree
+struct Node {
+    // name of the node
+    1: required string Name;
+    2: optional i64 Weight;
+    3: double Score;
+    4: bool Leaf;
+    5: binary Data;
+    6: list<Node> Children;
+    7: set<i32> Tags;
+    8: map<string, Color> Colors;
+    9: Node Parent;
+}
+`)
+
+	s := newSchemaBuilder(idl).structSchema("Node", idl.LookupStruct("Node"))
+	raw, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "type": "object",
+  "description": "a node of the tree",
+  "properties": {
+    "Name": {"type": "string", "description": "name of the node"},
+    "Weight": {"type": "integer"},
+    "Score": {"type": "number"},
+    "Leaf": {"type": "boolean"},
+    "Data": {"type": "string", "contentEncoding": "base64"},
+    "Children": {"type": "array", "items": {"type": "object"}},
+    "Tags": {"type": "array", "items": {"type": "integer"}, "uniqueItems": true},
+    "Colors": {
+      "type": "object",
+      "additionalProperties": {"type": "integer", "enum": [1, 2], "description": "1: RED\n2: BLUE"}
+    },
+    "Parent": {"type": "object"}
+  },
+  "required": ["Name"]
+}`, string(raw))
+}
+
+func TestFieldsSchema(t *testing.T) {
+	idl, _ := parseIDL(t, "hello.thrift", `
+namespace go hello
+
+struct Item { 1: string Name }
+
+service HelloService {
+    void Put(1: Item item, 2: list<Item> items)
+}
+`)
+
+	m := idl.Services[0].Methods[0]
+	b := newSchemaBuilder(idl)
+	raw, err := json.Marshal(b.fieldsSchema(m.Args, ""))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+  "type": "object",
+  "properties": {
+    "item": {"type": "object", "properties": {"Name": {"type": "string"}}},
+    "items": {"type": "array", "items": {"type": "object", "properties": {"Name": {"type": "string"}}}}
+  }
+}`, string(raw))
+	// the expansion of a struct does not leak into the next one
+	assert.Empty(t, b.visiting)
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+var toolsTpl = `// Code generated by cwgo mcp. DO NOT EDIT.
+
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+{{- if eq .Type "RPC"}}
+	"github.com/cloudwego/kitex/client"
+{{- end}}
+
+	{{.GenPkg}} "{{.GenImport}}"
+{{- range .Services}}
+	"{{.ClientImport}}"
+{{- end}}
+)
+
+// NewServer creates the MCP server of {{.ServerName}}. Every tool forwards its call to the service at target.
+func NewServer(target string) (*server.MCPServer, error) {
+	s := server.NewMCPServer("{{.ServerName}}", "1.0.0", server.WithToolCapabilities(false))
+{{range .Services}}
+	{{.ToolsVarName}}, err := new{{.ToolsTypeName}}(target)
+	if err != nil {
+		return nil, err
+	}
+	s.AddTools({{.ToolsVarName}}.serverTools()...)
+{{- end}}
+	return s, nil
+}
+{{range $svc := .Services}}
+type {{.ToolsTypeName}} struct {
+	client {{.ClientPkg}}.Client
+}
+
+func new{{.ToolsTypeName}}(target string) (*{{.ToolsTypeName}}, error) {
+{{- if eq $.Type "HTTP"}}
+	cli, err := {{.ClientPkg}}.New{{.ClientName}}Client(target)
+{{- else}}
+	cli, err := {{.ClientPkg}}.NewClient("{{$.ServerName}}", client.WithHostPorts(target))
+{{- end}}
+	if err != nil {
+		return nil, err
+	}
+	return &{{.ToolsTypeName}}{client: cli}, nil
+}
+
+func (t *{{.ToolsTypeName}}) serverTools() []server.ServerTool {
+	return []server.ServerTool{
+{{- range .Tools}}
+		{Tool: mcp.NewToolWithRawSchema("{{.Name}}", {{printf "%q" .Description}}, json.RawMessage({{.SchemaConst}})), Handler: t.{{.HandlerName}}},
+{{- end}}
+	}
+}
+{{range .Tools}}
+func (t *{{$svc.ToolsTypeName}}) {{.HandlerName}}(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+{{- if .Flat}}
+	req := new({{.ReqType}})
+	if err := bindArguments(request, req); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+{{- if eq $.Type "HTTP"}}
+	resp, _, err := t.client.{{.GoMethod}}(ctx, req)
+	if err != nil {
+{{- else if .Void}}
+	if err := t.client.{{.GoMethod}}(ctx, req); err != nil {
+{{- else}}
+	resp, err := t.client.{{.GoMethod}}(ctx, req)
+	if err != nil {
+{{- end}}
+{{- else}}
+	var args struct {
+{{- range .Args}}
+		{{.Field}} {{.Type}} ` + "`" + `json:"{{.JSON}}"` + "`" + `
+{{- end}}
+	}
+	if err := bindArguments(request, &args); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+{{- if .Void}}
+	if err := t.client.{{.GoMethod}}(ctx{{range .Args}}, args.{{.Field}}{{end}}); err != nil {
+{{- else}}
+	resp, err := t.client.{{.GoMethod}}(ctx{{range .Args}}, args.{{.Field}}{{end}})
+	if err != nil {
+{{- end}}
+{{- end}}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+{{- if .Void}}
+	return mcp.NewToolResultText("ok"), nil
+{{- else}}
+	return jsonResult(resp)
+{{- end}}
+}
+{{end}}
+{{- end}}
+const (
+{{- range .Services}}
+{{- range .Tools}}
+	{{.SchemaConst}} = {{.Schema}}
+{{- end}}
+{{- end}}
+)
+`
+
+var forwardTpl = `// Code generated by cwgo mcp. DO NOT EDIT.
+
+package mcpserver
+
+import (
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// bindArguments decodes the tool call arguments into v.
+func bindArguments(request mcp.CallToolRequest, v interface{}) error {
+	raw, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// jsonResult returns the response of the service as the JSON text of the tool result.
+func jsonResult(resp interface{}) (*mcp.CallToolResult, error) {
+	out, err := json.Marshal(resp)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+`
+
+var mainTpl = `package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"{{.GoModule}}/biz/mcpserver"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// main serves the MCP tools of {{.ServerName}}. With the stdio transport stdout carries
+// the protocol, so logs must go to stderr.
+func main() {
+	transport := flag.String("transport", "stdio", "MCP transport: stdio or sse")
+	addr := flag.String("addr", ":8090", "listen address of the sse transport")
+	target := flag.String("target", "{{.DefaultTarget}}", "address of the {{.ServerName}} service")
+	flag.Parse()
+
+	s, err := mcpserver.NewServer(*target)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *transport {
+	case "stdio":
+		err = server.ServeStdio(s)
+	case "sse":
+		err = server.NewSSEServer(s).Start(*addr)
+	default:
+		err = fmt.Errorf("unknown transport %q", *transport)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+`
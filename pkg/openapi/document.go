@@ -0,0 +1,173 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the OpenAPI version of the generated documents.
+const Version = "3.1.0"
+
+// Document is the subset of an OpenAPI 3.1 document which can be derived from an IDL.
+type Document struct {
+	OpenAPI    string              `yaml:"openapi"`
+	Info       Info                `yaml:"info"`
+	Tags       []Tag               `yaml:"tags,omitempty"`
+	Paths      map[string]PathItem `yaml:"paths"`
+	Components Components          `yaml:"components,omitempty"`
+}
+
+type Info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type Tag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path, keyed by the lower case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string             `yaml:"tags,omitempty"`
+	Summary     string               `yaml:"summary,omitempty"`
+	OperationID string               `yaml:"operationId"`
+	Parameters  []*Parameter         `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description,omitempty"`
+	Required    bool    `yaml:"required,omitempty"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type RequestBody struct {
+	Content map[string]*MediaType `yaml:"content"`
+}
+
+type Response struct {
+	Description string                `yaml:"description"`
+	Headers     map[string]*Header    `yaml:"headers,omitempty"`
+	Content     map[string]*MediaType `yaml:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `yaml:"description,omitempty"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `yaml:"schemas,omitempty"`
+}
+
+// Schema is the subset of JSON Schema 2020-12 used to describe IDL types.
+type Schema struct {
+	Ref                  string      `yaml:"$ref,omitempty"`
+	Type                 string      `yaml:"type,omitempty"`
+	Format               string      `yaml:"format,omitempty"`
+	Description          string      `yaml:"description,omitempty"`
+	Properties           *Properties `yaml:"properties,omitempty"`
+	Required             []string    `yaml:"required,omitempty"`
+	Items                *Schema     `yaml:"items,omitempty"`
+	AdditionalProperties *Schema     `yaml:"additionalProperties,omitempty"`
+	Enum                 []int64     `yaml:"enum,omitempty"`
+	EnumNames            []string    `yaml:"x-enum-varnames,omitempty"` // names of the enum values in the IDL
+	Default              string      `yaml:"default,omitempty"`
+	ContentEncoding      string      `yaml:"contentEncoding,omitempty"`
+	UniqueItems          bool        `yaml:"uniqueItems,omitempty"`
+}
+
+// Properties keeps the properties of an object schema in the order of the IDL fields.
+type Properties struct {
+	keys   []string
+	values map[string]*Schema
+}
+
+func (p *Properties) Set(key string, s *Schema) {
+	if p.values == nil {
+		p.values = make(map[string]*Schema)
+	}
+	if _, ok := p.values[key]; !ok {
+		p.keys = append(p.keys, key)
+	}
+	p.values[key] = s
+}
+
+func (p *Properties) Get(key string) *Schema {
+	return p.values[key]
+}
+
+func (p *Properties) Keys() []string {
+	return p.keys
+}
+
+func (p *Properties) IsZero() bool {
+	return p == nil || len(p.keys) == 0
+}
+
+func (p *Properties) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range p.keys {
+		v := new(yaml.Node)
+		if err := v.Encode(p.values[k]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, v)
+	}
+	return node, nil
+}
+
+// Marshal encodes the document as YAML.
+func (d *Document) Marshal() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the document as YAML to path, creating its directory if needed.
+func (d *Document) WriteFile(path string) error {
+	data, err := d.Marshal()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openapi describes the HTTP routes declared by the api.{method}
+// annotations of IDLs as an OpenAPI 3.1 document, binding the request fields
+// the same way the code generated by hz does.
+package openapi
+
+import (
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+)
+
+// FileName is the name of the document written to the root of generated projects.
+const FileName = "openapi.yaml"
+
+var httpMethods = []string{"get", "post", "put", "delete", "patch", "head", "options"}
+
+const (
+	mimeJSON      = "application/json"
+	mimeForm      = "application/x-www-form-urlencoded"
+	mimeMultipart = "multipart/form-data"
+)
+
+// Generate describes the routes of all services of the IDLs in one document.
+func Generate(title string, idls []*parser.IDL) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: "1.0.0"},
+		Paths:   make(map[string]PathItem),
+	}
+	comps := newComponents()
+	operationIDs := make(map[string]bool)
+	for _, idl := range idls {
+		for _, svc := range idl.Services {
+			tagged := false
+			for _, m := range svc.Methods {
+				method, path, ok := Route(m)
+				if !ok {
+					continue
+				}
+				op := operation(comps, idl, m, method, path)
+				op.Tags = []string{svc.Name}
+				if operationIDs[op.OperationID] {
+					op.OperationID = svc.Name + "_" + op.OperationID
+				}
+				operationIDs[op.OperationID] = true
+
+				if doc.Paths[path] == nil {
+					doc.Paths[path] = make(PathItem)
+				}
+				doc.Paths[path][method] = op
+				if !tagged {
+					doc.Tags = append(doc.Tags, Tag{Name: svc.Name, Description: svc.Comment})
+					tagged = true
+				}
+			}
+		}
+	}
+	doc.Components.Schemas = comps.schemas
+	return doc
+}
+
+// Route returns the lower case HTTP method and the OpenAPI path of the method's api.{method} annotation.
+func Route(m *parser.Method) (string, string, bool) {
+	for _, method := range httpMethods {
+		if path, ok := m.Annotations.Get("api." + method); ok {
+			return method, openAPIPath(path), true
+		}
+	}
+	return "", "", false
+}
+
+// openAPIPath converts the ":name" and "*name" segments of hz routes to "{name}".
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if len(s) > 1 && (s[0] == ':' || s[0] == '*') {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operation(comps *components, idl *parser.IDL, m *parser.Method, method, path string) *Operation {
+	op := &Operation{
+		Summary:     m.Comment,
+		OperationID: m.Name,
+		Responses:   map[string]*Response{"200": response(comps, idl, m.Response)},
+	}
+	if len(m.Args) == 0 {
+		return op
+	}
+
+	// hz handlers bind the request into the single argument of the method
+	reqIDL, fields := idl, m.Args
+	if arg := m.Args[0].Type; len(m.Args) == 1 && arg.Kind == parser.KindStruct {
+		var local string
+		reqIDL, local = idl.Resolve(arg.Name)
+		if st := reqIDL.LookupStruct(local); st != nil {
+			fields = st.Fields
+		}
+	}
+
+	var jsonFields, formFields []*parser.Field
+	multipart := false
+	for _, f := range fields {
+		in, key := Binding(f, method, path)
+		switch in {
+		case "":
+			continue
+		case "body":
+			jsonFields = append(jsonFields, f)
+		case "form":
+			formFields = append(formFields, f)
+			if _, ok := f.Annotations.Get("api.file_name"); ok || f.Type.Name == "binary" {
+				multipart = true
+			}
+		default:
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:        key,
+				In:          in,
+				Description: f.Comment,
+				Required:    f.Required || in == "path",
+				Schema:      comps.typeSchema(reqIDL, f.Type),
+			})
+		}
+	}
+
+	content := make(map[string]*MediaType)
+	if len(jsonFields) > 0 {
+		content[mimeJSON] = &MediaType{Schema: comps.fieldsSchema(reqIDL, jsonFields, "")}
+	}
+	if len(formFields) > 0 {
+		schema := &Schema{Type: "object", Properties: new(Properties)}
+		for _, f := range formFields {
+			key := formName(f)
+			fs := comps.fieldSchema(reqIDL, f)
+			if _, ok := f.Annotations.Get("api.file_name"); ok {
+				fs = &Schema{Type: "string", Format: "binary", Description: f.Comment}
+			}
+			schema.Properties.Set(key, fs)
+			if f.Required {
+				schema.Required = append(schema.Required, key)
+			}
+		}
+		mime := mimeForm
+		if multipart {
+			mime = mimeMultipart
+		}
+		content[mime] = &MediaType{Schema: schema}
+	}
+	if len(content) > 0 {
+		op.RequestBody = &RequestBody{Content: content}
+	}
+	return op
+}
+
+// Binding returns where hz binds the request field of the route from: path, query, header, cookie,
+// form or body, and the key it is bound with. It returns an empty location for the fields of api.none.
+// Fields without an api annotation are bound from the query for methods without a body,
+// and from the JSON body otherwise.
+func Binding(f *parser.Field, method, path string) (string, string) {
+	if _, ok := f.Annotations.Get("api.none"); ok {
+		return "", ""
+	}
+	in, key := binding(f, method == "get" || method == "head" || method == "delete")
+	if in != "path" && strings.Contains(path, "{"+key+"}") {
+		in = "path"
+	}
+	return in, key
+}
+
+func binding(f *parser.Field, noBody bool) (string, string) {
+	for _, in := range []string{"path", "query", "header", "cookie", "form", "file_name", "raw_body", "body"} {
+		key, ok := f.Annotations.Get("api." + in)
+		if !ok {
+			continue
+		}
+		switch in {
+		case "file_name":
+			return "form", formName(f)
+		case "raw_body":
+			return "body", f.Name
+		case "body":
+			return in, JSONName(f)
+		case "form":
+			if noBody {
+				return "query", key
+			}
+		}
+		return in, key
+	}
+	if noBody {
+		return "query", f.Name
+	}
+	return "body", JSONName(f)
+}
+
+func formName(f *parser.Field) string {
+	for _, in := range []string{"form", "file_name"} {
+		if key, ok := f.Annotations.Get("api." + in); ok && key != "" {
+			return key
+		}
+	}
+	return f.Name
+}
+
+// response describes the response struct, the fields bound to headers are described as response headers.
+func response(comps *components, idl *parser.IDL, t *parser.Type) *Response {
+	resp := &Response{Description: "OK"}
+	if t == nil {
+		return resp
+	}
+	if t.Kind != parser.KindStruct {
+		resp.Content = map[string]*MediaType{mimeJSON: {Schema: comps.typeSchema(idl, t)}}
+		return resp
+	}
+
+	owner, local := idl.Resolve(t.Name)
+	st := owner.LookupStruct(local)
+	if st == nil {
+		resp.Content = map[string]*MediaType{mimeJSON: {Schema: &Schema{Type: "object"}}}
+		return resp
+	}
+	var bodyFields []*parser.Field
+	for _, f := range st.Fields {
+		key, ok := f.Annotations.Get("api.header")
+		if !ok {
+			bodyFields = append(bodyFields, f)
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]*Header)
+		}
+		resp.Headers[key] = &Header{Description: f.Comment, Schema: comps.typeSchema(owner, f.Type)}
+	}
+	var schema *Schema
+	if resp.Headers == nil {
+		schema = comps.typeSchema(idl, t)
+	} else {
+		// the body is the struct without the header fields
+		schema = comps.fieldsSchema(owner, bodyFields, st.Comment)
+	}
+	resp.Content = map[string]*MediaType{mimeJSON: {Schema: schema}}
+	return resp
+}
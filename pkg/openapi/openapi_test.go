@@ -0,0 +1,158 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseIDL(t *testing.T, files map[string]string, main string) *parser.IDL {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	idl, err := parser.ParseIDL(filepath.Join(dir, main), nil)
+	assert.NoError(t, err)
+	return idl
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	assert.Equal(t, "/user/{id}", openAPIPath("/user/:id"))
+	assert.Equal(t, "/files/{path}", openAPIPath("/files/*path"))
+	assert.Equal(t, "/ping", openAPIPath("/ping"))
+}
+
+func TestGenerate(t *testing.T) {
+	idl := parseIDL(t, map[string]string{
+		"base.thrift": `
+namespace go base
+struct BaseResp { 1: i32 Code; 2: Extra Extra }
+struct Extra { 1: string K }
+`,
+		"hello.thrift": `
+namespace go hello
+include "base.thrift"
+
+enum Status { OK = 0 }
+
+struct User {
+    1: i64 ID (api.js_conv="true");
+    2: list<User> Friends;
+}
+
+struct GetReq {
+    1: required i64 ID;
+    2: string Lang (api.query="lang");
+    3: string Token (api.header="Authorization");
+}
+
+struct GetResp {
+    1: User User;
+    2: string TraceID (api.header="X-Trace-Id");
+}
+
+struct UploadReq {
+    1: string Name (api.form="name");
+    2: binary File (api.file_name="file");
+}
+
+struct CreateReq {
+    1: required string Name (api.body="name");
+    2: Status Status;
+}
+
+service UserService {
+    // get a user
+    GetResp Get(1: GetReq req) (api.get="/user/:ID");
+    base.BaseResp Upload(1: UploadReq req) (api.post="/upload");
+    User Create(1: CreateReq req) (api.post="/user");
+    User Internal(1: CreateReq req);
+}
+`,
+	}, "hello.thrift")
+
+	doc := Generate("hello", []*parser.IDL{idl})
+	assert.Len(t, doc.Paths, 3)
+
+	get := doc.Paths["/user/{ID}"]["get"]
+	assert.Equal(t, "Get", get.OperationID)
+	assert.Equal(t, "get a user", get.Summary)
+	assert.Equal(t, []string{"UserService"}, get.Tags)
+	assert.Len(t, get.Parameters, 3)
+	assert.Equal(t, Parameter{Name: "ID", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}}, *get.Parameters[0])
+	assert.Equal(t, "query", get.Parameters[1].In)
+	assert.Equal(t, "Authorization", get.Parameters[2].Name)
+	assert.Nil(t, get.RequestBody)
+	resp := get.Responses["200"]
+	assert.Contains(t, resp.Headers, "X-Trace-Id")
+	assert.Equal(t, []string{"User"}, resp.Content[mimeJSON].Schema.Properties.Keys())
+
+	upload := doc.Paths["/upload"]["post"]
+	form := upload.RequestBody.Content[mimeMultipart].Schema
+	assert.Equal(t, []string{"name", "file"}, form.Properties.Keys())
+	assert.Equal(t, "binary", form.Properties.Get("file").Format)
+	assert.Equal(t, componentsPrefix+"BaseResp", upload.Responses["200"].Content[mimeJSON].Schema.Ref)
+
+	create := doc.Paths["/user"]["post"]
+	body := create.RequestBody.Content[mimeJSON].Schema
+	assert.Equal(t, []string{"name", "Status"}, body.Properties.Keys())
+	assert.Equal(t, []string{"name"}, body.Required)
+	assert.Equal(t, componentsPrefix+"Status", body.Properties.Get("Status").Ref)
+
+	schemas := doc.Components.Schemas
+	assert.ElementsMatch(t, []string{"User", "BaseResp", "Extra", "Status"}, keys(schemas))
+	assert.Equal(t, "string", schemas["User"].Properties.Get("ID").Type)
+	assert.Equal(t, componentsPrefix+"User", schemas["User"].Properties.Get("Friends").Items.Ref)
+	assert.Equal(t, []int64{0}, schemas["Status"].Enum)
+	assert.Equal(t, []string{"OK"}, schemas["Status"].EnumNames)
+
+	data, err := doc.Marshal()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "openapi: 3.1.0\n"))
+	// properties keep the order of the IDL fields
+	assert.Less(t, strings.Index(string(data), "        Code:"), strings.Index(string(data), "        Extra:"))
+}
+
+func TestGenerateNameClash(t *testing.T) {
+	a := parseIDL(t, map[string]string{"a.thrift": `
+struct Req { 1: string A }
+service A { Req Get(1: Req req) (api.post="/a") }
+`}, "a.thrift")
+	b := parseIDL(t, map[string]string{"b.thrift": `
+struct Req { 1: string B }
+service B { Req Get(1: Req req) (api.post="/b") }
+`}, "b.thrift")
+
+	doc := Generate("clash", []*parser.IDL{a, b})
+	assert.ElementsMatch(t, []string{"Req", "b.Req"}, keys(doc.Components.Schemas))
+	assert.Equal(t, "Get", doc.Paths["/a"]["post"].OperationID)
+	assert.Equal(t, "B_Get", doc.Paths["/b"]["post"].OperationID)
+}
+
+func keys(m map[string]*Schema) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+// components collects the schemas of the structs and enums referenced by the routes.
+type components struct {
+	schemas map[string]*Schema
+	// component name of each declared type, keyed by the IDL path and the type name
+	names map[string]string
+}
+
+func newComponents() *components {
+	return &components{schemas: make(map[string]*Schema), names: make(map[string]string)}
+}
+
+// ref returns a reference to the component of the struct or enum named in idl, adding it if needed.
+func (c *components) ref(idl *parser.IDL, name string) *Schema {
+	owner, local := idl.Resolve(name)
+	key := owner.Path + "#" + local
+	if n, ok := c.names[key]; ok {
+		return &Schema{Ref: componentsPrefix + n}
+	}
+
+	var (
+		st   *parser.Struct
+		enum *parser.Enum
+	)
+	if st = owner.LookupStruct(local); st == nil {
+		if enum = owner.LookupEnum(local); enum == nil {
+			return &Schema{Type: "object"}
+		}
+	}
+
+	// types of different IDLs may share a name, the later ones are qualified with the file name
+	n := local
+	if _, taken := c.schemas[n]; taken {
+		n = strings.TrimSuffix(filepath.Base(owner.Path), filepath.Ext(owner.Path)) + "." + local
+	}
+	c.names[key] = n
+	// register the name before building the schema, so that recursive types refer to it
+	c.schemas[n] = nil
+	if st != nil {
+		c.schemas[n] = c.fieldsSchema(owner, st.Fields, st.Comment)
+	} else {
+		c.schemas[n] = enumSchema(enum)
+	}
+	return &Schema{Ref: componentsPrefix + n}
+}
+
+// fieldsSchema describes an object whose properties are the given fields, keyed by their JSON names.
+func (c *components) fieldsSchema(idl *parser.IDL, fields []*parser.Field, description string) *Schema {
+	s := &Schema{Type: "object", Description: description, Properties: new(Properties)}
+	for _, f := range fields {
+		if _, ok := f.Annotations.Get("api.none"); ok {
+			continue
+		}
+		key := JSONName(f)
+		s.Properties.Set(key, c.fieldSchema(idl, f))
+		if f.Required {
+			s.Required = append(s.Required, key)
+		}
+	}
+	return s
+}
+
+func (c *components) fieldSchema(idl *parser.IDL, f *parser.Field) *Schema {
+	s := c.typeSchema(idl, f.Type)
+	if v, ok := f.Annotations.Get("api.js_conv"); ok && v != "false" {
+		// integers are encoded as strings for javascript
+		s = &Schema{Type: "string"}
+	}
+	// siblings of $ref are allowed since OpenAPI 3.1
+	s.Description = f.Comment
+	if f.Default != "" && f.Type.Kind == parser.KindBase {
+		s.Default = strings.Trim(f.Default, `"'`)
+	}
+	return s
+}
+
+func (c *components) typeSchema(idl *parser.IDL, t *parser.Type) *Schema {
+	switch t.Kind {
+	case parser.KindList, parser.KindSet:
+		return &Schema{Type: "array", Items: c.typeSchema(idl, t.ValueType), UniqueItems: t.Kind == parser.KindSet}
+	case parser.KindMap:
+		return &Schema{Type: "object", AdditionalProperties: c.typeSchema(idl, t.ValueType)}
+	case parser.KindStruct, parser.KindEnum:
+		return c.ref(idl, t.Name)
+	}
+
+	switch t.Name {
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "byte", "i8", "i16", "i32", "u32":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "i64", "u64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float":
+		return &Schema{Type: "number", Format: "float"}
+	case "double":
+		return &Schema{Type: "number", Format: "double"}
+	case "binary":
+		return &Schema{Type: "string", ContentEncoding: "base64"}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+func enumSchema(e *parser.Enum) *Schema {
+	s := &Schema{Type: "integer", Format: "int32", Description: e.Comment}
+	for _, v := range e.Values {
+		s.Enum = append(s.Enum, v.Value)
+		s.EnumNames = append(s.EnumNames, v.Name)
+		line := fmt.Sprintf("%d: %s", v.Value, v.Name)
+		if s.Description == "" {
+			s.Description = line
+		} else {
+			s.Description += "\n" + line
+		}
+	}
+	return s
+}
+
+// JSONName is the key of the field in JSON bodies, hz sets the json tag from api.body.
+func JSONName(f *parser.Field) string {
+	if key, ok := f.Annotations.Get("api.body"); ok && key != "" {
+		return key
+	}
+	return f.Name
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/openapi"
+	"github.com/cloudwego/kitex/tool/internal_pkg/util"
+)
+
+func pyType(s *openapi.Schema) string {
+	if s.Ref != "" {
+		// forward references, the types may be declared after their use
+		return strconv.Quote(refName(s))
+	}
+	switch s.Type {
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "string":
+		if s.Format == "binary" {
+			// a file object or bytes, as accepted by requests
+			return "Any"
+		}
+		return "str"
+	case "array":
+		return "List[" + pyType(s.Items) + "]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Dict[str, " + pyType(s.AdditionalProperties) + "]"
+		}
+		return "Dict[str, Any]"
+	}
+	return "Any"
+}
+
+// pyResponse returns the type of the response, the types are all declared before the clients.
+func pyResponse(s *openapi.Schema) string {
+	if s == nil {
+		return "None"
+	}
+	if s.Ref != "" {
+		return refName(s)
+	}
+	return pyType(s)
+}
+
+var pyKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "break": true,
+	"class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true,
+	"or": true, "pass": true, "raise": true, "return": true, "try": true, "while": true,
+	"with": true, "yield": true,
+}
+
+func pyMethod(name string) string {
+	name = util.SnakeString(name)
+	if pyKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// pyDoc returns the comment as a docstring followed by a new line, or nothing if it is empty.
+func pyDoc(comment, indent string) string {
+	if comment == "" {
+		return ""
+	}
+	comment = strings.ReplaceAll(comment, `\`, `\\`)
+	comment = strings.ReplaceAll(comment, `"""`, `\"\"\"`)
+	lines := strings.Split(comment, "\n")
+	if len(lines) == 1 {
+		return indent + `"""` + comment + `"""` + "\n"
+	}
+	var b strings.Builder
+	b.WriteString(indent + `"""` + lines[0] + "\n")
+	for _, l := range lines[1:] {
+		b.WriteString(strings.TrimRight(indent+l, " ") + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+	return b.String()
+}
+
+// pyComment returns the comment as # lines followed by a new line, or nothing if it is empty.
+func pyComment(comment, indent string) string {
+	if comment == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, l := range strings.Split(comment, "\n") {
+		b.WriteString(strings.TrimRight(indent+"# "+l, " ") + "\n")
+	}
+	return b.String()
+}
+
+// pyAccess reads the field from the request, only required fields are expected to be set.
+func pyAccess(f *fieldData) string {
+	if f.Required {
+		return "req[" + strconv.Quote(f.Key) + "]"
+	}
+	return "req.get(" + strconv.Quote(f.Key) + ")"
+}
+
+// pyPath returns an expression building the path of the method from the request.
+func pyPath(m *methodData) string {
+	literals, params := pathSegments(m.Path)
+	fields := make(map[string]*fieldData, len(m.PathParams))
+	for _, f := range m.PathParams {
+		fields[f.Key] = f
+	}
+	var parts []string
+	for i, l := range literals {
+		if l != "" {
+			parts = append(parts, strconv.Quote(l))
+		}
+		if i < len(params) {
+			f := fields[params[i]]
+			if f == nil {
+				f = &fieldData{Key: params[i], Required: true}
+			}
+			parts = append(parts, "quote(str("+pyAccess(f)+`), safe="")`)
+		}
+	}
+	if len(parts) == 0 {
+		return `""`
+	}
+	return strings.Join(parts, " + ")
+}
+
+// pyParams returns the (key, value) pairs of the fields, read from the request.
+func pyParams(fields []*fieldData) string {
+	pairs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		pairs = append(pairs, "("+strconv.Quote(f.Key)+", "+pyAccess(f)+")")
+	}
+	return "[" + strings.Join(pairs, ", ") + "]"
+}
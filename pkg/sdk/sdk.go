@@ -0,0 +1,342 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sdk generates HTTP clients in other languages than Go for the routes
+// of hertz services. The clients are derived from the OpenAPI description of
+// the IDL, so that they bind the request fields the same way as the server.
+package sdk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/openapi"
+)
+
+const refPrefix = "#/components/schemas/"
+
+// language renders the client of one language, its template uses the functions of the language
+// to write the types and the expressions reading the request.
+type language struct {
+	ext   string
+	tpl   string
+	funcs template.FuncMap
+}
+
+var languages = map[string]language{
+	consts.LangTS: {ext: ".ts", tpl: tsTpl, funcs: template.FuncMap{
+		"type":     tsType,
+		"response": tsResponse,
+		"key":      tsKey,
+		"doc":      tsDoc,
+		"method":   lowerFirst,
+		"path":     tsPath,
+		"params":   tsParams,
+	}},
+	consts.LangPython: {ext: ".py", tpl: pyTpl, funcs: template.FuncMap{
+		"type":     pyType,
+		"response": pyResponse,
+		"doc":      pyDoc,
+		"comment":  pyComment,
+		"method":   pyMethod,
+		"path":     pyPath,
+		"params":   pyParams,
+		"quote":    strconv.Quote,
+		"chomp":    func(s string) string { return strings.TrimSuffix(s, "\n") },
+	}},
+}
+
+type sdkData struct {
+	ServerName string
+	Types      []*typeData
+	Services   []*serviceData
+}
+
+// typeData is a struct or an enum of the IDL, or the request or response of a method.
+type typeData struct {
+	Name    string
+	Comment string
+	Enum    bool
+	Values  []enumValue
+	Fields  []*fieldData
+}
+
+type enumValue struct {
+	Name  string
+	Value int64
+}
+
+type fieldData struct {
+	Key      string // key of the field on the wire
+	Schema   *openapi.Schema
+	Required bool
+	Comment  string
+}
+
+type serviceData struct {
+	Name    string
+	Comment string
+	Methods []*methodData
+}
+
+type methodData struct {
+	Name       string // method name in the IDL
+	Comment    string
+	HTTPMethod string
+	Path       string // OpenAPI path, parameters are written as {key}
+	Request    string // type whose fields are the parameters and the body of the request
+
+	PathParams []*fieldData
+	Query      []*fieldData
+	Headers    []*fieldData
+	Cookies    []*fieldData
+	Body       string // json, form, multipart or empty
+	BodyFields []*fieldData
+
+	Response *openapi.Schema // nil if the method returns nothing
+}
+
+// RequestOptional reports whether the request may be omitted, when none of its fields is required.
+func (m *methodData) RequestOptional() bool {
+	for _, fields := range [][]*fieldData{m.PathParams, m.Query, m.Headers, m.Cookies, m.BodyFields} {
+		for _, f := range fields {
+			if f.Required {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Generate writes the client of the HTTP routes of the IDLs in the language of c.Lang.
+func Generate(c *config.ClientArgument) error {
+	lang, ok := languages[c.Lang]
+	if !ok {
+		return fmt.Errorf("unsupported client language '%s'", c.Lang)
+	}
+
+	paths, err := utils.ExpandIDLPaths(c.IdlPath)
+	if err != nil {
+		return err
+	}
+	idls := make([]*parser.IDL, 0, len(paths))
+	for _, p := range paths {
+		idl, err := parser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return err
+		}
+		idls = append(idls, idl)
+	}
+
+	data := buildSdkData(c.ServerName, idls)
+	if len(data.Services) == 0 {
+		return fmt.Errorf("no HTTP route found in idl '%s', declare them with api.{method} annotations", c.IdlPath)
+	}
+
+	tmpl, err := template.New(c.Lang).Funcs(lang.funcs).Parse(lang.tpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(c.OutDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.OutDir, identifier(c.ServerName)+"_client"+lang.ext), buf.Bytes(), 0o644)
+}
+
+func buildSdkData(serverName string, idls []*parser.IDL) *sdkData {
+	doc := openapi.Generate(serverName, idls)
+	data := &sdkData{ServerName: serverName}
+
+	taken := make(map[string]bool)
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := schemaType(identifier(name), doc.Components.Schemas[name])
+		taken[t.Name] = true
+		data.Types = append(data.Types, t)
+	}
+	// the types of the methods use names which the IDL types do not
+	typeName := func(name, suffix string) string {
+		n := identifier(name) + suffix
+		for taken[n] {
+			n += "_"
+		}
+		taken[n] = true
+		return n
+	}
+
+	for _, idl := range idls {
+		for _, svc := range idl.Services {
+			s := &serviceData{Name: svc.Name, Comment: svc.Comment}
+			for _, m := range svc.Methods {
+				method, path, ok := openapi.Route(m)
+				if !ok {
+					continue
+				}
+				op := doc.Paths[path][method]
+				md := &methodData{
+					Name:       m.Name,
+					Comment:    m.Comment,
+					HTTPMethod: strings.ToUpper(method),
+					Path:       path,
+				}
+
+				req := &typeData{Name: typeName(op.OperationID, "Request")}
+				for _, p := range op.Parameters {
+					f := &fieldData{Key: p.Name, Schema: p.Schema, Required: p.Required, Comment: p.Description}
+					switch p.In {
+					case "path":
+						md.PathParams = append(md.PathParams, f)
+					case "query":
+						md.Query = append(md.Query, f)
+					case "header":
+						md.Headers = append(md.Headers, f)
+					case "cookie":
+						md.Cookies = append(md.Cookies, f)
+					}
+					req.Fields = append(req.Fields, f)
+				}
+				if op.RequestBody != nil {
+					// hz binds either a JSON or a form body, the JSON one is preferred when the IDL declares both
+					for _, body := range []struct{ kind, mime string }{
+						{"json", "application/json"},
+						{"multipart", "multipart/form-data"},
+						{"form", "application/x-www-form-urlencoded"},
+					} {
+						if mt, ok := op.RequestBody.Content[body.mime]; ok {
+							md.Body = body.kind
+							md.BodyFields = schemaType("", mt.Schema).Fields
+							break
+						}
+					}
+					req.Fields = append(req.Fields, md.BodyFields...)
+				}
+				md.Request = req.Name
+				data.Types = append(data.Types, req)
+
+				if content := op.Responses["200"].Content["application/json"]; content != nil {
+					md.Response = content.Schema
+					if content.Schema.Ref == "" && content.Schema.Properties != nil {
+						resp := schemaType(typeName(op.OperationID, "Response"), content.Schema)
+						data.Types = append(data.Types, resp)
+						md.Response = &openapi.Schema{Ref: refPrefix + resp.Name}
+					}
+				}
+				s.Methods = append(s.Methods, md)
+			}
+			if len(s.Methods) > 0 {
+				data.Services = append(data.Services, s)
+			}
+		}
+	}
+	return data
+}
+
+// schemaType converts the schema of an object or an enum to a type.
+func schemaType(name string, s *openapi.Schema) *typeData {
+	t := &typeData{Name: name, Comment: s.Description}
+	if len(s.Enum) > 0 {
+		t.Enum = true
+		// the description of enums lists their values, which the values of the type already show
+		t.Comment = ""
+		for i, v := range s.Enum {
+			ev := enumValue{Name: fmt.Sprintf("V%d", v), Value: v}
+			if i < len(s.EnumNames) {
+				ev.Name = s.EnumNames[i]
+			}
+			t.Values = append(t.Values, ev)
+		}
+		return t
+	}
+	if s.Properties == nil {
+		return t
+	}
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	for _, key := range s.Properties.Keys() {
+		ps := s.Properties.Get(key)
+		t.Fields = append(t.Fields, &fieldData{Key: key, Schema: ps, Required: required[key], Comment: ps.Description})
+	}
+	return t
+}
+
+// refName returns the type name of a schema reference.
+func refName(s *openapi.Schema) string {
+	return identifier(strings.TrimPrefix(s.Ref, refPrefix))
+}
+
+// identifier replaces the characters which can not be used in identifiers with underscores.
+func identifier(name string) string {
+	b := []rune(name)
+	for i, r := range b {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 0 && unicode.IsDigit(b[0]) {
+		return "_" + string(b)
+	}
+	return string(b)
+}
+
+// isIdentifier reports whether the key can be used as an identifier in TypeScript and Python.
+func isIdentifier(key string) bool {
+	return key != "" && identifier(key) == key
+}
+
+// lowerFirst returns the name with its first letter in lower case, e.g. getUser for GetUser.
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// pathSegments splits an OpenAPI path into the literal parts and the parameters between them.
+func pathSegments(path string) (literals, params []string) {
+	for {
+		start := strings.IndexByte(path, '{')
+		end := strings.IndexByte(path, '}')
+		if start < 0 || end < start {
+			return append(literals, path), params
+		}
+		literals = append(literals, path[:start])
+		params = append(params, path[start+1:end])
+		path = path[end+1:]
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+const helloIDL = `
+namespace go hello
+
+enum Status { OK = 0 }
+
+// user info
+struct User {
+    1: i64 ID;
+    2: list<User> Friends;
+}
+
+struct GetReq {
+    1: required i64 ID (api.path="id");
+    2: string Lang (api.query="lang");
+    3: string Token (api.header="X-Token");
+}
+
+struct GetResp {
+    1: User User;
+    2: string TraceID (api.header="X-Trace-Id");
+}
+
+struct CreateReq {
+    1: required string Name (api.body="name");
+    2: Status Status;
+}
+
+service UserService {
+    // get a user
+    GetResp Get(1: GetReq req) (api.get="/user/:id");
+    User Import(1: CreateReq req) (api.post="/user");
+    void Ping() (api.head="/ping");
+    User Internal(1: CreateReq req);
+}
+`
+
+func buildHello(t *testing.T) *sdkData {
+	path := filepath.Join(t.TempDir(), "hello.thrift")
+	assert.NoError(t, os.WriteFile(path, []byte(helloIDL), 0o644))
+	idl, err := parser.ParseIDL(path, nil)
+	assert.NoError(t, err)
+	return buildSdkData("hello", []*parser.IDL{idl})
+}
+
+func render(t *testing.T, lang string, data *sdkData) string {
+	l := languages[lang]
+	tmpl, err := template.New(lang).Funcs(l.funcs).Parse(l.tpl)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, tmpl.Execute(&buf, data))
+	return buf.String()
+}
+
+func TestBuildSdkData(t *testing.T) {
+	data := buildHello(t)
+	assert.Len(t, data.Services, 1)
+	methods := data.Services[0].Methods
+	assert.Len(t, methods, 3)
+
+	get := methods[0]
+	assert.Equal(t, "GET", get.HTTPMethod)
+	assert.Equal(t, "/user/{id}", get.Path)
+	assert.Equal(t, "GetRequest", get.Request)
+	assert.Equal(t, "id", get.PathParams[0].Key)
+	assert.Equal(t, "lang", get.Query[0].Key)
+	assert.Equal(t, "X-Token", get.Headers[0].Key)
+	assert.False(t, get.RequestOptional())
+	// the response without its header fields
+	assert.Equal(t, refPrefix+"GetResponse", get.Response.Ref)
+
+	imp := methods[1]
+	assert.Equal(t, "json", imp.Body)
+	assert.Equal(t, "name", imp.BodyFields[0].Key)
+	assert.Equal(t, refPrefix+"User", imp.Response.Ref)
+
+	ping := methods[2]
+	assert.Nil(t, ping.Response)
+	assert.True(t, ping.RequestOptional())
+
+	var names []string
+	for _, typ := range data.Types {
+		names = append(names, typ.Name)
+	}
+	assert.Equal(t, []string{"Status", "User", "GetRequest", "GetResponse", "ImportRequest", "PingRequest"}, names)
+	assert.Equal(t, enumValue{Name: "OK", Value: 0}, data.Types[0].Values[0])
+}
+
+func TestRenderTS(t *testing.T) {
+	out := render(t, consts.LangTS, buildHello(t))
+	assert.Contains(t, out, "export enum Status {\n  OK = 0,\n}")
+	assert.Contains(t, out, "/** user info */\nexport interface User {\n  ID?: number;\n  Friends?: User[];\n}")
+	assert.Contains(t, out, "  \"X-Token\"?: string;")
+	assert.Contains(t, out, "export class UserServiceClient extends BaseClient {")
+	assert.Contains(t, out, "  /** get a user */\n  get(req: GetRequest): Promise<GetResponse> {")
+	assert.Contains(t, out, "`/user/${encodeURIComponent(String(req.id))}`, [[\"lang\", req.lang]], [[\"X-Token\", req[\"X-Token\"]]], []);")
+	assert.Contains(t, out, "{ kind: \"json\", fields: [[\"name\", req.name], [\"Status\", req.Status]] }")
+	assert.Contains(t, out, "ping(req: PingRequest = {}): Promise<void> {")
+}
+
+func TestRenderPython(t *testing.T) {
+	out := render(t, consts.LangPython, buildHello(t))
+	assert.Contains(t, out, "class Status(IntEnum):\n    OK = 0\n")
+	assert.Contains(t, out, "# user info\nUser = TypedDict(\n    \"User\",\n    {\n        \"ID\": int,\n        \"Friends\": List[\"User\"],\n    },\n    total=False,\n)")
+	assert.Contains(t, out, "class UserServiceClient(_Client):\n    \"\"\"Client of UserService.\"\"\"\n\n    def get(self, req: GetRequest) -> GetResponse:\n        \"\"\"get a user\"\"\"\n")
+	assert.Contains(t, out, "\"/user/\" + quote(str(req[\"id\"]), safe=\"\"),")
+	assert.Contains(t, out, "def import_(self, req: ImportRequest) -> User:")
+	assert.Contains(t, out, "def ping(self, req: Optional[PingRequest] = None) -> None:\n        req = req or {}\n")
+}
+
+func TestPath(t *testing.T) {
+	m := &methodData{Path: "/files/{dir}/{name}.txt", PathParams: []*fieldData{{Key: "dir", Required: true}, {Key: "name", Required: true}}}
+	assert.Equal(t, "`/files/${encodeURIComponent(String(req.dir))}/${encodeURIComponent(String(req.name))}.txt`", tsPath(m))
+	assert.Equal(t, `"/files/" + quote(str(req["dir"]), safe="") + "/" + quote(str(req["name"]), safe="") + ".txt"`, pyPath(m))
+	assert.Equal(t, `"/"`, pyPath(&methodData{Path: "/"}))
+}
@@ -0,0 +1,313 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+var tsTpl = `// Code generated by cwgo client. DO NOT EDIT.
+// HTTP client of {{.ServerName}}.
+{{range .Types}}
+{{doc .Comment ""}}
+{{- if .Enum}}export enum {{.Name}} {
+{{- range .Values}}
+  {{.Name}} = {{.Value}},
+{{- end}}
+}
+{{else}}export interface {{.Name}} {
+{{- range .Fields}}
+{{doc .Comment "  "}}  {{key .Key}}{{if not .Required}}?{{end}}: {{type .Schema}};
+{{- end}}
+}
+{{end}}
+{{- end}}
+export interface HttpRequest {
+  method: string;
+  url: string;
+  headers: Record<string, string>;
+  body?: string | URLSearchParams | FormData;
+}
+
+export interface HttpResponse {
+  status: number;
+  headers: Record<string, string>;
+  body: string;
+}
+
+/**
+ * Transport sends the requests of the clients. Wrap it to add authentication,
+ * retries or logging, or replace it to send the requests with another library.
+ */
+export type Transport = (req: HttpRequest) => Promise<HttpResponse>;
+
+/** fetchTransport sends the requests with the global fetch. */
+export const fetchTransport: Transport = async (req) => {
+  const resp = await fetch(req.url, { method: req.method, headers: req.headers, body: req.body });
+  const headers: Record<string, string> = {};
+  resp.headers.forEach((value, key) => {
+    headers[key] = value;
+  });
+  return { status: resp.status, headers, body: await resp.text() };
+};
+
+/** HttpError is thrown for the responses whose status is not 2xx. */
+export class HttpError extends Error {
+  readonly response: HttpResponse;
+
+  constructor(response: HttpResponse) {
+    super(` + "`HTTP ${response.status}: ${response.body}`" + `);
+    this.name = "HttpError";
+    this.response = response;
+  }
+}
+
+export interface ClientOptions {
+  /** transport sends the requests, default is fetchTransport. */
+  transport?: Transport;
+  /** headers are added to every request. */
+  headers?: Record<string, string>;
+}
+
+type Param = [string, unknown];
+
+interface Body {
+  kind: "json" | "form" | "multipart";
+  fields: Param[];
+}
+
+function present(params: Param[]): Param[] {
+  return params.filter(([, value]) => value !== undefined && value !== null);
+}
+
+function appendParams(target: URLSearchParams | FormData, params: Param[]): void {
+  for (const [key, value] of present(params)) {
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (target instanceof FormData) {
+        target.append(key, v instanceof Blob ? v : String(v));
+      } else {
+        target.append(key, String(v));
+      }
+    }
+  }
+}
+
+class BaseClient {
+  private readonly baseURL: string;
+  private readonly transport: Transport;
+  private readonly headers: Record<string, string>;
+
+  constructor(baseURL: string, options: ClientOptions = {}) {
+    this.baseURL = baseURL.replace(/\/+$/, "");
+    this.transport = options.transport ?? fetchTransport;
+    this.headers = options.headers ?? {};
+  }
+
+  protected async call<T>(method: string, path: string, query: Param[], headers: Param[], cookies: Param[], body?: Body): Promise<T> {
+    const req: HttpRequest = { method, url: this.baseURL + path, headers: { ...this.headers } };
+    const search = new URLSearchParams();
+    appendParams(search, query);
+    if (search.toString()) {
+      req.url += "?" + search.toString();
+    }
+    for (const [key, value] of present(headers)) {
+      req.headers[key] = String(value);
+    }
+    if (present(cookies).length > 0) {
+      req.headers["Cookie"] = present(cookies)
+        .map(([key, value]) => key + "=" + encodeURIComponent(String(value)))
+        .join("; ");
+    }
+    if (body?.kind === "json") {
+      req.headers["Content-Type"] = "application/json";
+      req.body = JSON.stringify(Object.fromEntries(present(body.fields)));
+    } else if (body) {
+      const form = body.kind === "multipart" ? new FormData() : new URLSearchParams();
+      appendParams(form, body.fields);
+      req.body = form;
+    }
+
+    const resp = await this.transport(req);
+    if (resp.status < 200 || resp.status >= 300) {
+      throw new HttpError(resp);
+    }
+    return (resp.body ? JSON.parse(resp.body) : undefined) as T;
+  }
+}
+{{range .Services}}
+{{doc .Comment ""}}export class {{.Name}}Client extends BaseClient {
+{{- range $i, $m := .Methods}}
+{{- if $i}}
+{{end}}
+{{doc .Comment "  "}}  {{method .Name}}(req: {{.Request}}{{if .RequestOptional}} = {}{{end}}): Promise<{{response .Response}}> {
+    return this.call<{{response .Response}}>("{{.HTTPMethod}}", {{path .}}, {{params .Query}}, {{params .Headers}}, {{params .Cookies}}
+    {{- if .Body}}, { kind: "{{.Body}}", fields: {{params .BodyFields}} }{{end}});
+  }
+{{- end}}
+}
+{{end -}}
+`
+
+var pyTpl = `# Code generated by cwgo client. DO NOT EDIT.
+"""HTTP client of {{.ServerName}}."""
+
+import json
+from dataclasses import dataclass, field
+from enum import IntEnum
+from typing import Any, Callable, Dict, List, Optional, Tuple, TypedDict
+from urllib.parse import quote
+{{range .Types}}{{if .Enum}}
+
+class {{.Name}}(IntEnum):
+{{- range .Values}}
+    {{.Name}} = {{.Value}}
+{{- end}}
+{{end}}{{end}}
+{{- range .Types}}{{if not .Enum}}
+
+{{comment .Comment ""}}{{.Name}} = TypedDict(
+    {{quote .Name}},
+    {
+{{- range .Fields}}
+{{comment .Comment "        "}}        {{quote .Key}}: {{type .Schema}},
+{{- end}}
+    },
+    total=False,
+)
+{{end}}{{end}}
+
+@dataclass
+class HttpRequest:
+    method: str
+    url: str
+    params: List[Tuple[str, str]] = field(default_factory=list)
+    headers: Dict[str, str] = field(default_factory=dict)
+    cookies: Dict[str, str] = field(default_factory=dict)
+    json: Any = None
+    data: Optional[Dict[str, Any]] = None
+    files: Optional[Dict[str, Any]] = None
+
+
+@dataclass
+class HttpResponse:
+    status: int
+    headers: Dict[str, str]
+    body: bytes
+
+
+# Transport sends the requests of the clients. Wrap it to add authentication,
+# retries or logging, or replace it to send the requests with another library.
+Transport = Callable[[HttpRequest], HttpResponse]
+
+
+def requests_transport(session: Any = None, timeout: Optional[float] = None) -> Transport:
+    """Returns a transport sending the requests with a requests session."""
+    import requests
+
+    s = session if session is not None else requests.Session()
+
+    def send(req: HttpRequest) -> HttpResponse:
+        resp = s.request(
+            req.method,
+            req.url,
+            params=req.params,
+            headers=req.headers,
+            cookies=req.cookies,
+            json=req.json,
+            data=req.data,
+            files=req.files,
+            timeout=timeout,
+        )
+        return HttpResponse(resp.status_code, dict(resp.headers), resp.content)
+
+    return send
+
+
+class HttpError(Exception):
+    """Raised for the responses whose status is not 2xx."""
+
+    def __init__(self, response: HttpResponse):
+        super().__init__("HTTP %d: %s" % (response.status, response.body.decode("utf-8", "replace")))
+        self.response = response
+
+
+def _str(value: Any) -> str:
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    return str(value)
+
+
+def _present(params: List[Tuple[str, Any]]) -> List[Tuple[str, Any]]:
+    return [(key, value) for key, value in params if value is not None]
+
+
+class _Client:
+    def __init__(self, base_url: str, transport: Optional[Transport] = None, headers: Optional[Dict[str, str]] = None):
+        self._base_url = base_url.rstrip("/")
+        self._transport = transport if transport is not None else requests_transport()
+        self._headers = dict(headers or {})
+
+    def _call(
+        self,
+        method: str,
+        path: str,
+        query: List[Tuple[str, Any]],
+        headers: List[Tuple[str, Any]],
+        cookies: List[Tuple[str, Any]],
+        body_kind: Optional[str] = None,
+        body: Optional[List[Tuple[str, Any]]] = None,
+    ) -> Any:
+        req = HttpRequest(method, self._base_url + path, headers=dict(self._headers))
+        for key, value in _present(query):
+            for v in value if isinstance(value, (list, tuple, set)) else [value]:
+                req.params.append((key, _str(v)))
+        for key, value in _present(headers):
+            req.headers[key] = _str(value)
+        for key, value in _present(cookies):
+            req.cookies[key] = _str(value)
+        fields = dict(_present(body or []))
+        if body_kind == "json":
+            req.json = fields
+        elif body_kind == "form":
+            req.data = fields
+        elif body_kind == "multipart":
+            req.files = {k: v for k, v in fields.items() if isinstance(v, bytes) or hasattr(v, "read")}
+            req.data = {k: v for k, v in fields.items() if k not in req.files}
+
+        resp = self._transport(req)
+        if not 200 <= resp.status < 300:
+            raise HttpError(resp)
+        return json.loads(resp.body) if resp.body else None
+{{range .Services}}
+
+class {{.Name}}Client(_Client):
+{{chomp (doc (or .Comment (printf "Client of %s." .Name)) "    ")}}
+{{- range .Methods}}
+
+    def {{method .Name}}(self, req: {{if .RequestOptional}}Optional[{{.Request}}] = None{{else}}{{.Request}}{{end}}) -> {{response .Response}}:
+{{doc .Comment "        "}}
+{{- if .RequestOptional}}        req = req or {}
+{{end}}        return self._call(
+            {{quote .HTTPMethod}},
+            {{path .}},
+            {{params .Query}},
+            {{params .Headers}},
+            {{params .Cookies}},
+{{- if .Body}}
+            {{quote .Body}},
+            {{params .BodyFields}},
+{{- end}}
+        )
+{{- end}}
+{{end -}}
+`
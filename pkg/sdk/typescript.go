@@ -0,0 +1,114 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/openapi"
+)
+
+func tsType(s *openapi.Schema) string {
+	if s.Ref != "" {
+		return refName(s)
+	}
+	switch s.Type {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "string":
+		if s.Format == "binary" {
+			return "Blob"
+		}
+		return "string"
+	case "array":
+		return tsType(s.Items) + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + tsType(s.AdditionalProperties) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+func tsResponse(s *openapi.Schema) string {
+	if s == nil {
+		return "void"
+	}
+	return tsType(s)
+}
+
+func tsKey(key string) string {
+	if isIdentifier(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func tsAccess(key string) string {
+	if isIdentifier(key) {
+		return "req." + key
+	}
+	return "req[" + strconv.Quote(key) + "]"
+}
+
+// tsDoc returns the comment as a JSDoc block followed by a new line, or nothing if it is empty.
+func tsDoc(comment, indent string) string {
+	if comment == "" {
+		return ""
+	}
+	comment = strings.ReplaceAll(comment, "*/", "* /")
+	lines := strings.Split(comment, "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + comment + " */\n"
+	}
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(indent+" * "+l, " ") + "\n")
+	}
+	b.WriteString(indent + " */\n")
+	return b.String()
+}
+
+// tsPath returns a template literal building the path of the method from the request.
+func tsPath(m *methodData) string {
+	literals, params := pathSegments(m.Path)
+	var b strings.Builder
+	b.WriteString("`")
+	for i, l := range literals {
+		l = strings.ReplaceAll(l, "`", "\\`")
+		b.WriteString(strings.ReplaceAll(l, "${", "\\${"))
+		if i < len(params) {
+			b.WriteString("${encodeURIComponent(String(" + tsAccess(params[i]) + "))}")
+		}
+	}
+	b.WriteString("`")
+	return b.String()
+}
+
+// tsParams returns the [key, value] pairs of the fields, read from the request.
+func tsParams(fields []*fieldData) string {
+	pairs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		pairs = append(pairs, "["+strconv.Quote(f.Key)+", "+tsAccess(f.Key)+"]")
+	}
+	return "[" + strings.Join(pairs, ", ") + "]"
+}
@@ -45,6 +45,37 @@ func check(sa *config.ServerArgument) error {
 		return errors.New("must specify server name")
 	}
 
+	if sa.AgentMemory != "" && sa.AgentMemory != "memory" && sa.AgentMemory != "redis" {
+		return errors.New("unsupported agent memory store")
+	}
+
+	if sa.Prune != "" && sa.Prune != consts.PruneMove && sa.Prune != consts.PruneDelete {
+		return fmt.Errorf("unsupported prune mode '%s', supported: %s, %s", sa.Prune, consts.PruneMove, consts.PruneDelete)
+	}
+
+	if sa.Gateway {
+		if sa.Type != consts.RPC {
+			return fmt.Errorf("--%s is only supported by RPC servers", consts.Gateway)
+		}
+		// the gateway is served on the kitex port by the hertz engine of hex
+		sa.Hex = true
+	}
+
+	if sa.Transport != "" && sa.Type != consts.RPC {
+		return fmt.Errorf("--%s is only supported by RPC servers", consts.Transport)
+	}
+	if err := utils.CheckTransport(sa.Transport, sa.IdlPath, sa.TLS); err != nil {
+		return err
+	}
+	// the TLS sections are rendered in the builtin templates only
+	if sa.TLS && sa.Template != "" && !(sa.Template == consts.StandardV2 && sa.Type == consts.HTTP) {
+		return fmt.Errorf("--%s is only supported by the builtin templates", consts.TLS)
+	}
+
+	if err := checkDal(sa); err != nil {
+		return err
+	}
+
 	// handle cwd and output dir
 	dir, err := os.Getwd()
 	if err != nil {
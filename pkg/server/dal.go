@@ -0,0 +1,144 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+)
+
+// dalComponents are the components --dal accepts, in the order of the templates.
+var dalComponents = []string{
+	consts.DalMySQL,
+	consts.DalPostgres,
+	consts.DalRedis,
+	consts.DalMongoDB,
+	consts.DalElasticsearch,
+	consts.DalKafka,
+}
+
+// defaultDal is generated when --dal is not given.
+var defaultDal = []string{consts.DalMySQL, consts.DalRedis}
+
+// checkDal normalizes --dal, "none" disables biz/dal.
+func checkDal(sa *config.ServerArgument) error {
+	if sa.Dal == nil {
+		sa.Dal = defaultDal
+	}
+	selected := make(map[string]bool)
+	for _, d := range sa.Dal {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		if d == consts.DalNone {
+			if len(sa.Dal) > 1 {
+				return fmt.Errorf("--dal %s can't be combined with other components", consts.DalNone)
+			}
+			selected = nil
+			break
+		}
+		if !contains(dalComponents, d) {
+			return fmt.Errorf("unsupported dal component '%s', supported: %s, %s", d, strings.Join(dalComponents, ", "), consts.DalNone)
+		}
+		selected[d] = true
+	}
+	sa.Dal = sa.Dal[:0:0]
+	for _, d := range dalComponents {
+		if selected[d] {
+			sa.Dal = append(sa.Dal, d)
+		}
+	}
+	if sa.AgentMemory == consts.DalRedis && !sa.HasDal(consts.DalRedis) {
+		return errors.New("--agent-memory redis needs redis in --dal")
+	}
+	return nil
+}
+
+// renderDal renders the [[ ]] actions of the templates in dir with the server options,
+// e.g. [[if HasDal "mysql"]]...[[end]], [[if .Dal]] for any component or [[if .TLS]].
+// dir is shared by the cwgo processes, so the templates are rendered into a copy for this
+// run, which is returned and removed by the caller once generated.
+func renderDal(sa *config.ServerArgument, dir string) (string, error) {
+	tmp, err := os.MkdirTemp("", "cwgo-templates-")
+	if err != nil {
+		return "", err
+	}
+	if err = copyTemplates(dir, tmp); err == nil {
+		err = utils.RenderTemplates(tmp, sa, template.FuncMap{"HasDal": sa.HasDal})
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// copyTemplates copies the regular files of the template dir src to dst.
+func copyTemplates(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+}
+
+// isBuiltinKitexTemplate reports whether the kitex templates are the ones of cwgo.
+func isBuiltinKitexTemplate(dir string) bool {
+	return dir == path.Join(tpl.KitexDir, consts.Server, consts.Standard)
+}
+
+// isBuiltinLayout reports whether the hertz layout is one of cwgo, user templates are used as is.
+func isBuiltinLayout(layout string) bool {
+	return layout == path.Join(tpl.HertzDir, consts.Server, consts.Standard, consts.LayoutFile) ||
+		layout == path.Join(tpl.HertzDir, consts.Server, consts.StandardV2, consts.LayoutFile)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRenderDal renders the shared template dir for two runs with different --dal, each into its
+// own copy.
+func TestRenderDal(t *testing.T) {
+	dir := t.TempDir()
+	templates := map[string]string{
+		"main.yaml":  `body: "[[if HasDal "mysql"]]mysql[[else]]none[[end]]"` + "\n",
+		"mysql.yaml": `[[if HasDal "mysql"]]body: mysql[[end]]` + "\n",
+		"conf.yaml":  "body: {{.Module}}\n",
+	}
+	for name, content := range templates {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	render := func(dal ...string) string {
+		sa := config.NewServerArgument()
+		sa.Dal = dal
+		assert.NoError(t, checkDal(sa))
+		tmp, err := renderDal(sa, dir)
+		assert.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tmp) })
+		return tmp
+	}
+	withMySQL := render(consts.DalMySQL)
+	withRedis := render(consts.DalRedis)
+
+	content, err := os.ReadFile(filepath.Join(withMySQL, "main.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "body: \"mysql\"\n", string(content))
+	content, err = os.ReadFile(filepath.Join(withRedis, "main.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "body: \"none\"\n", string(content))
+	assert.FileExists(t, filepath.Join(withMySQL, "mysql.yaml"))
+	assert.NoFileExists(t, filepath.Join(withRedis, "mysql.yaml"))
+	assert.FileExists(t, filepath.Join(withRedis, "conf.yaml"))
+
+	// the shared templates are left as is
+	for name, content := range templates {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(got), name)
+	}
+}
@@ -0,0 +1,378 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const (
+	defaultRPCPort  = 8888
+	defaultHTTPPort = 8080
+	defaultGoImage  = "1.22"
+	defaultK8sName  = "service" // of a service name without any letter or digit
+	maxK8sName      = 63
+)
+
+type deployData struct {
+	Name      string // kubernetes name of the service
+	Binary    string
+	Port      int
+	PortName  string
+	Env       string
+	GoVersion string
+	// HTTP probes, both empty for TCP probes of the service port
+	LivenessPath  string
+	ReadinessPath string
+	ProbePort     string // name of the container port probed
+	HealthPort    int    // port of the separate health server of RPC services, 0 if none
+	Conf          string // content of conf/<env>/conf.yaml
+}
+
+// generateDeploy writes a Dockerfile and the kubernetes manifests of the service,
+// existing files are kept.
+func generateDeploy(c *config.ServerArgument, binary string) error {
+	data := &deployData{
+		Name:      k8sName(binary),
+		Binary:    binary,
+		Port:      c.DeployPort,
+		Env:       c.DeployEnv,
+		GoVersion: goVersion(filepath.Join(c.OutDir, "go.mod")),
+	}
+	if data.Env == "" {
+		data.Env = "online"
+	}
+	switch c.Type {
+	case consts.RPC:
+		data.PortName = "rpc"
+		if data.Port == 0 {
+			data.Port = defaultRPCPort
+		}
+	case consts.HTTP:
+		data.PortName = "http"
+		// the ping route of the hertz layout
+		data.LivenessPath, data.ReadinessPath = "/ping", "/ping"
+		if data.Port == 0 {
+			data.Port = defaultHTTPPort
+		}
+	}
+	data.ProbePort = data.PortName
+
+	confPath := filepath.Join(c.OutDir, "conf", data.Env, "conf.yaml")
+	conf, err := os.ReadFile(confPath)
+	if err != nil {
+		log.Warnf("read %s failed, the ConfigMap is left empty: %v\n", confPath, err)
+	}
+	data.Conf = strings.TrimRight(string(conf), "\n")
+	if c.DeployPort != 0 {
+		data.Conf = setConfPort(data.Conf, c.DeployPort)
+	} else if port := confPort(data.Conf); port != 0 {
+		data.Port = port
+	}
+	// the biz/health package of the standard templates serves /healthz and /readyz,
+	// on the service port for HTTP and on health_address for RPC
+	if hasHealth, _ := utils.PathExist(filepath.Join(c.OutDir, "biz", "health")); hasHealth {
+		switch {
+		case c.Type == consts.HTTP:
+			data.LivenessPath, data.ReadinessPath = "/healthz", "/readyz"
+		case confHealthPort(data.Conf) != 0:
+			data.HealthPort = confHealthPort(data.Conf)
+			data.ProbePort = "health"
+			data.LivenessPath, data.ReadinessPath = "/healthz", "/readyz"
+		}
+	}
+
+	files := []struct {
+		path string
+		tpl  string
+	}{
+		{"Dockerfile", dockerfileTpl},
+		{".dockerignore", dockerignoreTpl},
+		{filepath.Join("deploy", "k8s", "configmap.yaml"), configMapTpl},
+		{filepath.Join("deploy", "k8s", "deployment.yaml"), deploymentTpl},
+		{filepath.Join("deploy", "k8s", "service.yaml"), k8sServiceTpl},
+	}
+	for _, f := range files {
+		path := filepath.Join(c.OutDir, f.path)
+		exist, err := utils.PathExist(path)
+		if err != nil {
+			return err
+		}
+		if exist {
+			log.Warnf("%s already exists, skip it\n", path)
+			continue
+		}
+		var buf bytes.Buffer
+		tmpl := template.Must(template.New(f.path).Funcs(template.FuncMap{"indent": indent}).Parse(f.tpl))
+		if err = tmpl.Execute(&buf, data); err != nil {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err = os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// k8sName converts the service name to a DNS-1123 label, e.g. HelloService -> hello-service.
+// Characters out of [a-z0-9] separate words, the label is cut to 63 characters.
+func k8sName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	dash := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			// a new word starts at an upper case letter after a lower case one, or before one: HTTPServer -> http-server
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				dash()
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			dash()
+		}
+	}
+	label := strings.Trim(b.String(), "-")
+	if len(label) > maxK8sName {
+		label = strings.TrimRight(label[:maxK8sName], "-")
+	}
+	if label == "" {
+		return defaultK8sName
+	}
+	return label
+}
+
+// listenAddr matches the listen address of the kitex or hertz section, the first address of conf.yaml.
+var listenAddr = regexp.MustCompile(`(?m)^(\s+address:\s*"[^"]*?:)(\d+)(")`)
+
+// confPort returns the port of the listen address in conf.yaml, 0 if not found.
+func confPort(conf string) int {
+	m := listenAddr.FindStringSubmatch(conf)
+	if m == nil {
+		return 0
+	}
+	port, _ := strconv.Atoi(m[2])
+	return port
+}
+
+// healthAddr matches the health_address of the kitex section.
+var healthAddr = regexp.MustCompile(`(?m)^\s+health_address:\s*"[^"]*?:(\d+)"`)
+
+// confHealthPort returns the port of health_address in conf.yaml, 0 if not found.
+func confHealthPort(conf string) int {
+	m := healthAddr.FindStringSubmatch(conf)
+	if m == nil {
+		return 0
+	}
+	port, _ := strconv.Atoi(m[1])
+	return port
+}
+
+// setConfPort replaces the port of the listen address in conf.yaml.
+func setConfPort(conf string, port int) string {
+	loc := listenAddr.FindStringSubmatchIndex(conf)
+	if loc == nil {
+		return conf
+	}
+	return conf[:loc[4]] + strconv.Itoa(port) + conf[loc[5]:]
+}
+
+// goVersion returns the major.minor version of the go directive of go.mod, used as the builder image tag.
+func goVersion(gomod string) string {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return defaultGoImage
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// the directive may be followed by a comment: go 1.21 // ...
+		if len(fields) >= 2 && fields[0] == "go" {
+			parts := strings.SplitN(fields[1], ".", 3)
+			if len(parts) >= 2 {
+				return parts[0] + "." + parts[1]
+			}
+		}
+	}
+	return defaultGoImage
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+var dockerfileTpl = `# Build stage
+FROM golang:{{.GoVersion}} AS builder
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" -o /out/{{.Binary}} .
+
+# Runtime stage
+FROM alpine:3.19
+RUN apk add --no-cache ca-certificates tzdata \
+    && adduser -D -u 10001 app \
+    && mkdir -p /app/log \
+    && chown app /app/log
+WORKDIR /app
+COPY --from=builder /out/{{.Binary}} /app/{{.Binary}}
+COPY conf ./conf
+USER app
+ENV GO_ENV={{.Env}}
+EXPOSE {{.Port}}
+ENTRYPOINT ["/app/{{.Binary}}"]
+`
+
+var dockerignoreTpl = `.git
+output
+log
+deploy
+`
+
+var configMapTpl = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{.Name}}-conf
+  labels:
+    app: {{.Name}}
+data:
+  conf.yaml: |
+{{indent 4 .Conf}}
+`
+
+var deploymentTpl = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+  labels:
+    app: {{.Name}}
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app: {{.Name}}
+    spec:
+      terminationGracePeriodSeconds: 30
+      containers:
+        - name: {{.Name}}
+          image: {{.Name}}:latest
+          imagePullPolicy: IfNotPresent
+          ports:
+            - name: {{.PortName}}
+              containerPort: {{.Port}}
+{{- if .HealthPort}}
+            - name: health
+              containerPort: {{.HealthPort}}
+{{- end}}
+          env:
+            - name: GO_ENV
+              value: {{.Env}}
+          readinessProbe:
+{{- if .ReadinessPath}}
+            httpGet:
+              path: {{.ReadinessPath}}
+              port: {{.ProbePort}}
+{{- else}}
+            tcpSocket:
+              port: {{.ProbePort}}
+{{- end}}
+            initialDelaySeconds: 3
+            periodSeconds: 5
+          livenessProbe:
+{{- if .LivenessPath}}
+            httpGet:
+              path: {{.LivenessPath}}
+              port: {{.ProbePort}}
+{{- else}}
+            tcpSocket:
+              port: {{.ProbePort}}
+{{- end}}
+            initialDelaySeconds: 10
+            periodSeconds: 10
+          resources:
+            requests:
+              cpu: 100m
+              memory: 128Mi
+            limits:
+              cpu: "1"
+              memory: 512Mi
+          volumeMounts:
+            - name: conf
+              mountPath: /app/conf/{{.Env}}/conf.yaml
+              subPath: conf.yaml
+              readOnly: true
+            - name: log
+              mountPath: /app/log
+      volumes:
+        - name: conf
+          configMap:
+            name: {{.Name}}-conf
+        - name: log
+          emptyDir: {}
+`
+
+var k8sServiceTpl = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Name}}
+  labels:
+    app: {{.Name}}
+spec:
+  type: ClusterIP
+  selector:
+    app: {{.Name}}
+  ports:
+    - name: {{.PortName}}
+      port: {{.Port}}
+      targetPort: {{.PortName}}
+`
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestK8sName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "hello", want: "hello"},
+		{name: "HelloService", want: "hello-service"},
+		{name: "HTTPServer", want: "http-server"},
+		{name: "api2Gateway", want: "api2-gateway"},
+		{name: "Echo2", want: "echo2"},
+		{name: "hello_world", want: "hello-world"},
+		{name: "hello.world", want: "hello-world"},
+		{name: "__hello--world__", want: "hello-world"},
+		{name: "hello world!", want: "hello-world"},
+		{name: "héllo", want: "h-llo"},
+		{name: "服务Echo", want: "echo"},
+		{name: "服务", want: defaultK8sName},
+		{name: "", want: defaultK8sName},
+		{name: strings.Repeat("a", 62) + "_bc", want: strings.Repeat("a", 62)},
+		{name: strings.Repeat("a", 70), want: strings.Repeat("a", 63)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, k8sName(tt.name))
+		})
+	}
+}
+
+func TestConfPort(t *testing.T) {
+	tests := []struct {
+		name string
+		conf string
+		want int
+	}{
+		{
+			name: "kitex",
+			conf: "kitex:\n  service: \"echo\"\n  address: \":8888\"\n  health_address: \":8889\"\n",
+			want: 8888,
+		},
+		{
+			name: "hertz with host",
+			conf: "hertz:\n  service: \"echo\"\n  address: \"127.0.0.1:8080\"\n",
+			want: 8080,
+		},
+		{
+			name: "first address",
+			conf: "hertz:\n  address: \":8080\"\nredis:\n  address: \"127.0.0.1:6379\"\n",
+			want: 8080,
+		},
+		{
+			name: "missing port",
+			conf: "hertz:\n  address: \"localhost\"\n",
+		},
+		{
+			name: "missing address",
+			conf: "kitex:\n  service: \"echo\"\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, confPort(tt.conf))
+		})
+	}
+}
+
+func TestSetConfPort(t *testing.T) {
+	tests := []struct {
+		name string
+		conf string
+		want string
+	}{
+		{
+			name: "kitex",
+			conf: "kitex:\n  address: \":8888\"\n  health_address: \":8889\"\n",
+			want: "kitex:\n  address: \":9000\"\n  health_address: \":8889\"\n",
+		},
+		{
+			name: "first address",
+			conf: "hertz:\n  address: \"0.0.0.0:8080\"\nredis:\n  address: \"127.0.0.1:6379\"\n",
+			want: "hertz:\n  address: \"0.0.0.0:9000\"\nredis:\n  address: \"127.0.0.1:6379\"\n",
+		},
+		{
+			name: "missing port",
+			conf: "hertz:\n  address: \"localhost\"\n",
+			want: "hertz:\n  address: \"localhost\"\n",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, setConfPort(tt.conf, 9000))
+		})
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		gomod string
+		want  string
+	}{
+		{name: "minor", gomod: "module example.com/echo\n\ngo 1.21\n", want: "1.21"},
+		{name: "patch", gomod: "module example.com/echo\n\ngo 1.22.3\n\ntoolchain go1.23.1\n", want: "1.22"},
+		{name: "comment", gomod: "module example.com/echo\n\ngo 1.20 // the oldest supported\n", want: "1.20"},
+		{name: "no go directive", gomod: "module example.com/echo\n\nrequire github.com/cloudwego/kitex v0.9.1\n", want: defaultGoImage},
+		{name: "major only", gomod: "module example.com/echo\n\ngo 1\n", want: defaultGoImage},
+		{name: "empty", want: defaultGoImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gomod := filepath.Join(t.TempDir(), "go.mod")
+			assert.NoError(t, os.WriteFile(gomod, []byte(tt.gomod), 0o644))
+			assert.Equal(t, tt.want, goVersion(gomod))
+		})
+	}
+
+	// no go.mod
+	assert.Equal(t, defaultGoImage, goVersion(filepath.Join(t.TempDir(), "go.mod")))
+}
@@ -0,0 +1,391 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+const (
+	errnoDir         = "biz/errno"
+	errnoFile        = "errno.go"
+	errnoCodesFile   = "codes.go"
+	errnoCatalogMD   = "catalog.md"
+	errnoCatalogJSON = "catalog.json"
+
+	// annotations of the IDL declaring the codes
+	errnoAnnotation     = "errno"      // on an enum, whose values are the codes
+	errnoCodeAnnotation = "errno.code" // on an exception, thrift exception or proto message named *Error
+	errnoMsgAnnotation  = "errno.msg"
+	errnoHTTPAnnotation = "errno.http"
+
+	defaultErrnoStatus = 500
+)
+
+type errnoEntry struct {
+	Name    string `json:"name"`
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+	Status  int    `json:"http_status"`
+	Source  string `json:"source"`
+}
+
+// generateErrno generates the error codes of biz/errno from the enums annotated with errno and the
+// exceptions annotated with errno.code, e.g. in thrift:
+//
+//	enum ErrCode {
+//	    UserNotFound = 10001 (errno.msg = "user not found", errno.http = "404")
+//	} (errno = "true")
+//
+// The codes and their catalogs are rewritten on every generation so that they follow the IDL,
+// errno.go is only written once. Nothing is generated until the IDL declares codes.
+func generateErrno(c *config.ServerArgument) error {
+	paths, err := utils.ExpandIDLPaths(c.IdlPath)
+	if err != nil {
+		return err
+	}
+	idls := make([]*idlparser.IDL, 0, len(paths))
+	for _, p := range paths {
+		idl, err := idlparser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return err
+		}
+		idls = append(idls, idl)
+	}
+	entries, err := buildErrnoCatalog(idls)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(c.OutDir, errnoDir)
+	exist, err := utils.PathExist(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 && !exist {
+		return nil
+	}
+	if exist, err = utils.PathExist(filepath.Join(dir, errnoFile)); err != nil {
+		return err
+	}
+	if !exist {
+		if err = writeGoFile(filepath.Join(dir, errnoFile), errnoTpl, c.Type == consts.RPC); err != nil {
+			return err
+		}
+	}
+	if err = writeGoFile(filepath.Join(dir, errnoCodesFile), errnoCodesTpl, entries); err != nil {
+		return err
+	}
+	return writeErrnoCatalogs(dir, c.ServerName, entries)
+}
+
+// buildErrnoCatalog collects the codes of the IDLs and of their includes, ordered by code.
+func buildErrnoCatalog(idls []*idlparser.IDL) ([]*errnoEntry, error) {
+	var entries []*errnoEntry
+	visited := make(map[string]bool)
+	var walk func(idl *idlparser.IDL) error
+	walk = func(idl *idlparser.IDL) error {
+		if visited[idl.Path] {
+			return nil
+		}
+		visited[idl.Path] = true
+		file := filepath.Base(idl.Path)
+		for _, e := range idl.Enums {
+			if _, ok := e.Annotations.Get(errnoAnnotation); !ok {
+				continue
+			}
+			for _, v := range e.Values {
+				// zero is the success of the enums of proto
+				if v.Value == 0 {
+					continue
+				}
+				if v.Value < math.MinInt32 || v.Value > math.MaxInt32 {
+					return fmt.Errorf("errno %s.%s of %s: code %d is out of the int32 range", e.Name, v.Name, file, v.Value)
+				}
+				entry, err := newErrnoEntry(v.Name, int32(v.Value), v.Annotations, v.Comment)
+				if err != nil {
+					return fmt.Errorf("errno %s.%s of %s: %v", e.Name, v.Name, file, err)
+				}
+				entry.Source = file + " " + e.Name + "." + v.Name
+				entries = append(entries, entry)
+			}
+		}
+		for _, s := range idl.Structs {
+			if s.Category != "exception" && !(idl.Type == consts.Proto && strings.HasSuffix(s.Name, "Error")) {
+				continue
+			}
+			value, ok := s.Annotations.Get(errnoCodeAnnotation)
+			if !ok {
+				continue
+			}
+			code, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("errno %s of %s: invalid code %q", s.Name, file, value)
+			}
+			name := strings.TrimSuffix(strings.TrimSuffix(s.Name, "Exception"), "Error")
+			if name == "" {
+				name = s.Name
+			}
+			entry, err := newErrnoEntry(name, int32(code), s.Annotations, s.Comment)
+			if err != nil {
+				return fmt.Errorf("errno %s of %s: %v", s.Name, file, err)
+			}
+			entry.Source = file + " " + s.Name
+			entries = append(entries, entry)
+		}
+
+		names := make([]string, 0, len(idl.Includes))
+		for name := range idl.Includes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := walk(idl.Includes[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, idl := range idls {
+		if err := walk(idl); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+	names := make(map[string]*errnoEntry, len(entries))
+	for i, e := range entries {
+		if i > 0 && entries[i-1].Code == e.Code {
+			return nil, fmt.Errorf("errno code %d is declared by both %s and %s", e.Code, entries[i-1].Source, e.Source)
+		}
+		if other, ok := names[e.Name]; ok {
+			return nil, fmt.Errorf("errno %s is declared by both %s and %s", e.Name, other.Source, e.Source)
+		}
+		names[e.Name] = e
+	}
+	return entries, nil
+}
+
+func newErrnoEntry(name string, code int32, annos idlparser.Annotations, comment string) (*errnoEntry, error) {
+	e := &errnoEntry{Name: errnoName(name), Code: code, Status: defaultErrnoStatus}
+	if msg, ok := annos.Get(errnoMsgAnnotation); ok {
+		e.Message = msg
+	} else if comment != "" {
+		e.Message = strings.ReplaceAll(comment, "\n", " ")
+	} else {
+		e.Message = strings.Join(splitWords(name), " ")
+	}
+	if value, ok := annos.Get(errnoHTTPAnnotation); ok {
+		status, err := strconv.Atoi(value)
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("invalid HTTP status %q", value)
+		}
+		e.Status = status
+	}
+	return e, nil
+}
+
+// errnoName is the Go name of the error value, e.g. ErrUserNotFound for UserNotFound or USER_NOT_FOUND.
+func errnoName(name string) string {
+	if strings.Contains(name, "_") || strings.ToUpper(name) == name {
+		name = strings.Join(splitWords(name), "_")
+	}
+	return "Err" + idlparser.GoName(consts.Thrift, name)
+}
+
+// splitWords splits a CamelCase or snake_case identifier into lower case words.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '_' {
+			if len(word) > 0 {
+				words, word = append(words, string(word)), nil
+			}
+			continue
+		}
+		// a new word starts at an upper case letter following a lower case one, or before the
+		// lower case letter ending an acronym, e.g. HTTPError is HTTP Error
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words, word = append(words, string(word)), nil
+			}
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+func writeErrnoCatalogs(dir, service string, entries []*errnoEntry) error {
+	if entries == nil {
+		entries = []*errnoEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(dir, errnoCatalogJSON), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	tmpl := template.Must(template.New(errnoCatalogMD).Funcs(template.FuncMap{
+		"cell": func(s string) string {
+			return strings.ReplaceAll(s, "|", `\|`)
+		},
+	}).Parse(errnoCatalogTpl))
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, map[string]interface{}{"Service": service, "Entries": entries}); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, errnoCatalogMD), buf.Bytes(), 0o644)
+}
+
+var errnoCatalogTpl = `# Error codes of {{.Service}}
+
+Generated by cwgo from the errno annotations of the IDL, do not edit.
+
+| Code | Name | HTTP status | Message | Source |
+| ---- | ---- | ----------- | ------- | ------ |
+{{- range .Entries}}
+| {{.Code}} | {{.Name}} | {{.Status}} | {{cell .Message}} | {{.Source}} |
+{{- end}}
+`
+
+var errnoTpl = `package errno
+
+import (
+	"errors"
+	"fmt"
+	{{- if .}}
+	"net/http"
+
+	"github.com/cloudwego/kitex/pkg/kerrors"
+	{{- end}}
+)
+
+// Errno is a business error of the codes of codes.go, generated from the IDL.
+{{- if .}}
+// The kitex handlers return it as a BizStatusError, which needs the TTHeader or gRPC transport,
+// and the HTTP handlers write it as a JSON error with its HTTP status.
+{{- else}}
+// biz/utils.SendErrResponse writes it as a JSON error with its HTTP status.
+{{- end}}
+type Errno struct {
+	Code    int32
+	Message string
+	Status  int // HTTP status of the error
+}
+
+func (e *Errno) Error() string {
+	return fmt.Sprintf("errno %d: %s", e.Code, e.Message)
+}
+
+// WithMessage returns the error with a more specific message, it keeps the code.
+func (e *Errno) WithMessage(format string, args ...interface{}) *Errno {
+	return &Errno{Code: e.Code, Message: fmt.Sprintf(format, args...), Status: e.Status}
+}
+
+// Is reports whether target has the same code, so that errors.Is matches the errors of WithMessage.
+func (e *Errno) Is(target error) bool {
+	t, ok := target.(*Errno)
+	return ok && t.Code == e.Code
+}
+
+// BizStatusCode, BizMessage and BizExtra implement the BizStatusErrorIface of kitex.
+func (e *Errno) BizStatusCode() int32 {
+	return e.Code
+}
+
+func (e *Errno) BizMessage() string {
+	return e.Message
+}
+
+func (e *Errno) BizExtra() map[string]string {
+	return nil
+}
+
+// HTTPStatus is the status of the HTTP responses of the error.
+func (e *Errno) HTTPStatus() int {
+	return e.Status
+}
+
+// FromError returns the Errno wrapped by err
+{{- if .}}, or the one of the BizStatusError received by a kitex client:
+// the error of the code with the message received, whose code may be unknown
+{{- end}}.
+func FromError(err error) (*Errno, bool) {
+	var e *Errno
+	if errors.As(err, &e) {
+		return e, true
+	}
+	{{- if .}}
+	if bizErr, ok := kerrors.FromBizStatusError(err); ok {
+		if e, ok := Lookup(bizErr.BizStatusCode()); ok {
+			return e.WithMessage("%s", bizErr.BizMessage()), true
+		}
+		return &Errno{Code: bizErr.BizStatusCode(), Message: bizErr.BizMessage(), Status: http.StatusInternalServerError}, true
+	}
+	{{- end}}
+	return nil, false
+}
+`
+
+var errnoCodesTpl = `// Code generated by cwgo. DO NOT EDIT.
+
+package errno
+
+var (
+	{{- range .}}
+	// {{.Name}} {{.Message}}, from {{.Source}}.
+	{{.Name}} = &Errno{Code: {{.Code}}, Message: {{printf "%q" .Message}}, Status: {{.Status}}}
+	{{- end}}
+)
+
+var codes = map[int32]*Errno{
+	{{- range .}}
+	{{.Code}}: {{.Name}},
+	{{- end}}
+}
+
+// Lookup returns the error of the code.
+func Lookup(code int32) (*Errno, bool) {
+	e, ok := codes[code]
+	return e, ok
+}
+`
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildErrnoCatalog(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.thrift": `
+namespace go base
+enum ErrCode {
+    Success = 0
+    // the user does not exist
+    UserNotFound = 10001 (errno.http = "404")
+    HTTPTimeout = 10003
+} (errno = "true")
+enum Status { Ok = 1 }
+exception BizError { 1: i32 Code } (errno.code = "20001", errno.msg = "business error", errno.http = "400")
+exception OtherError { 1: i32 Code }
+`,
+		"hello.thrift": `
+namespace go hello
+include "base.thrift"
+service Hello { void Ping() throws (1: base.BizError err) }
+`,
+		"hello.proto": `
+syntax = "proto3";
+package hello;
+enum Code {
+    option (errno) = true;
+    CODE_UNSPECIFIED = 0;
+    INVALID_PARAM = 10002 [(errno.msg) = "invalid parameter"];
+}
+message QuotaError { option (errno.code) = 20002; }
+message Quota { option (errno.code) = 20003; }
+service Greeter { rpc Ping(Quota) returns (Quota); }
+`,
+	}
+	for name, content := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	var idls []*idlparser.IDL
+	for _, name := range []string{"hello.thrift", "hello.proto"} {
+		idl, err := idlparser.ParseIDL(filepath.Join(dir, name), []string{dir})
+		assert.Nil(t, err)
+		idls = append(idls, idl)
+	}
+
+	entries, err := buildErrnoCatalog(idls)
+	assert.Nil(t, err)
+	assert.Equal(t, []*errnoEntry{
+		{Name: "ErrUserNotFound", Code: 10001, Message: "the user does not exist", Status: 404, Source: "base.thrift ErrCode.UserNotFound"},
+		{Name: "ErrInvalidParam", Code: 10002, Message: "invalid parameter", Status: 500, Source: "hello.proto Code.INVALID_PARAM"},
+		{Name: "ErrHTTPTimeout", Code: 10003, Message: "http timeout", Status: 500, Source: "base.thrift ErrCode.HTTPTimeout"},
+		{Name: "ErrBiz", Code: 20001, Message: "business error", Status: 400, Source: "base.thrift BizError"},
+		{Name: "ErrQuota", Code: 20002, Message: "quota", Status: 500, Source: "hello.proto QuotaError"},
+	}, entries)
+
+	// the codes are unique
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "dup.thrift"), []byte(`
+enum E { A = 10001 } (errno = "true")
+`), 0o644))
+	dup, err := idlparser.ParseIDL(filepath.Join(dir, "dup.thrift"), nil)
+	assert.Nil(t, err)
+	_, err = buildErrnoCatalog(append(idls, dup))
+	assert.NotNil(t, err)
+}
+
+func TestGenerateErrno(t *testing.T) {
+	dir := t.TempDir()
+	idl := filepath.Join(dir, "hello.thrift")
+	write := func(content string) {
+		assert.Nil(t, os.WriteFile(idl, []byte(content), 0o644))
+	}
+	c := &config.ServerArgument{
+		CommonParam: &config.CommonParam{IdlPath: idl, ServerName: "hello", Type: consts.RPC, OutDir: dir},
+		SliceParam:  &config.SliceParam{},
+	}
+
+	// nothing is generated without codes
+	write(`service Hello { void Ping() }`)
+	assert.Nil(t, generateErrno(c))
+	_, err := os.Stat(filepath.Join(dir, errnoDir))
+	assert.True(t, os.IsNotExist(err))
+
+	write(`enum E { NotFound = 1 (errno.http = "404") } (errno = "true")`)
+	assert.Nil(t, generateErrno(c))
+	codes, err := os.ReadFile(filepath.Join(dir, errnoDir, errnoCodesFile))
+	assert.Nil(t, err)
+	assert.Contains(t, string(codes), `ErrNotFound = &Errno{Code: 1, Message: "not found", Status: 404}`)
+	errno, err := os.ReadFile(filepath.Join(dir, errnoDir, errnoFile))
+	assert.Nil(t, err)
+	assert.Contains(t, string(errno), "kerrors.FromBizStatusError(err)")
+	catalog, err := os.ReadFile(filepath.Join(dir, errnoDir, errnoCatalogMD))
+	assert.Nil(t, err)
+	assert.Contains(t, string(catalog), "| 1 | ErrNotFound | 404 | not found | hello.thrift E.NotFound |")
+
+	// the codes follow the IDL, errno.go is kept
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, errnoDir, errnoFile), []byte("package errno\n"), 0o644))
+	write(`service Hello { void Ping() }`)
+	assert.Nil(t, generateErrno(c))
+	codes, err = os.ReadFile(filepath.Join(dir, errnoDir, errnoCodesFile))
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(codes), "ErrNotFound"))
+	errno, err = os.ReadFile(filepath.Join(dir, errnoDir, errnoFile))
+	assert.Nil(t, err)
+	assert.Equal(t, "package errno\n", string(errno))
+}
+
+func TestErrnoName(t *testing.T) {
+	assert.Equal(t, "ErrUserNotFound", errnoName("UserNotFound"))
+	assert.Equal(t, "ErrUserNotFound", errnoName("USER_NOT_FOUND"))
+	assert.Equal(t, "ErrUserNotFound", errnoName("user_not_found"))
+	assert.Equal(t, "ErrHTTPTimeout", errnoName("HTTPTimeout"))
+	assert.Equal(t, []string{"http", "timeout", "v2"}, splitWords("HTTPTimeoutV2"))
+}
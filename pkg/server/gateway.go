@@ -0,0 +1,644 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/openapi"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const (
+	gatewayFile        = "gateway.go"
+	gatewayRuntimeFile = "biz/gateway/gateway.go"
+)
+
+type gatewayData struct {
+	ProjPackage string
+	Proto       bool              // whether one of the IDLs is a proto, whose messages are encoded with protojson
+	Imports     map[string]string // import path by package alias
+	Services    []*gatewayService
+	Agent       bool // serve the routes of the eino agent, which has no biz/router/register.go to register them
+}
+
+type gatewayService struct {
+	Impl   string // type implementing the kitex service in handler.go
+	Var    string
+	Routes []*gatewayRoute
+}
+
+type gatewayRoute struct {
+	Name       string // method of the kitex service
+	Comment    string
+	HTTPMethod string
+	Path       string // hertz route, e.g. /user/:id
+	Codec      string
+	Request    string // Go type of the request, empty if the method has no argument
+	Void       bool
+	Params     []gatewayParam
+	Response   []gatewayParam
+	Exceptions []string // Go types of the exceptions declared by the method
+}
+
+type gatewayParam struct {
+	In    string
+	Key   string
+	Field string
+	Kind  string
+	List  bool
+}
+
+// generateGateway serves the HTTP routes declared by the api annotations of the IDLs with the kitex
+// service implementation, instead of the separate hertz handlers generated by hz for hex.
+// Both files are rewritten on every generation so that they follow the IDL.
+func generateGateway(c *config.ServerArgument, kitexGen string, idlPaths []string) error {
+	data := &gatewayData{ProjPackage: c.GoMod, Imports: make(map[string]string), Agent: c.EnableEino}
+	aliases := make(map[string]string) // alias by import path
+	importType := func(idl *idlparser.IDL, name string) string {
+		owner, local := idl.Resolve(name)
+		pkgPath, pkgName := owner.GoImport(c.GoMod, kitexGen)
+		alias, ok := aliases[pkgPath]
+		if !ok {
+			alias = pkgName
+			for i := 2; data.Imports[alias] != "" || isGatewayIdent(alias); i++ {
+				alias = pkgName + strconv.Itoa(i)
+			}
+			aliases[pkgPath] = alias
+			data.Imports[alias] = pkgPath
+		}
+		return alias + "." + idlparser.GoName(owner.Type, local)
+	}
+
+	vars := make(map[string]bool)
+	for _, p := range idlPaths {
+		idl, err := idlparser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return err
+		}
+		svc := kitexService(idl, c.ServerName)
+		if svc == nil {
+			continue
+		}
+		impl := idlparser.GoName(idl.Type, svc.Name) + "Impl"
+		s := &gatewayService{Impl: impl, Var: lowerFirst(impl)}
+		for i := 2; vars[s.Var]; i++ {
+			s.Var = lowerFirst(impl) + strconv.Itoa(i)
+		}
+		vars[s.Var] = true
+		for _, m := range svc.Methods {
+			method, path, ok := openapi.Route(m)
+			if !ok {
+				continue
+			}
+			r, reason := gatewayRouteOf(idl, m, method, path, importType)
+			if r == nil {
+				log.Warnf("%s.%s is not served by the gateway: %s\n", svc.Name, m.Name, reason)
+				continue
+			}
+			if idl.Type == consts.Proto {
+				data.Proto = true
+			}
+			s.Routes = append(s.Routes, r)
+		}
+		if len(s.Routes) > 0 {
+			data.Services = append(data.Services, s)
+		}
+	}
+
+	if err := writeGoFile(filepath.Join(c.OutDir, gatewayRuntimeFile), gatewayRuntimeTpl, data); err != nil {
+		return err
+	}
+	if err := writeGoFile(filepath.Join(c.OutDir, gatewayFile), gatewayTpl, data); err != nil {
+		return err
+	}
+	if content, err := os.ReadFile(filepath.Join(c.OutDir, hexFile)); err == nil && !bytes.Contains(content, []byte("registerGateway(")) {
+		log.Warnf("%s registers the routes of hz, call registerGateway(h) in its initHertz instead of router.GeneratedRegister(h)\n", hexFile)
+	}
+	return nil
+}
+
+func gatewayRouteOf(idl *idlparser.IDL, m *idlparser.Method, method, path string, importType func(*idlparser.IDL, string) string) (*gatewayRoute, string) {
+	if m.ClientStreaming || m.ServerStreaming {
+		return nil, "streaming methods are not supported"
+	}
+	r := &gatewayRoute{
+		Name:       idlparser.GoName(idl.Type, m.Name),
+		Comment:    strings.ReplaceAll(m.Comment, "\n", "\n// "),
+		HTTPMethod: strings.ToUpper(method),
+		Path:       hertzPath(path),
+		Codec:      "Thrift",
+		Void:       m.Response == nil,
+	}
+	if idl.Type == consts.Proto {
+		r.Codec = "Protobuf"
+	}
+
+	switch {
+	case len(m.Args) == 0:
+	case len(m.Args) == 1 && m.Args[0].Type.Kind == idlparser.KindStruct:
+		owner, local := idl.Resolve(m.Args[0].Type.Name)
+		st := owner.LookupStruct(local)
+		if st == nil {
+			return nil, fmt.Sprintf("request type %s not found", m.Args[0].Type.Name)
+		}
+		r.Request = importType(idl, m.Args[0].Type.Name)
+		for _, f := range st.Fields {
+			in, key := openapi.Binding(f, method, path)
+			if _, ok := f.Annotations.Get("api.file_name"); ok {
+				in = "file"
+			}
+			if in == "" || (in == "body" && key == f.Name) {
+				continue
+			}
+			p := gatewayParamOf(f)
+			p.In, p.Key = in, key
+			r.Params = append(r.Params, p)
+		}
+	default:
+		return nil, "the request must be a single struct"
+	}
+
+	if m.Response != nil && m.Response.Kind == idlparser.KindStruct {
+		owner, local := idl.Resolve(m.Response.Name)
+		if st := owner.LookupStruct(local); st != nil {
+			for _, f := range st.Fields {
+				p := gatewayParamOf(f)
+				if key, ok := f.Annotations.Get("api.header"); ok {
+					p.In, p.Key = "header", key
+				} else if key = openapi.JSONName(f); key != f.Name {
+					p.In, p.Key = "body", key
+				} else {
+					continue
+				}
+				r.Response = append(r.Response, p)
+			}
+		}
+	}
+	for _, ex := range m.Exceptions {
+		r.Exceptions = append(r.Exceptions, importType(idl, ex.Type.Name))
+	}
+	return r, ""
+}
+
+func gatewayParamOf(f *idlparser.Field) gatewayParam {
+	p := gatewayParam{Field: f.Name}
+	t := f.Type
+	if t.Kind == idlparser.KindList || t.Kind == idlparser.KindSet {
+		p.List, t = true, t.ValueType
+	}
+	switch {
+	case t.Kind == idlparser.KindEnum:
+		p.Kind = "Int"
+	case t.Kind != idlparser.KindBase:
+		p.Kind = "JSON"
+	case t.Name == "bool":
+		p.Kind = "Bool"
+	case t.Name == "float" || t.Name == "double":
+		p.Kind = "Float"
+	case t.Name == "binary":
+		p.Kind = "Bytes"
+	case t.Name == "string":
+		p.Kind = "String"
+	default:
+		p.Kind = "Int"
+	}
+	return p
+}
+
+// hertzPath converts the "{name}" parameters of an OpenAPI path back to the ":name" of hertz routes.
+func hertzPath(path string) string {
+	return strings.NewReplacer("{", ":", "}", "").Replace(path)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// isGatewayIdent reports whether the name is used by gateway.go, so that it can not alias an import.
+func isGatewayIdent(name string) bool {
+	switch name {
+	case "context", "app", "server", "gateway", "agentrouter":
+		return true
+	}
+	return false
+}
+
+func writeGoFile(path, tpl string, data interface{}) error {
+	tmpl, err := template.New(filepath.Base(path)).Parse(tpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format %s failed: %v", path, err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+var gatewayTpl = `// Code generated by cwgo. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"{{.ProjPackage}}/biz/gateway"
+{{- if .Agent}}
+	agentrouter "{{.ProjPackage}}/biz/router/agent"
+{{- end}}
+{{- range $alias, $path := .Imports}}
+	{{$alias}} "{{$path}}"
+{{- end}}
+)
+
+// registerGateway serves the HTTP routes of the IDL with the implementation of the Kitex service,
+// so that the methods in biz/service are called by both the RPC and the HTTP requests.
+func registerGateway(h *server.Hertz) {
+{{- range $i, $_ := .Services}}
+{{- if $i}}
+{{end}}
+	{{.Var}} := new({{.Impl}})
+{{- $svc := .}}
+{{- range .Routes}}
+{{if .Comment}}
+	// {{.Comment}}
+{{- end}}
+	h.{{.HTTPMethod}}("{{.Path}}", gateway.Handler(gateway.Route{
+		Codec: gateway.{{.Codec}},
+{{- if .Params}}
+		Params: []gateway.Param{
+{{- range .Params}}
+			{In: "{{.In}}", Key: "{{.Key}}", Field: "{{.Field}}", Kind: gateway.{{.Kind}}{{if .List}}, List: true{{end}}},
+{{- end}}
+		},
+{{- end}}
+{{- if .Response}}
+		Response: []gateway.Param{
+{{- range .Response}}
+			{In: "{{.In}}", Key: "{{.Key}}", Field: "{{.Field}}", Kind: gateway.{{.Kind}}{{if .List}}, List: true{{end}}},
+{{- end}}
+		},
+{{- end}}
+{{- if .Exceptions}}
+		Exceptions: []interface{}{ {{- range $i, $e := .Exceptions}}{{if $i}}, {{end}}new(*{{$e}}){{end -}} },
+{{- end}}
+{{- if .Request}}
+		NewRequest: func() interface{} { return new({{.Request}}) },
+{{- end}}
+		Call: func(ctx context.Context, req interface{}) (interface{}, error) {
+{{- if .Void}}
+			return nil, {{$svc.Var}}.{{.Name}}(ctx{{if .Request}}, req.(*{{.Request}}){{end}})
+{{- else}}
+			return {{$svc.Var}}.{{.Name}}(ctx{{if .Request}}, req.(*{{.Request}}){{end}})
+{{- end}}
+		},
+	}))
+{{- end}}
+{{- end}}
+{{- if .Agent}}
+
+	agentrouter.Register(h)
+{{- end}}
+}
+`
+
+var gatewayRuntimeTpl = `// Code generated by cwgo. DO NOT EDIT.
+
+// Package gateway serves the HTTP routes declared by the api annotations of the IDL with the
+// Kitex service implementation: the requests are bound from the path, the query, the headers,
+// the cookies, the forms and the JSON body the same way as hertz does, and the responses are
+// written as JSON.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/kitex/pkg/kerrors"
+{{- if .Proto}}
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+{{- end}}
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// Kind is the type of a parameter, it tells how the parameter is converted to JSON.
+type Kind int
+
+const (
+	String Kind = iota
+	Bool
+	Int
+	Float
+	Bytes // encoded in base64 like the binary fields
+	JSON  // structs and maps, written as JSON in the parameter
+)
+
+// Param maps a parameter of the HTTP request or response to a field of the Kitex request or response.
+type Param struct {
+	// In is where the parameter is: path, query, header, cookie, form, file or body.
+	// Body parameters are the fields whose JSON key is not the name of the field.
+	In    string
+	Key   string
+	Field string // name of the field in the IDL
+	Kind  Kind
+	List  bool
+}
+
+// Codec converts the requests and the responses of Kitex from and to JSON.
+type Codec struct {
+	Marshal   func(v interface{}) ([]byte, error)
+	Unmarshal func(data []byte, v interface{}) error
+}
+
+// Thrift encodes the structs generated by Kitex with their json tags, which are the names of the fields.
+var Thrift = Codec{Marshal: json.Marshal, Unmarshal: json.Unmarshal}
+{{- if .Proto}}
+
+// Protobuf encodes the messages with the names of the fields in the IDL.
+var Protobuf = Codec{
+	Marshal: func(v interface{}) ([]byte, error) {
+		return protojson.MarshalOptions{UseProtoNames: true}.Marshal(v.(proto.Message))
+	},
+	Unmarshal: func(data []byte, v interface{}) error {
+		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, v.(proto.Message))
+	},
+}
+{{- end}}
+
+// Route calls a method of the Kitex service for the requests of an HTTP route.
+type Route struct {
+	Codec    Codec
+	Params   []Param // fields of the request which are not bound from the JSON body by their name
+	Response []Param // fields of the response which are written to headers or with another JSON key
+	// Exceptions are pointers to the exceptions declared by the method, e.g. new(*example.BizError),
+	// they are written as JSON with the status 500 so that the HTTP clients decode them.
+	Exceptions []interface{}
+	NewRequest func() interface{} // nil if the method has no argument
+	Call       func(ctx context.Context, req interface{}) (interface{}, error)
+}
+
+// Handler returns the hertz handler of the route.
+func Handler(r Route) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		var req interface{}
+		if r.NewRequest != nil {
+			req = r.NewRequest()
+			if err := Bind(c, r.Codec, r.Params, req); err != nil {
+				writeJSON(c, http.StatusBadRequest, map[string]string{"message": err.Error()})
+				return
+			}
+		}
+		resp, err := r.Call(ctx, req)
+		if err != nil {
+			Error(c, err, r.Exceptions...)
+			return
+		}
+		Respond(c, r.Codec, r.Response, resp)
+	}
+}
+
+// Bind decodes the JSON body and the parameters of the HTTP request into req.
+func Bind(c *app.RequestContext, codec Codec, params []Param, req interface{}) error {
+	fields := make(map[string]json.RawMessage)
+	if body := c.Request.Body(); len(body) > 0 && strings.Contains(string(c.ContentType()), "json") {
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return fmt.Errorf("decode body: %w", err)
+		}
+	}
+	for _, p := range params {
+		if p.In == "body" {
+			if v, ok := fields[p.Key]; ok {
+				delete(fields, p.Key)
+				fields[p.Field] = v
+			}
+			continue
+		}
+		values, err := values(c, p)
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if !p.List {
+			values = values[:1]
+		}
+		raws := make([]json.RawMessage, 0, len(values))
+		for _, v := range values {
+			raw, err := toJSON(p.Kind, v)
+			if err != nil {
+				return fmt.Errorf("bind %s %q: %w", p.In, p.Key, err)
+			}
+			raws = append(raws, raw)
+		}
+		if p.List {
+			fields[p.Field], _ = json.Marshal(raws)
+		} else {
+			fields[p.Field] = raws[0]
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, req)
+}
+
+func values(c *app.RequestContext, p Param) ([]string, error) {
+	var values []string
+	switch p.In {
+	case "path":
+		if v, ok := c.Params.Get(p.Key); ok {
+			values = append(values, v)
+		}
+	case "query":
+		for _, v := range c.QueryArgs().PeekAll(p.Key) {
+			values = append(values, string(v))
+		}
+	case "header":
+		if v := c.Request.Header.Peek(p.Key); v != nil {
+			values = append(values, string(v))
+		}
+	case "cookie":
+		if v := c.Cookie(p.Key); v != nil {
+			values = append(values, string(v))
+		}
+	case "form":
+		for _, v := range c.PostArgs().PeekAll(p.Key) {
+			values = append(values, string(v))
+		}
+		if form, err := c.MultipartForm(); len(values) == 0 && err == nil {
+			values = append(values, form.Value[p.Key]...)
+		}
+	case "file":
+		fh, err := c.FormFile(p.Key)
+		if err != nil {
+			return nil, nil
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+	return values, nil
+}
+
+func toJSON(kind Kind, v string) (json.RawMessage, error) {
+	switch kind {
+	case Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(b)
+	case Int:
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			if _, err = strconv.ParseUint(v, 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		return json.RawMessage(v), nil
+	case Float:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(v), nil
+	case Bytes:
+		return json.Marshal([]byte(v))
+	case JSON:
+		if !json.Valid([]byte(v)) {
+			return nil, errors.New("invalid JSON")
+		}
+		return json.RawMessage(v), nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// Respond writes the response of the Kitex method as JSON with the status 200.
+func Respond(c *app.RequestContext, codec Codec, params []Param, resp interface{}) {
+	if v := reflect.ValueOf(resp); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		c.Data(http.StatusOK, jsonContentType, []byte("{}"))
+		return
+	}
+	data, err := codec.Marshal(resp)
+	if err != nil {
+		Error(c, err)
+		return
+	}
+	if len(params) > 0 {
+		fields := make(map[string]json.RawMessage)
+		if err = json.Unmarshal(data, &fields); err == nil {
+			for _, p := range params {
+				v, ok := fields[p.Field]
+				if !ok {
+					continue
+				}
+				delete(fields, p.Field)
+				if p.In == "header" {
+					c.Response.Header.Set(p.Key, headerValue(v))
+				} else {
+					fields[p.Key] = v
+				}
+			}
+			data, _ = json.Marshal(fields)
+		}
+	}
+	c.Data(http.StatusOK, jsonContentType, data)
+}
+
+func headerValue(v json.RawMessage) string {
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return s
+	}
+	return string(v)
+}
+
+// Error writes the error of the Kitex method: the exceptions of the method are written as their JSON,
+// the biz status errors of Kitex as {"code": ..., "message": ...}, and the other errors as {"message": ...},
+// all of them with the status 500.
+func Error(c *app.RequestContext, err error, exceptions ...interface{}) {
+	for _, target := range exceptions {
+		if errors.As(err, target) {
+			writeJSON(c, http.StatusInternalServerError, reflect.ValueOf(target).Elem().Interface())
+			return
+		}
+	}
+	if bizErr, ok := kerrors.FromBizStatusError(err); ok {
+		status := http.StatusInternalServerError
+		// e.g. the errors of biz/errno
+		if s, ok := bizErr.(interface{ HTTPStatus() int }); ok && s.HTTPStatus() != 0 {
+			status = s.HTTPStatus()
+		}
+		writeJSON(c, status, map[string]interface{}{
+			"code":    bizErr.BizStatusCode(),
+			"message": bizErr.BizMessage(),
+		})
+		return
+	}
+	writeJSON(c, http.StatusInternalServerError, map[string]string{"message": err.Error()})
+}
+
+func writeJSON(c *app.RequestContext, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.Data(http.StatusInternalServerError, jsonContentType, []byte("{}"))
+		return
+	}
+	c.Data(code, jsonContentType, data)
+}
+
+`
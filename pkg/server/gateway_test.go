@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateGateway(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.thrift": `
+namespace go base
+exception BizError { 1: i32 Code }
+`,
+		"hello.thrift": `
+namespace go hello.example
+include "base.thrift"
+
+struct Req {
+    1: required i64 ID (api.path="id");
+    2: list<string> Tags (api.query="tag");
+    3: string Name (api.body="name");
+    4: string Lang;
+}
+struct Resp { 1: string TraceID (api.header="X-Trace-Id"); 2: string Msg }
+
+service hello_service {
+    Resp Get(1: Req req) throws (1: base.BizError err) (api.get="/get/:id");
+    void Post(1: Req req) (api.post="/post/:id");
+    Resp Internal(1: Req req);
+    Resp Args(1: string a, 2: string b) (api.get="/args");
+}
+`,
+		"hello.proto": `
+syntax = "proto3";
+package hello;
+option go_package = "hello/pb";
+message HelloReq { string name = 1; }
+service Greeter { rpc SayHello(HelloReq) returns (HelloReq) { option (api.post) = "/hello"; } }
+`,
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/a"
+	c.OutDir = dir
+	err := generateGateway(c, "example.com/a/kitex_gen", []string{filepath.Join(dir, "hello.thrift"), filepath.Join(dir, "hello.proto")})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, gatewayFile))
+	assert.NoError(t, err)
+	code := string(content)
+	assert.Contains(t, code, `example "example.com/a/kitex_gen/hello/example"`)
+	assert.Contains(t, code, `pb "example.com/a/kitex_gen/hello/pb"`)
+	assert.Contains(t, code, `h.GET("/get/:id", gateway.Handler(gateway.Route{`)
+	assert.Contains(t, code, `{In: "path", Key: "id", Field: "ID", Kind: gateway.Int},`)
+	assert.Contains(t, code, `{In: "query", Key: "tag", Field: "Tags", Kind: gateway.String, List: true},`)
+	assert.Contains(t, code, `{In: "body", Key: "name", Field: "Name", Kind: gateway.String},`)
+	assert.Contains(t, code, `{In: "header", Key: "X-Trace-Id", Field: "TraceID", Kind: gateway.String},`)
+	assert.Contains(t, code, `Exceptions: []interface{}{new(*base.BizError)},`)
+	assert.Contains(t, code, `return nil, helloServiceImpl.Post(ctx, req.(*example.Req))`)
+	assert.Contains(t, code, `return greeterImpl.SayHello(ctx, req.(*pb.HelloReq))`)
+	assert.Contains(t, code, `gateway.Protobuf,`)
+	// the unannotated field is bound from the query of the GET, and from the JSON body of the POST by its name
+	assert.Equal(t, 1, strings.Count(code, `{In: "query", Key: "Lang", Field: "Lang", Kind: gateway.String},`))
+	assert.NotContains(t, code, "Internal")
+	assert.NotContains(t, code, "/args")
+
+	runtime, err := os.ReadFile(filepath.Join(dir, gatewayRuntimeFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(runtime), "var Protobuf = Codec{")
+	assert.NotContains(t, code, "agentrouter")
+
+	// the routes of the eino agent are registered with the gateway
+	c.EnableEino = true
+	assert.NoError(t, generateGateway(c, "example.com/a/kitex_gen", []string{filepath.Join(dir, "hello.thrift")}))
+	content, err = os.ReadFile(filepath.Join(dir, gatewayFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `agentrouter "example.com/a/biz/router/agent"`)
+	assert.Contains(t, string(content), "\n\tagentrouter.Register(h)\n}")
+}
+
+// TestGenerateGatewayService serves the service of --server_name of an IDL declaring several, the
+// one the handler implements.
+func TestGenerateGatewayService(t *testing.T) {
+	dir := t.TempDir()
+	idl := filepath.Join(dir, "hello.thrift")
+	assert.NoError(t, os.WriteFile(idl, []byte(`
+namespace go hello
+struct Req { 1: string Name (api.query="name") }
+service HelloService { Req Hello(1: Req req) (api.get="/hello") }
+service EchoService { Req Echo(1: Req req) (api.get="/echo") }
+`), 0o644))
+
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/a"
+	c.OutDir = dir
+	c.ServerName = "HelloService"
+	assert.NoError(t, generateGateway(c, "example.com/a/kitex_gen", []string{idl}))
+	content, err := os.ReadFile(filepath.Join(dir, gatewayFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "helloServiceImpl.Hello(")
+	assert.NotContains(t, string(content), "/echo")
+
+	// scanServices, which moves and renames the code of the methods, picks the same service
+	c.Type = consts.RPC
+	c.IdlPath = idl
+	services, err := scanServices(c)
+	assert.NoError(t, err)
+	if assert.Len(t, services, 1) {
+		assert.Equal(t, "HelloService", services[0].name)
+	}
+}
+
+func TestHertzPath(t *testing.T) {
+	assert.Equal(t, "/user/:id/files/:path", hertzPath("/user/{id}/files/{path}"))
+	assert.Equal(t, "/ping", hertzPath("/ping"))
+}
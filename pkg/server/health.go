@@ -0,0 +1,242 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const (
+	healthFile       = "biz/health/health.go"
+	healthChecksFile = "biz/health/checks.go"
+)
+
+type healthData struct {
+	Module string
+	Dal    map[string]bool // selected components of --dal with a readiness check
+}
+
+// healthDal are the components of --dal with a readiness check, kafka has none.
+var healthDal = []string{
+	consts.DalMySQL,
+	consts.DalPostgres,
+	consts.DalRedis,
+	consts.DalMongoDB,
+	consts.DalElasticsearch,
+}
+
+// generateHealthChecks generates the readiness state of biz/health shared by the kitex and hertz
+// templates, their skip health.go only serves it. The file is rewritten on every generation so
+// that the checks follow --dal. A health.go generated before it declares the state itself and is
+// reported instead.
+func generateHealthChecks(c *config.ServerArgument) error {
+	if exist, _ := utils.PathExist(filepath.Join(c.OutDir, healthFile)); !exist {
+		return nil
+	}
+	if legacyHealth(c.OutDir) {
+		log.Warnf("%s declares its readiness checks, they do not follow --dal, see the health.go and checks.go of a new project\n", healthFile)
+		return nil
+	}
+	data := &healthData{Module: c.GoMod, Dal: make(map[string]bool)}
+	if m, _, ok := utils.SearchGoMod(c.OutDir, false); ok {
+		data.Module = m
+	}
+	for _, d := range healthDal {
+		if c.HasDal(d) {
+			data.Dal[d] = true
+		}
+	}
+	return writeGoFile(filepath.Join(c.OutDir, healthChecksFile), healthChecksTpl, data)
+}
+
+// legacyHealth reports whether biz/health/health.go declares the readiness state of checks.go.
+func legacyHealth(outDir string) bool {
+	content, err := os.ReadFile(filepath.Join(outDir, healthFile))
+	return err == nil && bytes.Contains(content, []byte("func Ready("))
+}
+
+var healthChecksTpl = `// Code generated by cwgo. DO NOT EDIT.
+
+package health
+
+import (
+	"context"
+	{{- if .Dal.elasticsearch}}
+	"errors"
+	{{- end}}
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"{{if .Dal}}{{"\n"}}{{end}}
+	{{- if .Dal.elasticsearch}}
+	"{{.Module}}/biz/dal/elasticsearch"
+	{{- end}}
+	{{- if .Dal.mongodb}}
+	"{{.Module}}/biz/dal/mongodb"
+	{{- end}}
+	{{- if .Dal.mysql}}
+	"{{.Module}}/biz/dal/mysql"
+	{{- end}}
+	{{- if .Dal.postgres}}
+	"{{.Module}}/biz/dal/postgres"
+	{{- end}}
+	{{- if .Dal.redis}}
+	"{{.Module}}/biz/dal/redis"
+	{{- end}}
+)
+
+// Check reports whether a dependency of the service is usable.
+type Check func(ctx context.Context) error
+
+// checkTimeout bounds every readiness check.
+const checkTimeout = 2 * time.Second
+
+var (
+	ready    atomic.Bool
+	checksMu sync.RWMutex
+	checks   = map[string]Check{
+		{{- if .Dal.mysql}}
+		"mysql": checkMySQL,
+		{{- end}}
+		{{- if .Dal.postgres}}
+		"postgres": checkPostgres,
+		{{- end}}
+		{{- if .Dal.redis}}
+		"redis": checkRedis,
+		{{- end}}
+		{{- if .Dal.mongodb}}
+		"mongodb": checkMongoDB,
+		{{- end}}
+		{{- if .Dal.elasticsearch}}
+		"elasticsearch": checkElasticsearch,
+		{{- end}}
+	}
+)
+
+// Register adds a readiness check, e.g. of a downstream service.
+func Register(name string, check Check) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	checks[name] = check
+}
+
+// SetReady marks the service ready to receive traffic, or draining before shutdown.
+func SetReady(r bool) {
+	ready.Store(r)
+}
+
+// Ready runs the checks and returns the failures by name, the service is ready when
+// it is not draining and no check fails.
+func Ready(ctx context.Context) (bool, map[string]string) {
+	checksMu.RLock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	checksMu.RUnlock()
+	sort.Strings(names)
+
+	failures := make(map[string]string)
+	if !ready.Load() {
+		failures["server"] = "not serving"
+	}
+	for _, name := range names {
+		checksMu.RLock()
+		check := checks[name]
+		checksMu.RUnlock()
+		cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		if err := check(cctx); err != nil {
+			failures[name] = err.Error()
+		}
+		cancel()
+	}
+	return len(failures) == 0, failures
+}
+{{- if .Dal.mysql}}
+
+// checkMySQL pings the database of biz/dal/mysql, skipped until dal.Init is called.
+func checkMySQL(ctx context.Context) error {
+	if mysql.DB == nil {
+		return nil
+	}
+	db, err := mysql.DB.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+{{- end}}
+{{- if .Dal.postgres}}
+
+// checkPostgres pings the database of biz/dal/postgres, skipped until dal.Init is called.
+func checkPostgres(ctx context.Context) error {
+	if postgres.DB == nil {
+		return nil
+	}
+	db, err := postgres.DB.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+{{- end}}
+{{- if .Dal.redis}}
+
+// checkRedis pings the client of biz/dal/redis, skipped until dal.Init is called.
+func checkRedis(ctx context.Context) error {
+	if redis.RedisClient == nil {
+		return nil
+	}
+	return redis.RedisClient.Ping(ctx).Err()
+}
+{{- end}}
+{{- if .Dal.mongodb}}
+
+// checkMongoDB pings the client of biz/dal/mongodb, skipped until dal.Init is called.
+func checkMongoDB(ctx context.Context) error {
+	if mongodb.Client == nil {
+		return nil
+	}
+	return mongodb.Client.Ping(ctx, nil)
+}
+{{- end}}
+{{- if .Dal.elasticsearch}}
+
+// checkElasticsearch pings the client of biz/dal/elasticsearch, skipped until dal.Init is called.
+func checkElasticsearch(ctx context.Context) error {
+	if elasticsearch.Client == nil {
+		return nil
+	}
+	res, err := elasticsearch.Client.Ping(elasticsearch.Client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.New(res.String())
+	}
+	return nil
+}
+{{- end}}
+`
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// healthTestFixture stubs the dal clients pinged by the checks.
+var healthTestFixture = map[string]string{
+	"go.mod": "module example.com/echo\n\ngo 1.18\n",
+	"biz/dal/mysql/init.go": `package mysql
+
+import "database/sql"
+
+type gormDB struct{}
+
+func (*gormDB) DB() (*sql.DB, error) { return nil, nil }
+
+var DB *gormDB
+`,
+	"biz/dal/redis/init.go": `package redis
+
+import "context"
+
+type status struct{}
+
+func (status) Err() error { return nil }
+
+type client struct{}
+
+func (*client) Ping(ctx context.Context) status { return status{} }
+
+var RedisClient *client
+`,
+}
+
+// TestGenerateHealthChecks regenerates the checks of a kitex project with another --dal, the
+// health.go of the project is kept and builds with both.
+func TestGenerateHealthChecks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generated project")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	tpl.RegisterTemplateFunc()
+	dir := t.TempDir()
+	for name, content := range healthTestFixture {
+		writeFixture(t, dir, name, content)
+	}
+	tplDir := t.TempDir()
+	content, err := os.ReadFile(filepath.Join("..", "..", "tpl", "kitex", "server", "standard", "health_tpl.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(tplDir, "health_tpl.yaml"), content, 0o644))
+	renderStrategy(t, tplDir, dir)
+
+	for _, dal := range [][]string{{"mysql", "redis", "kafka"}, {}} {
+		c := config.NewServerArgument()
+		c.GoMod = "example.com/echo"
+		c.OutDir = dir
+		c.Dal = dal
+		assert.NoError(t, generateHealthChecks(c))
+
+		checks, err := os.ReadFile(filepath.Join(dir, healthChecksFile))
+		assert.NoError(t, err)
+		for _, d := range healthDal {
+			assert.Equal(t, c.HasDal(d), strings.Contains(string(checks), `"example.com/echo/biz/dal/`+d+`"`), "%v: %s", dal, d)
+		}
+
+		cmd := exec.Command(goBin, "vet", "./biz/health")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOSUMDB=off", "GOWORK=off")
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, "%v: go vet:\n%s", dal, out)
+	}
+}
+
+func TestGenerateHealthChecksLegacy(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, healthFile, "package health\n\nfunc Ready() {}\n")
+	c := config.NewServerArgument()
+	c.OutDir = dir
+	assert.NoError(t, generateHealthChecks(c))
+	exist, err := os.Stat(filepath.Join(dir, healthChecksFile))
+	assert.Nil(t, exist)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestHealthTemplates checks that the health.go of the templates only serve the state of checks.go.
+func TestHealthTemplates(t *testing.T) {
+	bodies := make(map[string]string)
+	var kitex struct {
+		Body string `yaml:"body"`
+	}
+	content, err := os.ReadFile(filepath.Join("..", "..", "tpl", "kitex", "server", "standard", "health_tpl.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(content, &kitex))
+	bodies["kitex"] = kitex.Body
+	for _, layout := range []string{"standard", "standard_v2"} {
+		content, err := os.ReadFile(filepath.Join("..", "..", "tpl", "hertz", "server", layout, "layout.yaml"))
+		assert.NoError(t, err)
+		for _, line := range strings.SplitAfter(string(content), "\n  - path: ")[1:] {
+			if strings.HasPrefix(line, healthFile+"\n") {
+				bodies[layout] = line
+			}
+		}
+	}
+
+	assert.Len(t, bodies, 3)
+	for name, body := range bodies {
+		for _, decl := range []string{"type Check ", "func Register(", "func SetReady(", "func Ready(", "func check"} {
+			assert.NotContains(t, body, decl, name)
+		}
+		assert.Contains(t, body, "Ready(", name)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cloudwego/cwgo/config"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/templates"
 	"github.com/cloudwego/cwgo/tpl"
 	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
 )
@@ -53,18 +54,10 @@ func convertHzArgument(sa *config.ServerArgument, hzArgument *hzConfig.Argument)
 	}
 
 	if strings.HasSuffix(sa.Template, consts.SuffixGit) {
-		err = utils.GitClone(sa.Template, path.Join(tpl.HertzDir, consts.Server))
+		gitPath, err := templates.Prepare(sa.Template, sa.Branch, sa.OutDir, path.Join(tpl.HertzDir, consts.Server))
 		if err != nil {
 			return err
 		}
-		gitPath, err := utils.GitPath(sa.Template)
-		if err != nil {
-			return err
-		}
-		gitPath = path.Join(tpl.HertzDir, consts.Server, gitPath)
-		if err = utils.GitCheckout(sa.Branch, gitPath); err != nil {
-			return err
-		}
 		hzArgument.CustomizeLayout = path.Join(gitPath, consts.LayoutFile)
 		hzArgument.CustomizePackage = path.Join(gitPath, consts.PackageLayoutFile)
 		layoutDataPath := path.Join(gitPath, "render.json")
@@ -33,6 +33,7 @@ import (
 	"github.com/cloudwego/cwgo/config"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/templates"
 	"github.com/cloudwego/cwgo/tpl"
 	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
@@ -114,18 +115,10 @@ Flags:
 
 	// Non-standard template
 	if strings.HasSuffix(sa.Template, consts.SuffixGit) {
-		err = utils.GitClone(sa.Template, path.Join(tpl.KitexDir, consts.Server))
+		gitPath, err := templates.Prepare(sa.Template, sa.Branch, sa.OutDir, path.Join(tpl.KitexDir, consts.Server))
 		if err != nil {
 			return err
 		}
-		gitPath, err := utils.GitPath(sa.Template)
-		if err != nil {
-			return err
-		}
-		gitPath = path.Join(tpl.KitexDir, consts.Server, gitPath)
-		if err = utils.GitCheckout(sa.Branch, gitPath); err != nil {
-			return err
-		}
 		kitexArgument.TemplateDir = gitPath
 	} else {
 		if len(sa.Template) != 0 {
@@ -241,20 +234,33 @@ func hzArgsForHex(c *config.ServerArgument) (*hzConfig.Argument, error) {
 	return hzArgs, nil
 }
 
+// hexFile serves the HTTP requests on the kitex port, it is generated once and then maintained by hand.
+const hexFile = "hex_trans_handler.go"
+
 func generateHexFile(c *config.ServerArgument) error {
 	tmplContent := `package main
 
 import (
+	{{- if .TLS}}
+	"bufio"
+	{{- end}}
 	"context"
 	"errors"
 	"fmt"
 	"net"
 	"regexp"
+	{{- if .TLS}}
+	"sync"
+	"time"
+	{{- end}}
 
 	"github.com/cloudwego/hertz/pkg/app"
 	hertzServer "github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/utils"
 	"github.com/cloudwego/hertz/pkg/network"
+	{{- if .TLS}}
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	{{- end}}
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/cloudwego/hertz/pkg/route"
 	"github.com/cloudwego/kitex/pkg/endpoint"
@@ -263,7 +269,12 @@ import (
 	"github.com/cloudwego/kitex/pkg/remote/trans/detection"
 	"github.com/cloudwego/kitex/pkg/remote/trans/netpoll"
 	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2"
+	{{- if .Health}}
+	"{{$.ProjPackage}}/biz/health"
+	{{- end}}
+	{{- if not .Gateway}}
 	"{{$.ProjPackage}}/biz/router"
+	{{- end}}
 )
 
 type mixTransHandlerFactory struct {
@@ -315,11 +326,7 @@ func (t *transHandler) OnRead(ctx context.Context, conn net.Conn) error {
 
 func initHertz() *route.Engine {
 	h := hertzServer.New(hertzServer.WithIdleTimeout(0))
-	// add a ping route to test
-	h.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
-		ctx.JSON(consts.StatusOK, utils.H{"ping": "pong"})
-	})
-	router.GeneratedRegister(h)
+	registerRoutes(h)
 	if err := h.Engine.Init(); err != nil {
 		panic(err)
 	}
@@ -329,27 +336,162 @@ func initHertz() *route.Engine {
 	return h.Engine
 }
 
+func registerRoutes(h *hertzServer.Hertz) {
+	// add a ping route to test
+	h.GET("/ping", func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(consts.StatusOK, utils.H{"ping": "pong"})
+	})
+	{{- if .Health}}
+	// liveness and readiness probes on the service port
+	h.GET("/healthz", func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(consts.StatusOK, utils.H{"status": "ok"})
+	})
+	h.GET("/readyz", func(c context.Context, ctx *app.RequestContext) {
+		if ok, failures := health.Ready(c); !ok {
+			ctx.JSON(consts.StatusServiceUnavailable, utils.H{"status": "not ready", "failures": failures})
+			return
+		}
+		ctx.JSON(consts.StatusOK, utils.H{"status": "ok"})
+	})
+	{{- end}}
+	{{- if .Gateway}}
+	registerGateway(h)
+	{{- else}}
+	router.GeneratedRegister(h)
+	{{- end}}
+}
+
 var hertzEngine *route.Engine
 
 func init() {
 	hertzEngine = initHertz()
 }
+{{- if .TLS}}
+
+// serveHex splits the connections of ln between kitex and hertz by their first bytes, which are
+// decrypted when ln is a TLS listener. It returns the listener of the kitex connections.
+func serveHex(ln net.Listener) net.Listener {
+	kitexLn, httpLn := newChanListener(ln), newChanListener(ln)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				kitexLn.Close()
+				httpLn.Close()
+				return
+			}
+			go dispatchHex(conn, kitexLn, httpLn)
+		}
+	}()
+
+	// hertz serves net.Conn with its standard transport
+	h := hertzServer.New(
+		hertzServer.WithListener(httpLn),
+		hertzServer.WithTransport(standard.NewTransporter),
+		hertzServer.WithIdleTimeout(0),
+	)
+	registerRoutes(h)
+	go func() {
+		if err := h.Run(); err != nil {
+			klog.Errorf("HERTZ: %s", err.Error())
+		}
+	}()
+	return kitexLn
+}
+
+// dispatchHex sends the connection to hertz if it starts with an HTTP method, to kitex otherwise.
+func dispatchHex(conn net.Conn, kitexLn, httpLn *chanListener) {
+	pc := &peekedConn{Conn: conn, r: bufio.NewReader(conn)}
+	// the first read does the TLS handshake
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	pre, err := pc.r.Peek(4)
+	_ = conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+	if httpReg.Match(pre) {
+		httpLn.push(pc)
+	} else {
+		kitexLn.push(pc)
+	}
+}
+
+// peekedConn reads the bytes peeked by dispatchHex before the rest of the connection.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// chanListener accepts the connections dispatched to kitex or hertz, closing it closes the shared listener.
+type chanListener struct {
+	ln     net.Listener
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newChanListener(ln net.Listener) *chanListener {
+	return &chanListener{ln: ln, conns: make(chan net.Conn), closed: make(chan struct{})}
+}
+
+func (l *chanListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *chanListener) push(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		conn.Close()
+	}
+}
+
+func (l *chanListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	if err := l.ln.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+func (l *chanListener) Addr() net.Addr {
+	return l.ln.Addr()
+}
+{{- end}}
 `
-	exist, err := utils.PathExist("hex_trans_handler.go")
+	exist, err := utils.PathExist(hexFile)
 	if err != nil {
 		return err
 	}
 	if exist {
 		return nil
 	}
+	// the probes are served by the biz/health package of the standard template
+	health, err := utils.PathExist(filepath.Join("biz", "health"))
+	if err != nil {
+		return err
+	}
 	tmpl := template.Must(template.New("hex_trans_handler").Parse(tmplContent))
-	file, err := os.Create("hex_trans_handler.go")
+	file, err := os.Create(hexFile)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	return tmpl.Execute(file, map[string]string{
+	return tmpl.Execute(file, map[string]interface{}{
 		"ProjPackage": c.GoMod,
+		"Health":      health,
+		"Gateway":     c.Gateway,
+		"TLS":         c.TLS,
 	})
 }
 
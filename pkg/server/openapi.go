@@ -0,0 +1,50 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/openapi"
+)
+
+// generateOpenAPI describes the HTTP routes of the IDLs in openapi.yaml of the output directory,
+// the document is rewritten on every generation so that it follows the IDL.
+func generateOpenAPI(c *config.ServerArgument) error {
+	paths, err := utils.ExpandIDLPaths(c.IdlPath)
+	if err != nil {
+		return err
+	}
+	idls := make([]*idlparser.IDL, 0, len(paths))
+	for _, p := range paths {
+		idl, err := idlparser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return err
+		}
+		idls = append(idls, idl)
+	}
+
+	doc := openapi.Generate(c.ServerName, idls)
+	if err = doc.WriteFile(filepath.Join(c.OutDir, openapi.FileName)); err != nil {
+		return fmt.Errorf("write %s failed: %w", openapi.FileName, err)
+	}
+	return nil
+}
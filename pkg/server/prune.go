@@ -0,0 +1,847 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/meta"
+	hzutil "github.com/cloudwego/hertz/cmd/hz/util"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+	"github.com/cloudwego/kitex/tool/internal_pkg/util"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const defaultHzHandlerDir = "biz/handler"
+
+// serviceCode is a service of the IDL and the code generated for its methods in the project.
+type serviceCode struct {
+	name    string
+	module  string
+	methods map[string]bool // methods declared by the IDL
+	code    map[string]*methodCode
+	snake   func(string) string // file names of the methods, as the templates write them
+}
+
+// methodCode is the code generated for a method.
+type methodCode struct {
+	name  string
+	run   string    // parameter and result types of the Run method of its service, to detect renames
+	files []string  // files of the method only, relative to the project
+	decls []declRef // code of the method in the files shared by the methods of the service
+}
+
+// declRef is a function, or a t.Run subtest, of a method in a shared file.
+type declRef struct {
+	file    string
+	name    string
+	recv    string // receiver type of a method
+	subtest bool
+}
+
+func (s *serviceCode) method(name string) *methodCode {
+	m, ok := s.code[name]
+	if !ok {
+		m = &methodCode{name: name}
+		s.code[name] = m
+	}
+	return m
+}
+
+// scanServices finds the code generated for the services of the IDL in the output directory.
+func scanServices(c *config.ServerArgument) ([]*serviceCode, error) {
+	idls, err := utils.ExpandIDLPaths(c.IdlPath)
+	if err != nil {
+		return nil, err
+	}
+	module := c.GoMod
+	if m, _, ok := utils.SearchGoMod(c.OutDir, false); ok {
+		module = m
+	}
+
+	var services []*serviceCode
+	for _, p := range idls {
+		idl, err := idlparser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return nil, err
+		}
+		svcs := idl.Services
+		if c.Type == consts.RPC {
+			svcs = nil
+			if svc := kitexService(idl, c.ServerName); svc != nil {
+				svcs = append(svcs, svc)
+			}
+		}
+		for _, svc := range svcs {
+			s := &serviceCode{
+				name:    svc.Name,
+				module:  module,
+				methods: make(map[string]bool),
+				code:    make(map[string]*methodCode),
+			}
+			for _, m := range svc.Methods {
+				s.methods[m.Name] = true
+			}
+			if c.Type == consts.RPC {
+				s.snake = util.SnakeString
+				s.scanKitex(c.OutDir)
+			} else {
+				s.snake = hzutil.ToSnakeCase
+				s.scanHz(c.OutDir, module, idl)
+			}
+			services = append(services, s)
+		}
+	}
+	return services, nil
+}
+
+// kitexService returns the service the kitex handler of the IDL implements: its only service, or
+// the one of --server_name when it declares several, as the server is generated. Nil if none.
+func kitexService(idl *idlparser.IDL, serverName string) *idlparser.Service {
+	if len(idl.Services) == 1 {
+		return idl.Services[0]
+	}
+	for _, svc := range idl.Services {
+		if svc.Name == serverName {
+			return svc
+		}
+	}
+	return nil
+}
+
+var (
+	newServiceFunc   = regexp.MustCompile(`^New(\w+)Service$`)
+	initStrategyFunc = regexp.MustCompile(`^Init(\w+)Strategies$`)
+)
+
+// scanKitex scans the layout of the kitex standard template: a service and a strategy file
+// for each method, and the methods of the handler and the subtests of the integration test.
+func (s *serviceCode) scanKitex(dir string) {
+	pkg := s.snake(s.name)
+	for _, f := range parseGoFiles(dir, filepath.Join("biz", "service", pkg)) {
+		if strings.HasSuffix(f.rel, "_test.go") {
+			continue
+		}
+		for _, name := range funcNames(f.file, newServiceFunc) {
+			m := s.method(name)
+			m.files = append(m.files, f.rel)
+			if test := strings.TrimSuffix(f.rel, ".go") + "_test.go"; fileExists(filepath.Join(dir, test)) {
+				m.files = append(m.files, test)
+			}
+			m.run = runSignature(f.file, name+"Service")
+		}
+	}
+	for _, f := range parseGoFiles(dir, filepath.Join("biz", "strategy", pkg+"_strategy")) {
+		names := funcNames(f.file, initStrategyFunc)
+		for _, name := range names {
+			m := s.method(name)
+			if len(names) == 1 {
+				m.files = append(m.files, f.rel)
+			} else {
+				m.decls = append(m.decls, declRef{file: f.rel, name: "Init" + name + "Strategies"})
+			}
+		}
+	}
+	if f := parseGoFile(dir, "handler.go"); f != nil {
+		for _, decl := range f.file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && recvType(fn) == s.name+"Impl" {
+				m := s.method(fn.Name.Name)
+				m.decls = append(m.decls, declRef{file: f.rel, name: fn.Name.Name, recv: s.name + "Impl"})
+			}
+		}
+	}
+	if f := parseGoFile(dir, "integration_test.go"); f != nil {
+		for _, name := range subtests(f.file, "Test"+s.name+"Integration") {
+			if m, ok := s.code[name]; ok {
+				m.decls = append(m.decls, declRef{file: f.rel, name: name, subtest: true})
+			}
+		}
+	}
+}
+
+// scanHz scans the handlers of the service generated by hz, and the service file and the test of each of them.
+func (s *serviceCode) scanHz(dir, module string, idl *idlparser.IDL) {
+	handlerDir := defaultHzHandlerDir
+	manifest := new(meta.Manifest)
+	if err := manifest.InitAndValidate(dir); err == nil && manifest.HandlerDir != "" {
+		handlerDir = manifest.HandlerDir
+	}
+	f := findHzHandler(dir, handlerDir, idlPackage(idl), s.snake(s.name)+".go")
+	if f == nil {
+		return
+	}
+	for _, decl := range f.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Doc == nil || !strings.Contains(fn.Doc.Text(), "@router") {
+			continue
+		}
+		m := s.method(fn.Name.Name)
+		m.decls = append(m.decls, declRef{file: f.rel, name: fn.Name.Name})
+		if svc := serviceFile(dir, module, f.file, fn); svc != nil {
+			m.files = append(m.files, svc.rel)
+			m.run = runSignature(svc.file, fn.Name.Name+"Service")
+		}
+	}
+	if test := parseGoFile(dir, strings.TrimSuffix(f.rel, ".go")+"_test.go"); test != nil {
+		for _, decl := range test.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+				continue
+			}
+			if m, ok := s.code[strings.TrimPrefix(fn.Name.Name, "Test")]; ok {
+				m.decls = append(m.decls, declRef{file: test.rel, name: fn.Name.Name})
+			}
+		}
+	}
+}
+
+// idlPackage is the path of the packages hz generates for the IDL under the handler dir.
+func idlPackage(idl *idlparser.IDL) string {
+	if idl.Type == consts.Thrift {
+		return strings.ReplaceAll(idl.GoPackage, ".", "/")
+	}
+	pkg, _, _ := strings.Cut(idl.GoPackage, ";")
+	return pkg
+}
+
+// findHzHandler finds the handler file of the service, in the package of the IDL if several services share its name.
+func findHzHandler(dir, handlerDir, pkg, name string) *goFile {
+	var candidates []string
+	root := filepath.Join(dir, handlerDir)
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && d.Name() == name {
+			rel, _ := filepath.Rel(root, filepath.Dir(p))
+			candidates = append(candidates, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	var found string
+	for _, c := range candidates {
+		if c == pkg || strings.HasSuffix(pkg, "/"+c) {
+			found = c
+			break
+		}
+		if path.Base(c) == path.Base(pkg) {
+			found = c
+		}
+	}
+	if found == "" && len(candidates) == 1 {
+		found = candidates[0]
+	}
+	if found == "" {
+		return nil
+	}
+	return parseGoFile(dir, filepath.Join(handlerDir, filepath.FromSlash(found), name))
+}
+
+// serviceFile finds the file of the New<Handler>Service called by the handler, in the packages of the project.
+func serviceFile(dir, module string, f *ast.File, fn *ast.FuncDecl) *goFile {
+	var pkgDir string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "New"+fn.Name.Name+"Service" {
+			return pkgDir == ""
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			for _, imp := range f.Imports {
+				p, _ := strconv.Unquote(imp.Path.Value)
+				name := path.Base(p)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				if name == x.Name && strings.HasPrefix(p, module+"/") {
+					pkgDir = filepath.FromSlash(strings.TrimPrefix(p, module+"/"))
+				}
+			}
+		}
+		return false
+	})
+	if pkgDir == "" {
+		return nil
+	}
+	for _, sf := range parseGoFiles(dir, pkgDir) {
+		if strings.HasSuffix(sf.rel, "_test.go") {
+			continue
+		}
+		for _, name := range funcNames(sf.file, newServiceFunc) {
+			if name == fn.Name.Name {
+				return sf
+			}
+		}
+	}
+	return nil
+}
+
+type goFile struct {
+	rel  string // relative to the project
+	file *ast.File
+}
+
+// parseGoFile parses a file of the project, nil if it doesn't exist or doesn't parse.
+func parseGoFile(dir, rel string) *goFile {
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, rel), nil, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	return &goFile{rel: rel, file: f}
+}
+
+// parseGoFiles parses the go files of a directory of the project.
+func parseGoFiles(dir, pkgDir string) []*goFile {
+	paths, _ := filepath.Glob(filepath.Join(dir, pkgDir, "*.go"))
+	var files []*goFile
+	for _, p := range paths {
+		if f := parseGoFile(dir, filepath.Join(pkgDir, filepath.Base(p))); f != nil {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// funcNames returns the first submatch of the names of the functions matching re.
+func funcNames(f *ast.File, re *regexp.Regexp) []string {
+	var names []string
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			if m := re.FindStringSubmatch(fn.Name.Name); m != nil {
+				names = append(names, m[len(m)-1])
+			}
+		}
+	}
+	return names
+}
+
+func recvType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	t := fn.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// runSignature returns the parameter and the result types of the Run method of the type.
+func runSignature(f *ast.File, typeName string) string {
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "Run" || recvType(fn) != typeName {
+			continue
+		}
+		return "(" + fieldTypes(fn.Type.Params) + ") (" + fieldTypes(fn.Type.Results) + ")"
+	}
+	return ""
+}
+
+func fieldTypes(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var ts []string
+	for _, f := range fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			ts = append(ts, types.ExprString(f.Type))
+		}
+	}
+	return strings.Join(ts, ", ")
+}
+
+// subtests returns the names of the t.Run subtests of the test function.
+func subtests(f *ast.File, test string) []string {
+	var names []string
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == test && fn.Body != nil {
+			for _, stmt := range fn.Body.List {
+				if name, ok := subtestName(stmt); ok {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+func subtestName(stmt ast.Stmt) (string, bool) {
+	es, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return "", false
+	}
+	call, ok := es.X.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Run" {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	name, err := strconv.Unquote(lit.Value)
+	return name, err == nil
+}
+
+// pruneMethods reports the code of the methods removed from the IDL, and moves or deletes it with --prune.
+// A removed method whose Run signature matches the one of a single method added by this generation
+// is taken as renamed, --prune moves its implementation to the added method.
+func pruneMethods(c *config.ServerArgument, before, after []*serviceCode) error {
+	generated := make(map[string]map[string]*methodCode)
+	for _, s := range before {
+		generated[s.name] = s.code
+	}
+	pending, err := loadRenames(c.OutDir)
+	if err != nil {
+		return err
+	}
+	var unapplied []rename
+	for _, s := range after {
+		var removed, added []string
+		for name := range s.code {
+			if !s.methods[name] {
+				removed = append(removed, name)
+			}
+		}
+		for name := range s.methods {
+			if _, ok := generated[s.name][name]; !ok && s.code[name] != nil {
+				added = append(added, name)
+			}
+		}
+		sort.Strings(removed)
+		renames := detectRenames(s, removed, added)
+		// the renames detected by a previous generation without --prune
+		for _, r := range pending {
+			if from, to := s.code[r.from], s.code[r.to]; r.service == s.name && from != nil && to != nil &&
+				!s.methods[r.from] && s.methods[r.to] && from.run == to.run {
+				renames[r.from] = r.to
+			}
+		}
+
+		for _, name := range removed {
+			m := s.code[name]
+			to, renamed := renames[name]
+			switch {
+			case c.Prune == "" && renamed:
+				unapplied = append(unapplied, rename{service: s.name, from: name, to: to})
+				log.Warnf("%s.%s is removed from the IDL, it looks renamed to %s: use --prune to move its implementation\n", s.name, name, to)
+			case c.Prune == "":
+				log.Warnf("%s.%s is removed from the IDL, its code is left in %s: use --prune %s or --prune %s to clean it up\n",
+					s.name, name, strings.Join(m.locations(), ", "), consts.PruneMove, consts.PruneDelete)
+			case renamed:
+				if err = renameMethod(c, s, m, s.code[to]); err != nil {
+					return err
+				}
+				log.Warnf("%s.%s is renamed to %s, its implementation is moved\n", s.name, name, to)
+			default:
+				if err = pruneMethod(c, m.files, m.decls); err != nil {
+					return err
+				}
+				if c.Prune == consts.PruneMove {
+					log.Warnf("%s.%s is removed from the IDL, its code is moved to %s\n", s.name, name, consts.OrphanedDir)
+				} else {
+					log.Warnf("%s.%s is removed from the IDL, its code is deleted\n", s.name, name)
+				}
+			}
+		}
+	}
+	return saveRenames(c.OutDir, unapplied)
+}
+
+// rename is a rename detected by a generation without --prune, applied by the next one with it.
+type rename struct {
+	service, from, to string
+}
+
+func renamesFile(dir string) string {
+	return filepath.Join(dir, consts.CwgoStateDir, "renames")
+}
+
+// loadRenames reads the pending renames, one "<service> <from> <to>" line each.
+func loadRenames(dir string) ([]rename, error) {
+	data, err := os.ReadFile(renamesFile(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var renames []rename
+	for _, line := range strings.Split(string(data), "\n") {
+		if f := strings.Fields(line); len(f) == 3 {
+			renames = append(renames, rename{service: f[0], from: f[1], to: f[2]})
+		}
+	}
+	return renames, nil
+}
+
+func saveRenames(dir string, renames []rename) error {
+	p := renamesFile(dir)
+	if len(renames) == 0 {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	var b strings.Builder
+	for _, r := range renames {
+		fmt.Fprintf(&b, "%s %s %s\n", r.service, r.from, r.to)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, []byte(b.String()), 0o644)
+}
+
+// detectRenames pairs the removed and the added methods with the same Run signature, when the pair is the only match.
+func detectRenames(s *serviceCode, removed, added []string) map[string]string {
+	bySignature := func(names []string) map[string][]string {
+		r := make(map[string][]string)
+		for _, name := range names {
+			if run := s.code[name].run; run != "" {
+				r[run] = append(r[run], name)
+			}
+		}
+		return r
+	}
+	from, to := bySignature(removed), bySignature(added)
+	renames := make(map[string]string)
+	for run, names := range from {
+		if len(names) == 1 && len(to[run]) == 1 {
+			renames[names[0]] = to[run][0]
+		}
+	}
+	return renames
+}
+
+func (m *methodCode) locations() []string {
+	locs := append([]string(nil), m.files...)
+	for _, d := range m.decls {
+		if !contains(locs, d.file) {
+			locs = append(locs, d.file)
+		}
+	}
+	return locs
+}
+
+// renameMethod moves the implementation of a renamed method into the files generated for its new name.
+func renameMethod(c *config.ServerArgument, s *serviceCode, from, to *methodCode) error {
+	fromSnake, toSnake := s.snake(from.name), s.snake(to.name)
+	var orphans []string
+	for _, f := range from.files {
+		base := filepath.Base(f)
+		if !strings.HasPrefix(base, fromSnake) {
+			orphans = append(orphans, f)
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(c.OutDir, f))
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(filepath.Dir(f), toSnake+strings.TrimPrefix(base, fromSnake))
+		if err = os.WriteFile(filepath.Join(c.OutDir, target), renameSource(src, s.module, from.name, to.name, fromSnake, toSnake), 0o644); err != nil {
+			return err
+		}
+		if err = os.Remove(filepath.Join(c.OutDir, f)); err != nil {
+			return err
+		}
+	}
+	// the shared code of the new name is generated already
+	return pruneMethod(c, orphans, from.decls)
+}
+
+// pruneMethod moves the files and the declarations of a method to the orphaned dir, or deletes them.
+func pruneMethod(c *config.ServerArgument, files []string, decls []declRef) error {
+	for _, f := range files {
+		var err error
+		if c.Prune == consts.PruneMove {
+			dst := filepath.Join(c.OutDir, consts.OrphanedDir, f)
+			if err = os.MkdirAll(filepath.Dir(dst), 0o755); err == nil {
+				err = os.Rename(filepath.Join(c.OutDir, f), dst)
+			}
+		} else {
+			err = os.Remove(filepath.Join(c.OutDir, f))
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	byFile := make(map[string][]declRef)
+	for _, d := range decls {
+		byFile[d.file] = append(byFile[d.file], d)
+	}
+	for file, refs := range byFile {
+		p := filepath.Join(c.OutDir, file)
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		out, removed, err := removeDecls(src, refs)
+		if err != nil {
+			return fmt.Errorf("remove the code of the removed methods from %s failed: %w", file, err)
+		}
+		if c.Prune == consts.PruneMove && len(removed) > 0 {
+			if err = appendOrphaned(filepath.Join(c.OutDir, consts.OrphanedDir, file), src, removed); err != nil {
+				return err
+			}
+		}
+		if err = os.WriteFile(p, out, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendOrphaned appends the code removed from src to an orphaned file, created with the package clause of src.
+func appendOrphaned(p string, src, code []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		if f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly); err == nil {
+			data = []byte("package " + f.Name.Name + "\n\n")
+		}
+	} else if err != nil {
+		return err
+	}
+	data = append(data, code...)
+	if formatted, err := format.Source(data); err == nil {
+		data = formatted
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// removeDecls cuts the declarations out of the source and drops the imports left unused.
+// It returns the new source and the code removed.
+func removeDecls(src []byte, refs []declRef) ([]byte, []byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	type span struct {
+		start, end int
+		test       string // test function of a subtest
+	}
+	var spans []span
+	add := func(start, end token.Pos, test string) {
+		s, e := fset.Position(start).Offset, fset.Position(end).Offset
+		// whole lines, the indentation and the line break included
+		for s > 0 && (src[s-1] == ' ' || src[s-1] == '\t') {
+			s--
+		}
+		if e < len(src) && src[e] == '\n' {
+			e++
+		}
+		spans = append(spans, span{s, e, test})
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		for _, r := range refs {
+			switch {
+			case r.subtest && fn.Body != nil:
+				for _, stmt := range fn.Body.List {
+					if name, ok := subtestName(stmt); ok && name == r.name {
+						add(stmt.Pos(), stmt.End(), fn.Name.Name)
+					}
+				}
+			case !r.subtest && fn.Name.Name == r.name && recvType(fn) == r.recv:
+				start := fn.Pos()
+				if fn.Doc != nil {
+					start = fn.Doc.Pos()
+				}
+				add(start, fn.End(), "")
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return src, nil, nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var out, removed bytes.Buffer
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		out.Write(src[last:s.start])
+		if s.test != "" {
+			// keep the subtest in its test function so that the removed code still parses
+			fmt.Fprintf(&removed, "func %s(t *testing.T) {\n%s}\n\n", s.test, src[s.start:s.end])
+		} else {
+			removed.Write(src[s.start:s.end])
+			removed.WriteByte('\n')
+		}
+		last = s.end
+	}
+	out.Write(src[last:])
+
+	f, err = parser.ParseFile(fset, "", out.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unused []*ast.ImportSpec
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil && (imp.Name.Name == "_" || imp.Name.Name == ".") {
+			continue
+		}
+		if !astutil.UsesImport(f, p) {
+			unused = append(unused, imp)
+		}
+	}
+	for _, imp := range unused {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := ""
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		astutil.DeleteNamedImport(fset, f, name, p)
+	}
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, f); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), removed.Bytes(), nil
+}
+
+var word = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// methodIdents are the identifiers the templates generate for a method, %s being its name.
+var methodIdents = []string{
+	"New%sService", "%sService", "%sState", "%sHandler", "%sProcessor",
+	"%sStrategies", "Choose%sStrategy", "Init%sStrategies",
+	// the tests of the services
+	"Test%s", "Test%sState", "new%sReq", "new%sInvalidReq", "new%sState", "install%sStrategies",
+}
+
+// renameSource renames the identifiers generated for a method, e.g. NewByeService or ByeState,
+// and the method in the comments and the strings, e.g. "bye" of the strategy config. The types of
+// the IDL keep their names, e.g. ByeReq, as do the identifiers of the packages outside the biz dir
+// of the project, e.g. the kitex_gen or hz models.
+func renameSource(src []byte, module, from, to, fromSnake, toSnake string) []byte {
+	foreign := make(map[string]bool) // names of the imports not generated for the methods
+	if f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly); err == nil {
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			name := path.Base(p)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			foreign[name] = !strings.HasPrefix(p, module+"/biz/") || strings.HasPrefix(p, module+"/biz/model/")
+		}
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	var out bytes.Buffer
+	last := 0
+	var prev [2]string // the previous tokens, to find the identifiers qualified by a package
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		var renamed string
+		switch tok {
+		case token.IDENT:
+			renamed = lit
+			if !(prev[1] == "." && foreign[prev[0]]) {
+				renamed = renameIdent(lit, from, to)
+			}
+		case token.STRING, token.COMMENT:
+			renamed = word.ReplaceAllStringFunc(lit, func(w string) string {
+				switch w {
+				case fromSnake:
+					return toSnake
+				case from:
+					return to
+				}
+				return renameIdent(w, from, to)
+			})
+		}
+		if tok != token.COMMENT {
+			prev[0], prev[1] = prev[1], lit
+			if lit == "" {
+				prev[1] = tok.String()
+			}
+		}
+		if renamed == "" || renamed == lit {
+			continue
+		}
+		off := file.Offset(pos)
+		out.Write(src[last:off])
+		out.WriteString(renamed)
+		last = off + len(lit)
+	}
+	out.Write(src[last:])
+	if formatted, err := format.Source(out.Bytes()); err == nil {
+		return formatted
+	}
+	return out.Bytes()
+}
+
+// renameIdent renames an identifier generated for the method from, e.g. NewByeService or ByeState.
+// Other identifiers are kept, such as the request types of the IDL or the Run method of the services.
+func renameIdent(id, from, to string) string {
+	for _, f := range methodIdents {
+		if id == fmt.Sprintf(f, from) {
+			return fmt.Sprintf(f, to)
+		}
+	}
+	return id
+}
+
+func fileExists(p string) bool {
+	ok, _ := utils.PathExist(p)
+	return ok
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameIdent(t *testing.T) {
+	assert.Equal(t, "NewFarewellService", renameIdent("NewByeService", "Bye", "Farewell"))
+	assert.Equal(t, "FarewellState", renameIdent("ByeState", "Bye", "Farewell"))
+	assert.Equal(t, "ChooseFarewellStrategy", renameIdent("ChooseByeStrategy", "Bye", "Farewell"))
+	assert.Equal(t, "Byes", renameIdent("Byes", "Bye", "Farewell"))
+	assert.Equal(t, "ByeReq", renameIdent("ByeReq", "Bye", "Farewell"))
+	assert.Equal(t, "Run", renameIdent("Run", "Run", "Exec"))
+	assert.Equal(t, "ExecService", renameIdent("RunService", "Run", "Exec"))
+}
+
+func TestRenameSource(t *testing.T) {
+	src := `package svc
+
+// RunService runs Run.
+type RunService struct{}
+
+func NewRunService() *RunService { return &RunService{} }
+
+func (s *RunService) Run(req string) (string, error) {
+	return "run: " + req, nil // Runner is not renamed
+}
+`
+	want := `package svc
+
+// ExecService runs Exec.
+type ExecService struct{}
+
+func NewExecService() *ExecService { return &ExecService{} }
+
+func (s *ExecService) Run(req string) (string, error) {
+	return "exec: " + req, nil // Runner is not renamed
+}
+`
+	assert.Equal(t, want, string(renameSource([]byte(src), "example.com/app", "Run", "Exec", "run", "exec")))
+}
+
+func TestRenameSourceKeepsTypes(t *testing.T) {
+	src := `package user
+
+import (
+	"context"
+
+	"example.com/app/biz/model/account"
+	"example.com/app/biz/service/user"
+	"example.com/app/kitex_gen/api"
+)
+
+// LoginService serves Login with a LoginReq.
+type LoginService struct {
+	ctx context.Context
+}
+
+func NewLoginService(ctx context.Context) *LoginService { return &LoginService{ctx: ctx} }
+
+func (s *LoginService) Run(req *api.LoginReq) (resp *api.LoginResp, err error) {
+	_ = user.NewLoginService
+	_ = account.LoginState{}
+	_ = api.LoginService{}
+	return
+}
+`
+	want := `package user
+
+import (
+	"context"
+
+	"example.com/app/biz/model/account"
+	"example.com/app/biz/service/user"
+	"example.com/app/kitex_gen/api"
+)
+
+// SignInService serves SignIn with a LoginReq.
+type SignInService struct {
+	ctx context.Context
+}
+
+func NewSignInService(ctx context.Context) *SignInService { return &SignInService{ctx: ctx} }
+
+func (s *SignInService) Run(req *api.LoginReq) (resp *api.LoginResp, err error) {
+	_ = user.NewSignInService
+	_ = account.LoginState{}
+	_ = api.LoginService{}
+	return
+}
+`
+	assert.Equal(t, want, string(renameSource([]byte(src), "example.com/app", "Login", "SignIn", "login", "sign_in")))
+}
+
+func TestRemoveDecls(t *testing.T) {
+	src := `package main
+
+import (
+	"context"
+	"testing"
+
+	bye "example.com/a/biz/service/bye"
+	hello "example.com/a/biz/service/hello"
+)
+
+type Impl struct{}
+
+// Bye says bye.
+func (s *Impl) Bye(ctx context.Context) error {
+	return bye.Run(ctx)
+}
+
+// Hello says hello.
+func (s *Impl) Hello(ctx context.Context) error {
+	return hello.Run(ctx)
+}
+
+func TestImpl(t *testing.T) {
+	s := new(Impl)
+
+	t.Run("Bye", func(t *testing.T) {
+		_ = s.Bye(context.Background())
+	})
+
+	t.Run("Hello", func(t *testing.T) {
+		_ = s.Hello(context.Background())
+	})
+}
+`
+	out, removed, err := removeDecls([]byte(src), []declRef{
+		{name: "Bye", recv: "Impl"},
+		{name: "Bye", subtest: true},
+		{name: "Hello"}, // a function, not the method
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `package main
+
+import (
+	"context"
+	"testing"
+
+	hello "example.com/a/biz/service/hello"
+)
+
+type Impl struct{}
+
+// Hello says hello.
+func (s *Impl) Hello(ctx context.Context) error {
+	return hello.Run(ctx)
+}
+
+func TestImpl(t *testing.T) {
+	s := new(Impl)
+
+	t.Run("Hello", func(t *testing.T) {
+		_ = s.Hello(context.Background())
+	})
+}
+`, string(out))
+	assert.Equal(t, `// Bye says bye.
+func (s *Impl) Bye(ctx context.Context) error {
+	return bye.Run(ctx)
+}
+
+func TestImpl(t *testing.T) {
+	t.Run("Bye", func(t *testing.T) {
+		_ = s.Bye(context.Background())
+	})
+}
+
+`, string(removed))
+}
+
+func TestDetectRenames(t *testing.T) {
+	s := &serviceCode{code: map[string]*methodCode{
+		"Bye":      {run: "(*Req) (*Resp, error)"},
+		"Farewell": {run: "(*Req) (*Resp, error)"},
+		"Get":      {run: "(*GetReq) (*Resp, error)"},
+		"List":     {run: "(*ListReq) (*Resp, error)"},
+		"Find":     {run: "(*ListReq) (*Resp, error)"},
+		"Search":   {run: "(*ListReq) (*Resp, error)"},
+	}}
+	// Get has no match, List matches two added methods
+	assert.Equal(t, map[string]string{"Bye": "Farewell"},
+		detectRenames(s, []string{"Bye", "Get", "List"}, []string{"Farewell", "Find", "Search"}))
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+// strategyTemplates are the templates of tpl/kitex/server/standard generating the strategy layer.
+var strategyTemplates = []string{
+	"processor_common_logger.yaml",
+	"processor_instrument.yaml",
+	"processor_types.yaml",
+	"processor_validate.yaml",
+	"processor_vars.yaml",
+	"service.yaml",
+	"service_test.yaml",
+	"strategy.yaml",
+	"strategy_builder.yaml",
+	"strategy_config_loader.yaml",
+	"strategy_init_tpl.yaml",
+	"strategy_pipeline.yaml",
+	"strategy_policy.yaml",
+	"strategy_registry.yaml",
+	"strategy_selector.yaml",
+	"strategy_service_config.yaml",
+	"strategy_service_yaml.yaml",
+}
+
+// legacyFiles are the skip files of a project generated before selectors, policies and validation,
+// reduced to their declarations.
+var legacyFiles = map[string]string{
+	"biz/service/echo/echo.go": `package echo
+
+import (
+	"context"
+
+	"example.com/echo/biz/processor"
+	"example.com/echo/biz/strategy"
+	"example.com/echo/kitex_gen/api"
+)
+
+type EchoHandler func(ctx context.Context, req *api.EchoReq) (*api.EchoResp, error)
+
+type EchoState struct {
+	Ctx  context.Context
+	Req  *api.EchoReq
+	Resp *api.EchoResp
+	Vars map[string]any
+}
+
+type EchoProcessor = processor.Processor[EchoState]
+
+var EchoStrategies = strategy.NewRegistry[EchoHandler]()
+
+var ChooseEchoStrategy = func(ctx context.Context, req *api.EchoReq) string {
+	return "default" // chosen by hand
+}
+
+type EchoService struct {
+	ctx context.Context
+}
+
+func NewEchoService(ctx context.Context) *EchoService {
+	return &EchoService{ctx: ctx}
+}
+`,
+	"conf/conf.go": `package conf
+
+func GetEnv() string { return "test" }
+`,
+	"biz/strategy/echo_strategy/builder.go": `package echo_strategy
+
+// BuildAndRegisterStrategies assembles the pipelines without strategy.BuildPipeline.
+func BuildAndRegisterStrategies() {}
+
+// BuildAndRegisterVoidStrategies assembles the pipelines without strategy.BuildPipeline.
+func BuildAndRegisterVoidStrategies() {}
+`,
+}
+
+// TestRegenerateStrategyProject regenerates the strategy layer over the skip files of a legacy
+// project: they are kept, and the regenerated files only use what the legacy files declare.
+func TestRegenerateStrategyProject(t *testing.T) {
+	tpl.RegisterTemplateFunc()
+	dir := t.TempDir()
+	for name, content := range legacyFiles {
+		writeFixture(t, dir, name, content)
+	}
+	writeFixture(t, dir, "echo.thrift", "namespace go api\nstruct EchoReq { 1: string Msg (vt.min_size = \"1\") }\n")
+
+	tplDir := t.TempDir()
+	for _, name := range strategyTemplates {
+		content, err := os.ReadFile(filepath.Join("..", "..", "tpl", "kitex", "server", "standard", name))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(tplDir, name), content, 0o644))
+	}
+	renderStrategy(t, tplDir, dir)
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/echo"
+	c.OutDir = dir
+	assert.NoError(t, generateValidation(c, "example.com/echo/kitex_gen", []string{filepath.Join(dir, "echo.thrift")}))
+
+	for name, content := range legacyFiles {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(got), name)
+	}
+
+	// the selectors are installed in the kept Choose function
+	config, err := os.ReadFile(filepath.Join(dir, "biz", "strategy", "echo_strategy", "config.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(config), "strategy.InstallSelector(&echo.ChooseEchoStrategy, sel)")
+
+	// the kept builder does not build the pipelines with the regenerated strategy.BuildPipeline
+	assert.Equal(t, []string{filepath.Join("biz", "strategy", "echo_strategy", "builder.go")}, legacyBuilders(dir))
+
+	assert.Empty(t, undeclaredRefs(t, dir, "example.com/echo"))
+}
+
+// undeclaredRefs returns the references pkg.Name of the go files in dir to packages of the module
+// declaring no top-level Name, e.g. an identifier only declared by the current skip templates.
+func undeclaredRefs(t *testing.T, dir, module string) []string {
+	fset := token.NewFileSet()
+	files := make(map[string][]*ast.File) // by package dir
+	assert.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		files[filepath.Dir(path)] = append(files[filepath.Dir(path)], f)
+		return nil
+	}))
+
+	declared := func(pkgDir, name string) bool {
+		for _, f := range files[pkgDir] {
+			if f.Scope.Lookup(name) != nil {
+				return true
+			}
+		}
+		return false
+	}
+	var undeclared []string
+	for _, fs := range files {
+		for _, f := range fs {
+			imports := make(map[string]string) // package dir by name
+			for _, imp := range f.Imports {
+				path, _ := strconv.Unquote(imp.Path.Value)
+				if !strings.HasPrefix(path, module+"/") || strings.HasPrefix(path, module+"/kitex_gen/") {
+					continue
+				}
+				name := filepath.Base(path)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				imports[name] = filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(path, module+"/")))
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Obj == nil && imports[pkg.Name] != "" && !declared(imports[pkg.Name], sel.Sel.Name) {
+					undeclared = append(undeclared, fset.Position(sel.Pos()).String()+": "+pkg.Name+"."+sel.Sel.Name)
+				}
+				return true
+			})
+		}
+	}
+	sort.Strings(undeclared)
+	return undeclared
+}
+
+func writeFixture(t *testing.T, dir, name, content string) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+}
+
+// renderStrategy renders the kitex templates of tplDir for the Echo service of echo.thrift into dir.
+func renderStrategy(t *testing.T, tplDir, dir string) {
+	api := generator.PkgInfo{PkgName: "api", PkgRefName: "api", ImportPath: "example.com/echo/kitex_gen/api"}
+	req := []*generator.Parameter{{Deps: []generator.PkgInfo{api}, Name: "Req", RawName: "req", Type: "*api.EchoReq"}}
+	pkg := &generator.PackageInfo{
+		Namespace:    "api",
+		Dependencies: map[string]string{"api": api.ImportPath},
+		Imports:      map[string]map[string]bool{"context": nil, api.ImportPath: nil},
+		ServiceInfo: &generator.ServiceInfo{
+			PkgInfo:        api,
+			ServiceName:    "Echo",
+			RawServiceName: "Echo",
+			Methods: []*generator.MethodInfo{
+				{
+					PkgInfo: api, ServiceName: "Echo", Name: "Echo", RawName: "Echo", Args: req,
+					Resp: &generator.Parameter{Deps: []generator.PkgInfo{api}, Type: "*api.EchoResp"},
+				},
+				{PkgInfo: api, ServiceName: "Echo", Name: "Ping", RawName: "Ping", Args: req, Void: true},
+			},
+		},
+	}
+
+	g := generator.NewGenerator(&generator.Config{TemplateDir: tplDir, OutputPath: dir, ModuleName: "example.com/echo"}, nil)
+	fs, err := g.GenerateCustomPackage(pkg)
+	assert.NoError(t, err)
+	for _, f := range fs {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(f.Name), 0o755))
+		assert.NoError(t, os.WriteFile(f.Name, []byte(f.Content), 0o644))
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/devcert"
 	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/common/merge"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/cwgo/pkg/eino"
@@ -38,18 +40,87 @@ import (
 )
 
 func Server(c *config.ServerArgument) error {
-	var err error
-	err = check(c)
+	err := check(c)
+	if err != nil {
+		return err
+	}
+
+	// the methods generated before, to tell the methods added by this generation
+	before, scanErr := scanServices(c)
+	if scanErr != nil && c.Verbose {
+		log.Warnf("scan the generated methods failed, the methods removed from the IDL are not checked: %v\n", scanErr)
+	}
+
+	if c.Merge || merge.Enabled(c.OutDir) {
+		err = generateMerged(c)
+	} else {
+		err = generate(c)
+	}
+	if err == nil {
+		err = generateErrno(c)
+	}
+	if err == nil && c.TLS {
+		err = generateDevCerts(c)
+	}
+	if err != nil || scanErr != nil {
+		return err
+	}
+
+	after, err := scanServices(c)
+	if err != nil {
+		return err
+	}
+	return pruneMethods(c, before, after)
+}
+
+// generateDevCerts writes the self-signed certificates of certs/ used by the TLS sections of conf.yaml,
+// the existing ones are kept.
+func generateDevCerts(c *config.ServerArgument) error {
+	dir := filepath.Join(c.OutDir, consts.DevCertDir)
+	generated, err := devcert.Generate(dir, c.ServerName)
 	if err != nil {
+		return fmt.Errorf("generate the development certificates failed: %w", err)
+	}
+	if generated {
+		log.Infof("self-signed certificates for development are generated in %s\n", dir)
+	}
+	return nil
+}
+
+// generateMerged generates the code and merges it with the edits of the files generated before.
+func generateMerged(c *config.ServerArgument) error {
+	session, err := merge.Begin(c.OutDir)
+	if err != nil {
+		return err
+	}
+	if err = generate(c); err != nil {
+		if aerr := session.Abort(); aerr != nil {
+			log.Warnf("restore the files of %s failed: %v\n", c.OutDir, aerr)
+		}
 		return err
 	}
+	report, err := session.Finish()
+	if err != nil {
+		return fmt.Errorf("merge the regenerated files failed: %w", err)
+	}
+	if len(report.Conflicts) > 0 {
+		log.Warnf("%d files have merge conflicts, listed in %s:\n%s", len(report.Conflicts), merge.ReportFile, report)
+	} else if c.Verbose {
+		log.Warnf("%s", report)
+	}
+	return nil
+}
 
+func generate(c *config.ServerArgument) error {
+	var err error
 	if c.EnableEino {
 		if err := eino.GenerateEinoAgentModule(c); err != nil {
 			return err
 		}
 	}
 
+	// name of the binary, the kitex service name of the last generated IDL for RPC
+	binary := c.ServerName
 	switch c.Type {
 	case consts.RPC:
 		log.Verbose = c.Verbose
@@ -59,6 +130,12 @@ func Server(c *config.ServerArgument) error {
 			return err
 		}
 
+		// the kitex_gen package and the IDLs of the generated handlers, for the gateway and the validation
+		var (
+			kitexGen    string
+			gatewayIDLs []string
+			builtin     bool
+		)
 		for _, idl := range idls {
 			// Deep-copy embedded pointers to avoid mutating the original config across runs.
 			cc := *c
@@ -97,11 +174,22 @@ func Server(c *config.ServerArgument) error {
 				}
 			}
 
+			binary = cc.ServerName
+
 			var args kargs.Arguments
 			err = convertKitexArgs(&cc, &args)
 			if err != nil {
 				return err
 			}
+			kitexGen = args.PackagePrefix
+			gatewayIDLs = append(gatewayIDLs, idl)
+			builtin = isBuiltinKitexTemplate(args.TemplateDir)
+			if builtin {
+				if args.TemplateDir, err = renderDal(&cc, args.TemplateDir); err != nil {
+					return err
+				}
+				defer os.RemoveAll(args.TemplateDir)
+			}
 			kx_registry.HandleRegistry(cc.CommonParam, args.TemplateDir)
 			defer kx_registry.RemoveExtension()
 
@@ -115,17 +203,34 @@ func Server(c *config.ServerArgument) error {
 						utils.ReplaceThriftVersion()
 					}
 				}
-				os.Exit(1)
+				// exit through cli so that the deferred cleanups and the merge session see the failure
+				return cli.Exit("", 1)
 			}
 			utils.Hessian2PostProcessing(args)
 		}
 
-		if c.Hex { // add http listen for kitex
-			hzArgs, err := hzArgsForHex(c)
-			if err != nil {
+		// the validate processor of the builtin templates calls biz/validation
+		if builtin {
+			if err = generateValidation(c, kitexGen, gatewayIDLs); err != nil {
 				return err
 			}
-			err = app.TriggerPlugin(hzArgs)
+			if err = generateHealthChecks(c); err != nil {
+				return err
+			}
+			for _, b := range legacyBuilders(c.OutDir) {
+				log.Warnf("%s does not build its pipelines with strategy.BuildPipeline, the requests are not validated and the processors are not instrumented, see the builder.go of a new project\n", b)
+			}
+		}
+
+		if c.Hex { // add http listen for kitex
+			if c.Gateway {
+				err = generateGateway(c, kitexGen, gatewayIDLs)
+			} else {
+				var hzArgs *hzConfig.Argument
+				if hzArgs, err = hzArgsForHex(c); err == nil {
+					err = app.TriggerPlugin(hzArgs)
+				}
+			}
 			if err != nil {
 				return err
 			}
@@ -137,6 +242,11 @@ func Server(c *config.ServerArgument) error {
 			if err != nil {
 				log.Warn("please add \"opts = append(opts,server.WithTransHandlerFactory(&mixTransHandlerFactory{nil}))\", to your kitex options")
 			}
+			if c.OpenAPI {
+				if err = generateOpenAPI(c); err != nil {
+					return err
+				}
+			}
 		}
 		utils.ReplaceThriftVersion()
 		utils.UpgradeGolangProtobuf()
@@ -163,10 +273,24 @@ func Server(c *config.ServerArgument) error {
 			} else {
 				args.NeedGoMod = true
 			}
+			builtinLayout := isBuiltinLayout(args.CustomizeLayout)
+			if builtinLayout {
+				dir, err := renderDal(c, filepath.Dir(args.CustomizeLayout))
+				if err != nil {
+					return err
+				}
+				defer os.RemoveAll(dir)
+				args.CustomizeLayout = filepath.Join(dir, filepath.Base(args.CustomizeLayout))
+			}
 			err = app.GenerateLayout(args)
 			if err != nil {
 				return cli.Exit(err, meta.GenerateLayoutError)
 			}
+			if builtinLayout {
+				if err = generateHealthChecks(c); err != nil {
+					return err
+				}
+			}
 			defer func() {
 				// ".hz" file converges to the hz tool
 				manifest := new(meta.Manifest)
@@ -220,7 +344,41 @@ func Server(c *config.ServerArgument) error {
 			return cli.Exit(err, meta.PluginError)
 		}
 		utils.ReplaceThriftVersion()
+		if c.OpenAPI {
+			if err = generateOpenAPI(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.EnableEino {
+		if err = eino.FinishEinoAgentModule(c); err != nil {
+			return err
+		}
+	}
+
+	if c.Deploy {
+		if err = generateDeploy(c, binary); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// legacyBuilders returns the builder.go files of the builtin templates not calling strategy.BuildPipeline,
+// kept from the projects generated before it: they are hand-editable and not regenerated.
+func legacyBuilders(outDir string) (legacy []string) {
+	builders, _ := filepath.Glob(filepath.Join(outDir, "biz", "strategy", "*_strategy", "builder.go"))
+	for _, path := range builders {
+		content, err := os.ReadFile(path)
+		if err != nil || bytes.Contains(content, []byte("strategy.BuildPipeline(")) {
+			continue
+		}
+		if rel, err := filepath.Rel(outDir, path); err == nil {
+			path = rel
+		}
+		legacy = append(legacy, path)
+	}
+	return legacy
+}
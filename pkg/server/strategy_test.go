@@ -0,0 +1,150 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/stretchr/testify/assert"
+)
+
+// strategyTestFixture is the part of a project with an annotated IDL not generated by the templates
+// under test. The kitex client and server are replaced by stubs calling the handler directly.
+var strategyTestFixture = map[string]string{
+	"go.mod": `module example.com/echo
+
+go 1.18
+
+require (
+	github.com/cloudwego/kitex v0.9.1
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+`,
+	"echo.thrift": `
+namespace go api
+struct EchoReq { 1: string Msg (vt.min_size = "1") }
+struct EchoResp { 1: string Msg }
+service Echo {
+    EchoResp Echo(1: EchoReq req)
+    void Ping(1: EchoReq req)
+}
+`,
+	"kitex_gen/api/echo.go": `package api
+
+type EchoReq struct {
+	Msg string
+}
+
+type EchoResp struct {
+	Msg string
+}
+`,
+	"kitex_gen/api/echo/client.go": `package echo
+
+import (
+	"context"
+
+	"example.com/echo/kitex_gen/api"
+)
+
+type Echo interface {
+	Echo(ctx context.Context, req *api.EchoReq) (*api.EchoResp, error)
+	Ping(ctx context.Context, req *api.EchoReq) error
+}
+
+type Client = Echo
+`,
+	"testutil/server.go": `package testutil
+
+import (
+	"context"
+	"testing"
+
+	"example.com/echo/kitex_gen/api/echo"
+)
+
+type Server struct {
+	handler echo.Echo
+}
+
+func StartServer(t testing.TB, handler echo.Echo) *Server {
+	return &Server{handler: handler}
+}
+
+func (s *Server) NewClient(t testing.TB) echo.Client {
+	return s.handler
+}
+
+func Context(t testing.TB) context.Context {
+	return context.Background()
+}
+`,
+	"conf/conf.go": `package conf
+
+func GetEnv() string { return "test" }
+`,
+	"main.go": `package main
+
+func main() {}
+`,
+}
+
+// TestGenerateStrategyTests runs the tests generated for an IDL annotated with validation rules:
+// the empty requests they send are rejected by the validate processor every strategy runs first.
+func TestGenerateStrategyTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the tests of a generated project")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	tpl.RegisterTemplateFunc()
+	dir := t.TempDir()
+	for name, content := range strategyTestFixture {
+		writeFixture(t, dir, name, content)
+	}
+	tplDir := t.TempDir()
+	for _, name := range append([]string{"handler_tpl.yaml", "integration_test_tpl.yaml"}, strategyTemplates...) {
+		content, err := os.ReadFile(filepath.Join("..", "..", "tpl", "kitex", "server", "standard", name))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(tplDir, name), content, 0o644))
+	}
+	renderStrategy(t, tplDir, dir)
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/echo"
+	c.OutDir = dir
+	assert.NoError(t, generateValidation(c, "example.com/echo/kitex_gen", []string{filepath.Join(dir, "echo.thrift")}))
+
+	cmd := exec.Command(goBin, "test", "-v", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOSUMDB=off", "GOWORK=off")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, "go test:\n%s", out)
+	for _, name := range []string{"TestEchoIntegration/Echo", "TestEchoIntegration/Ping", "TestEcho/invalid_request", "TestPing/invalid_request"} {
+		assert.Contains(t, string(out), "--- PASS: "+name+" ", "go test:\n%s", out)
+	}
+}
@@ -0,0 +1,845 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+)
+
+const validationFile = "biz/validation/validation.go"
+
+// validation annotations of thrift-gen-validator and protoc-gen-validate
+const (
+	thriftValidatePrefix = "vt."
+	protoValidateRules   = "validate.rules"
+	protoValidateOff     = "validate.disabled"
+)
+
+type validationData struct {
+	Imports  map[string]string // import path by package alias
+	UTF8     bool
+	Patterns []*validationPattern
+	Roots    []*validationRoot
+	Funcs    []*validationFunc
+}
+
+type validationPattern struct {
+	Var  string
+	Expr string // quoted regular expression
+}
+
+// validationRoot is a request type of the methods, checked by Validate.
+type validationRoot struct {
+	Type string
+	Func string
+}
+
+type validationFunc struct {
+	Name string
+	Type string
+	Body string
+}
+
+// validationRule is a check of a field, Rule is the annotated name reported in the field errors.
+type validationRule struct {
+	Name   string
+	Rule   string
+	Values []string
+}
+
+// generateValidation generates biz/validation from the thrift-gen-validator (vt.*) annotations of
+// thrift fields and the protoc-gen-validate (validate.rules) options of proto fields. Validate
+// checks the requests of the methods and their nested structs, it is called by the validate
+// processor which runs first in the strategy pipelines. The file is rewritten on every generation
+// so that it follows the IDL, rules which can not be checked are reported and ignored.
+func generateValidation(c *config.ServerArgument, kitexGen string, idlPaths []string) error {
+	g := &validationGen{
+		module:   c.GoMod,
+		kitexGen: kitexGen,
+		data:     &validationData{Imports: make(map[string]string)},
+		aliases:  make(map[string]string),
+		funcs:    make(map[string]string),
+		roots:    make(map[string]bool),
+	}
+	for _, p := range idlPaths {
+		idl, err := idlparser.ParseIDL(p, c.SliceParam.ProtoSearchPath)
+		if err != nil {
+			return err
+		}
+		for _, svc := range idl.Services {
+			for _, m := range svc.Methods {
+				if len(m.Args) > 0 && m.Args[0].Type.Kind == idlparser.KindStruct {
+					g.root(idl, m.Args[0].Type.Name)
+				}
+			}
+		}
+	}
+	return writeGoFile(filepath.Join(c.OutDir, validationFile), validationTpl, g.data)
+}
+
+type validationGen struct {
+	module   string
+	kitexGen string
+	data     *validationData
+	aliases  map[string]string // alias by import path
+	funcs    map[string]string // validation function by struct, empty if the struct has no rule
+	roots    map[string]bool
+}
+
+func (g *validationGen) root(idl *idlparser.IDL, name string) {
+	fn := g.structFunc(idl, name)
+	if fn == "" {
+		return
+	}
+	typ := "*" + g.importType(idl, name)
+	if g.roots[typ] {
+		return
+	}
+	g.roots[typ] = true
+	g.data.Roots = append(g.data.Roots, &validationRoot{Type: typ, Func: fn})
+}
+
+func (g *validationGen) importType(idl *idlparser.IDL, name string) string {
+	owner, local := idl.Resolve(name)
+	pkgPath, pkgName := owner.GoImport(g.module, g.kitexGen)
+	alias, ok := g.aliases[pkgPath]
+	if !ok {
+		alias = pkgName
+		for i := 2; g.data.Imports[alias] != "" || isValidationIdent(alias); i++ {
+			alias = pkgName + strconv.Itoa(i)
+		}
+		g.aliases[pkgPath] = alias
+		g.data.Imports[alias] = pkgPath
+	}
+	return alias + "." + idlparser.GoName(owner.Type, local)
+}
+
+// structFunc returns the function validating the struct, generating it on first use, or an empty
+// string if neither the struct nor the structs it holds have rules.
+func (g *validationGen) structFunc(idl *idlparser.IDL, name string) string {
+	owner, local := idl.Resolve(name)
+	key := owner.Path + "#" + local
+	if fn, ok := g.funcs[key]; ok {
+		return fn
+	}
+	s := owner.LookupStruct(local)
+	if !hasValidation(owner, s, make(map[*idlparser.Struct]bool)) {
+		g.funcs[key] = ""
+		return ""
+	}
+	for _, k := range sortedAnnotationKeys(s.Annotations) {
+		if strings.HasPrefix(k, thriftValidatePrefix) {
+			log.Warnf("%s: validation annotation %s is not supported and is not checked\n", s.Name, k)
+		}
+	}
+
+	typ := g.importType(idl, name)
+	fn := &validationFunc{Name: "validate" + idlparser.GoName(consts.Thrift, strings.ReplaceAll(typ, ".", "_")), Type: typ}
+	// registered before the fields so that recursive structs call it
+	g.funcs[key] = fn.Name
+	g.data.Funcs = append(g.data.Funcs, fn)
+	var body strings.Builder
+	for _, f := range s.Fields {
+		body.WriteString(g.fieldCode(owner, s, f))
+	}
+	fn.Body = body.String()
+	return fn.Name
+}
+
+// hasValidation reports whether the struct or one of the structs it holds has rules.
+func hasValidation(idl *idlparser.IDL, s *idlparser.Struct, visited map[*idlparser.Struct]bool) bool {
+	if s == nil || visited[s] {
+		return false
+	}
+	visited[s] = true
+	if off, _ := s.Annotations.Get(protoValidateOff); off == "true" {
+		return false
+	}
+	for _, f := range s.Fields {
+		if f.Oneof {
+			continue
+		}
+		rules, skip, _ := fieldRules(idl.Type, f)
+		if len(rules) > 0 {
+			return true
+		}
+		if skip {
+			continue
+		}
+		t := f.Type
+		if t.Kind == idlparser.KindList || t.Kind == idlparser.KindSet || t.Kind == idlparser.KindMap {
+			t = t.ValueType
+		}
+		if t.Kind == idlparser.KindStruct {
+			owner, local := idl.Resolve(t.Name)
+			if hasValidation(owner, owner.LookupStruct(local), visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldCode returns the checks of the field, followed by the validation of its nested structs.
+func (g *validationGen) fieldCode(idl *idlparser.IDL, s *idlparser.Struct, f *idlparser.Field) string {
+	rules, skip, unsupported := fieldRules(idl.Type, f)
+	for _, key := range unsupported {
+		log.Warnf("%s.%s: validation rule %s is not supported and is not checked\n", s.Name, f.Name, key)
+	}
+	if f.Oneof {
+		if len(rules) > 0 {
+			log.Warnf("%s.%s: validation of oneof fields is not supported and is not checked\n", s.Name, f.Name)
+		}
+		return ""
+	}
+	goField := "v." + idlparser.GoName(idl.Type, f.Name)
+	ptr := isPointerField(f)
+	value := goField
+	if ptr {
+		value = "*" + goField
+	}
+	name := strconv.Quote(f.Name)
+
+	var checks []string
+	var b strings.Builder
+	for _, r := range rules {
+		if r.Name == "required" {
+			if !ptr && !isNillable(f.Type) {
+				log.Warnf("%s.%s: validation rule %s does not apply to %s and is not checked\n", s.Name, f.Name, r.Rule, f.Type.Name)
+				continue
+			}
+			fmt.Fprintf(&b, "\tif %s == nil {\n\t\terrs.add(path, %s, %q, %q)\n\t}\n", goField, name, r.Rule, "is required")
+			continue
+		}
+		cond, msg, err := g.ruleCond(idl, f.Type, value, ptr, r)
+		if err != nil {
+			log.Warnf("%s.%s: validation rule %s %v and is not checked\n", s.Name, f.Name, r.Rule, err)
+			continue
+		}
+		checks = append(checks, fmt.Sprintf("if %s {\n\t\terrs.add(path, %s, %q, %q)\n\t}\n", cond, name, r.Rule, msg))
+	}
+	if !skip {
+		if nested := g.nestedCode(idl, f.Type, goField, name); nested != "" {
+			checks = append(checks, nested)
+		}
+	}
+	if len(checks) == 0 {
+		return b.String()
+	}
+	if ptr || f.Type.Kind == idlparser.KindStruct {
+		fmt.Fprintf(&b, "\tif %s != nil {\n", goField)
+		for _, check := range checks {
+			b.WriteString("\t" + check)
+		}
+		b.WriteString("\t}\n")
+	} else {
+		for _, check := range checks {
+			b.WriteString("\t" + check)
+		}
+	}
+	return b.String()
+}
+
+// nestedCode validates the structs held by the field, which is not nil.
+func (g *validationGen) nestedCode(idl *idlparser.IDL, t *idlparser.Type, goField, name string) string {
+	switch t.Kind {
+	case idlparser.KindStruct:
+		if fn := g.structFunc(idl, t.Name); fn != "" {
+			return fmt.Sprintf("%s(%s, join(path, %s), errs)\n", fn, goField, name)
+		}
+	case idlparser.KindList, idlparser.KindSet, idlparser.KindMap:
+		if t.ValueType.Kind != idlparser.KindStruct {
+			return ""
+		}
+		if fn := g.structFunc(idl, t.ValueType.Name); fn != "" {
+			return fmt.Sprintf("for k, e := range %s {\n\t\tif e != nil {\n\t\t\t%s(e, index(join(path, %s), k), errs)\n\t\t}\n\t}\n", goField, fn, name)
+		}
+	}
+	return ""
+}
+
+// ruleCond returns the condition under which the value breaks the rule, and the message of the error.
+func (g *validationGen) ruleCond(idl *idlparser.IDL, t *idlparser.Type, value string, ptr bool, r *validationRule) (string, string, error) {
+	kind := valueKind(t)
+	arg := ""
+	if len(r.Values) > 0 {
+		arg = r.Values[0]
+	}
+	switch r.Name {
+	case "len", "min_len", "max_len", "len_runes", "min_runes", "max_runes":
+		runes := strings.HasSuffix(r.Name, "_runes")
+		if runes && kind != "string" || !runes && kind != "string" && kind != "bytes" && kind != "list" && kind != "map" {
+			return "", "", fmt.Errorf("does not apply to %s", t.Name)
+		}
+		n, err := strconv.ParseUint(arg, 10, 31)
+		if err != nil {
+			return "", "", fmt.Errorf("has an invalid length %q", arg)
+		}
+		length := "len(" + value + ")"
+		if runes {
+			g.data.UTF8 = true
+			length = "utf8.RuneCountInString(" + value + ")"
+		}
+		switch r.Name {
+		case "min_len", "min_runes":
+			return fmt.Sprintf("%s < %d", length, n), fmt.Sprintf("length must be at least %d", n), nil
+		case "max_len", "max_runes":
+			return fmt.Sprintf("%s > %d", length, n), fmt.Sprintf("length must be at most %d", n), nil
+		default:
+			return fmt.Sprintf("%s != %d", length, n), fmt.Sprintf("length must be %d", n), nil
+		}
+	case "gt", "ge", "lt", "le":
+		if kind != "int" && kind != "uint" && kind != "float" {
+			return "", "", fmt.Errorf("does not apply to %s", t.Name)
+		}
+		lit, err := literal(kind, arg)
+		if err != nil {
+			return "", "", err
+		}
+		var op, msg string
+		switch r.Name {
+		case "gt":
+			op, msg = "<=", "greater than"
+		case "ge":
+			op, msg = "<", "greater than or equal to"
+		case "lt":
+			op, msg = ">=", "less than"
+		default:
+			op, msg = ">", "less than or equal to"
+		}
+		return fmt.Sprintf("%s %s %s", value, op, lit), fmt.Sprintf("must be %s %s", msg, arg), nil
+	case "const", "in", "not_in":
+		if kind != "string" && kind != "int" && kind != "uint" && kind != "float" && kind != "enum" && (kind != "bool" || r.Name != "const") {
+			return "", "", fmt.Errorf("does not apply to %s", t.Name)
+		}
+		var conds []string
+		for _, v := range r.Values {
+			lit, err := literal(kind, v)
+			if err != nil {
+				return "", "", err
+			}
+			conds = append(conds, value+" == "+lit)
+		}
+		values := strings.Join(r.Values, ", ")
+		switch {
+		case r.Name == "not_in":
+			return strings.Join(conds, " || "), fmt.Sprintf("must not be one of [%s]", values), nil
+		case len(conds) == 1:
+			return strings.Replace(conds[0], " == ", " != ", 1), fmt.Sprintf("must be %s", values), nil
+		default:
+			return "!(" + strings.Join(conds, " || ") + ")", fmt.Sprintf("must be one of [%s]", values), nil
+		}
+	case "pattern", "prefix", "suffix", "contains", "not_contains":
+		if kind != "string" {
+			return "", "", fmt.Errorf("does not apply to %s", t.Name)
+		}
+		switch r.Name {
+		case "pattern":
+			if _, err := regexp.Compile(arg); err != nil {
+				return "", "", fmt.Errorf("has an invalid pattern: %v", err)
+			}
+			v := g.pattern(arg)
+			return fmt.Sprintf("!%s.MatchString(%s)", v, value), "must match the pattern " + arg, nil
+		case "prefix":
+			return fmt.Sprintf("!strings.HasPrefix(%s, %q)", value, arg), "must start with " + arg, nil
+		case "suffix":
+			return fmt.Sprintf("!strings.HasSuffix(%s, %q)", value, arg), "must end with " + arg, nil
+		case "contains":
+			return fmt.Sprintf("!strings.Contains(%s, %q)", value, arg), "must contain " + arg, nil
+		default:
+			return fmt.Sprintf("strings.Contains(%s, %q)", value, arg), "must not contain " + arg, nil
+		}
+	case "defined_only":
+		if kind != "enum" {
+			return "", "", fmt.Errorf("does not apply to %s", t.Name)
+		}
+		msg := "must be a defined enum value"
+		if idl.Type == consts.Proto {
+			return fmt.Sprintf("_, ok := %s_name[int32(%s)]; !ok", g.importType(idl, t.Name), value), msg, nil
+		}
+		if ptr {
+			value = "(" + value + ")"
+		}
+		// thriftgo returns <UNSET> for the values which are not declared
+		return fmt.Sprintf("%s.String() == \"<UNSET>\"", value), msg, nil
+	}
+	return "", "", fmt.Errorf("is not supported")
+}
+
+func (g *validationGen) pattern(expr string) string {
+	quoted := strconv.Quote(expr)
+	for _, p := range g.data.Patterns {
+		if p.Expr == quoted {
+			return p.Var
+		}
+	}
+	p := &validationPattern{Var: "pattern" + strconv.Itoa(len(g.data.Patterns)), Expr: quoted}
+	g.data.Patterns = append(g.data.Patterns, p)
+	return p.Var
+}
+
+// fieldRules returns the rules annotated on the field, whether its nested structs are not validated,
+// and the annotated rules which are not supported.
+func fieldRules(idlType string, f *idlparser.Field) (rules []*validationRule, skip bool, unsupported []string) {
+	type pair struct{ key, value string }
+	var pairs []pair
+	if idlType == consts.Thrift {
+		for _, k := range sortedAnnotationKeys(f.Annotations) {
+			if !strings.HasPrefix(k, thriftValidatePrefix) {
+				continue
+			}
+			for _, v := range f.Annotations[k] {
+				pairs = append(pairs, pair{strings.TrimPrefix(k, thriftValidatePrefix), v})
+			}
+		}
+	} else {
+		for _, k := range sortedAnnotationKeys(f.Annotations) {
+			if k != protoValidateRules && !strings.HasPrefix(k, protoValidateRules+".") {
+				continue
+			}
+			prefix := strings.TrimPrefix(strings.TrimPrefix(k, protoValidateRules), ".")
+			for _, v := range f.Annotations[k] {
+				if strings.HasPrefix(strings.TrimSpace(v), "{") {
+					flattenProtoOption(prefix, v, func(key, value string) {
+						pairs = append(pairs, pair{key, value})
+					})
+				} else {
+					pairs = append(pairs, pair{prefix, v})
+				}
+			}
+		}
+	}
+
+	byName := make(map[string]*validationRule)
+	for _, p := range pairs {
+		var name string
+		if idlType == consts.Thrift {
+			name = thriftRule(p.key)
+		} else {
+			name = protoRule(p.key)
+		}
+		value := unquoteRuleValue(p.value)
+		if name == "" || strings.HasPrefix(value, "$") || strings.HasPrefix(value, "@") {
+			unsupported = append(unsupported, p.key)
+			continue
+		}
+		switch name {
+		case "skip", "required", "defined_only":
+			if value != "true" {
+				continue
+			}
+		}
+		if name == "skip" {
+			skip = true
+			continue
+		}
+		if r, ok := byName[name]; ok {
+			r.Values = append(r.Values, value)
+			continue
+		}
+		rule := p.key[strings.LastIndex(p.key, ".")+1:]
+		byName[name] = &validationRule{Name: name, Rule: rule, Values: []string{value}}
+		rules = append(rules, byName[name])
+	}
+	return rules, skip, unsupported
+}
+
+// thriftRule maps a thrift-gen-validator rule to the checks of the generator.
+func thriftRule(key string) string {
+	switch key {
+	case "min_size":
+		return "min_len"
+	case "max_size":
+		return "max_len"
+	case "not_nil":
+		return "required"
+	case "const", "gt", "ge", "lt", "le", "in", "not_in", "pattern", "prefix", "suffix", "contains",
+		"not_contains", "defined_only", "skip":
+		return key
+	}
+	return ""
+}
+
+// protoRule maps a protoc-gen-validate rule, e.g. "string.min_len", to the checks of the generator.
+// The lengths of strings are counted in characters, min_bytes and max_bytes count bytes.
+func protoRule(key string) string {
+	typ, rule, ok := strings.Cut(key, ".")
+	if !ok {
+		return ""
+	}
+	switch typ {
+	case "string":
+		switch rule {
+		case "len":
+			return "len_runes"
+		case "min_len", "max_len":
+			return strings.TrimSuffix(rule, "len") + "runes"
+		case "len_bytes":
+			return "len"
+		case "min_bytes", "max_bytes":
+			return strings.TrimSuffix(rule, "bytes") + "len"
+		case "const", "in", "not_in", "pattern", "prefix", "suffix", "contains", "not_contains":
+			return rule
+		}
+	case "bytes":
+		switch rule {
+		case "len", "min_len", "max_len":
+			return rule
+		}
+	case "int32", "int64", "uint32", "uint64", "sint32", "sint64", "fixed32", "fixed64", "sfixed32", "sfixed64",
+		"float", "double":
+		switch rule {
+		case "const", "gt", "lt", "in", "not_in":
+			return rule
+		case "gte", "lte":
+			return strings.TrimSuffix(rule, "te") + "e"
+		}
+	case "bool":
+		if rule == "const" {
+			return rule
+		}
+	case "enum":
+		switch rule {
+		case "const", "in", "not_in", "defined_only":
+			return rule
+		}
+	case "message":
+		switch rule {
+		case "required", "skip":
+			return rule
+		}
+	case "repeated":
+		switch rule {
+		case "min_items", "max_items":
+			return strings.TrimSuffix(rule, "items") + "len"
+		}
+	case "map":
+		switch rule {
+		case "min_pairs", "max_pairs":
+			return strings.TrimSuffix(rule, "pairs") + "len"
+		}
+	}
+	return ""
+}
+
+// flattenProtoOption flattens the text of an aggregate option, e.g. `{ min_len: 1 in: ["a", "b"] }`,
+// into the dotted keys of its scalar values, e.g. "string.min_len" and twice "string.in".
+func flattenProtoOption(prefix, text string, add func(key, value string)) {
+	toks := tokenizeProtoOption(text)
+	pos := 0
+	var parse func(prefix string)
+	parse = func(prefix string) {
+		for pos < len(toks) {
+			tok := toks[pos]
+			pos++
+			if !tok.str && tok.text == "}" {
+				return
+			}
+			if !tok.str && (tok.text == "{" || tok.text == "," || tok.text == ";" || tok.text == ":") {
+				continue
+			}
+			key := tok.text
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			for pos < len(toks) && !toks[pos].str && toks[pos].text == ":" {
+				pos++
+			}
+			if pos >= len(toks) {
+				return
+			}
+			switch val := toks[pos]; {
+			case !val.str && val.text == "{":
+				pos++
+				parse(key)
+			case !val.str && val.text == "[":
+				for pos++; pos < len(toks) && (toks[pos].str || toks[pos].text != "]"); pos++ {
+					if toks[pos].str || toks[pos].text != "," {
+						add(key, toks[pos].text)
+					}
+				}
+				pos++
+			default:
+				pos++
+				add(key, val.text)
+			}
+		}
+	}
+	parse(prefix)
+}
+
+type optionToken struct {
+	text string
+	str  bool // quoted string, never punctuation
+}
+
+func tokenizeProtoOption(text string) []optionToken {
+	var toks []optionToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.IndexByte("{}[]:,;", c) >= 0:
+			toks = append(toks, optionToken{text: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(text) && text[j] != c {
+				if text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			raw := text[i+1 : min(j, len(text))]
+			s, err := strconv.Unquote(`"` + strings.ReplaceAll(raw, `"`, `\"`) + `"`)
+			if err != nil {
+				s = raw
+			}
+			toks = append(toks, optionToken{text: s, str: true})
+			i = j + 1
+		default:
+			j := i
+			for j < len(text) && !unicode.IsSpace(rune(text[j])) && strings.IndexByte("{}[]:,;\"'", text[j]) < 0 {
+				j++
+			}
+			toks = append(toks, optionToken{text: text[i:j]})
+			i = j
+		}
+	}
+	return toks
+}
+
+// unquoteRuleValue strips the quotes thrift-gen-validator allows around strings, e.g. vt.in = "'a'".
+func unquoteRuleValue(v string) string {
+	if len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// isPointerField reports whether the Go field generated by thriftgo or protoc-gen-go is a pointer.
+func isPointerField(f *idlparser.Field) bool {
+	switch f.Type.Kind {
+	case idlparser.KindStruct:
+		return true
+	case idlparser.KindBase, idlparser.KindEnum:
+		// optional thrift fields and proto3 optional fields, except bytes
+		return f.Optional && f.Type.Name != "binary"
+	}
+	return false
+}
+
+func isNillable(t *idlparser.Type) bool {
+	switch t.Kind {
+	case idlparser.KindList, idlparser.KindSet, idlparser.KindMap:
+		return true
+	}
+	return t.Name == "binary"
+}
+
+// valueKind classifies the Go type of the field for the rules.
+func valueKind(t *idlparser.Type) string {
+	switch t.Kind {
+	case idlparser.KindEnum:
+		return "enum"
+	case idlparser.KindStruct:
+		return "struct"
+	case idlparser.KindList, idlparser.KindSet:
+		return "list"
+	case idlparser.KindMap:
+		return "map"
+	}
+	switch t.Name {
+	case "string":
+		return "string"
+	case "binary":
+		return "bytes"
+	case "bool":
+		return "bool"
+	case "u32", "u64":
+		return "uint"
+	case "float", "double":
+		return "float"
+	}
+	return "int"
+}
+
+// literal returns the Go literal of the rule value compared to a field of the kind.
+func literal(kind, v string) (string, error) {
+	switch kind {
+	case "string":
+		return strconv.Quote(v), nil
+	case "bool":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", fmt.Errorf("has an invalid bool %q", v)
+		}
+		return strconv.FormatBool(b), nil
+	case "uint":
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("has an invalid unsigned integer %q", v)
+		}
+		return strconv.FormatUint(n, 10), nil
+	case "float":
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f != f || f > 1e308 || f < -1e308 {
+			return "", fmt.Errorf("has an invalid number %q", v)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	default: // int and enum
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("has an invalid integer %q", v)
+		}
+		return strconv.FormatInt(n, 10), nil
+	}
+}
+
+func sortedAnnotationKeys(a idlparser.Annotations) []string {
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isValidationIdent reports whether the name is used by biz/validation, so that it can not alias an import.
+func isValidationIdent(name string) bool {
+	switch name {
+	case "json", "http", "regexp", "strings", "utf8", "fmt", "join", "index", "req", "errs", "path", "v", "k", "e":
+		return true
+	}
+	return false
+}
+
+var validationTpl = `// Code generated by cwgo. DO NOT EDIT.
+
+// Package validation checks the requests of the service against the rules annotated on the fields
+// of the IDL, with thrift-gen-validator (vt.*) or protoc-gen-validate (validate.rules).
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+{{- if .Patterns}}
+	"regexp"
+{{- end}}
+	"strings"
+{{- if .UTF8}}
+	"unicode/utf8"
+{{- end}}
+{{range $alias, $path := .Imports}}
+	{{$alias}} "{{$path}}"
+{{- end}}
+)
+
+// Code is the biz status code of the errors returned by Validate.
+var Code int32 = 400
+
+// FieldError is a field of the request breaking a rule, e.g. {"field": "user.name", "rule": "min_len"}.
+type FieldError struct {
+	Field   string ` + "`json:\"field\"`" + `
+	Rule    string ` + "`json:\"rule\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+}
+
+// Errors are the field errors of an invalid request. They implement the biz status error of Kitex,
+// the client receives Code with the field errors as JSON in the "fields" extra.
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+func (e Errors) BizStatusCode() int32 { return Code }
+
+func (e Errors) BizMessage() string { return e.Error() }
+
+func (e Errors) BizExtra() map[string]string {
+	fields, _ := json.Marshal(e)
+	return map[string]string{"fields": string(fields)}
+}
+
+// HTTPStatus is the status of the error when the method is served over HTTP.
+func (e Errors) HTTPStatus() int { return http.StatusBadRequest }
+
+func (e *Errors) add(path, field, rule, message string) {
+	*e = append(*e, &FieldError{Field: join(path, field), Rule: rule, Message: message})
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func index(path string, key interface{}) string {
+	return fmt.Sprintf("%s[%v]", path, key)
+}
+{{- if .Patterns}}
+
+var (
+{{- range .Patterns}}
+	{{.Var}} = regexp.MustCompile({{.Expr}})
+{{- end}}
+)
+{{- end}}
+
+// Validate checks the request of a method, it returns Errors if fields break their rules.
+// Requests without rules are always valid.
+func Validate(req interface{}) error {
+	var errs Errors
+{{- if .Roots}}
+	switch r := req.(type) {
+{{- range .Roots}}
+	case {{.Type}}:
+		if r != nil {
+			{{.Func}}(r, "", &errs)
+		}
+{{- end}}
+	}
+{{- end}}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+{{range .Funcs}}
+func {{.Name}}(v *{{.Type}}, path string, errs *Errors) {
+{{.Body -}}
+}
+{{end}}`
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldRules(t *testing.T) {
+	field := func(annos idlparser.Annotations) *idlparser.Field {
+		return &idlparser.Field{Name: "f", Type: &idlparser.Type{Name: "string"}, Annotations: annos}
+	}
+
+	rules, skip, unsupported := fieldRules(consts.Thrift, field(idlparser.Annotations{
+		"vt.min_size": {"1"},
+		"vt.in":       {"'a'", "\"b\""},
+		"vt.not_nil":  {"false"},
+		"vt.const":    {"$other"},
+		"vt.elem.gt":  {"1"},
+		"api.query":   {"f"},
+	}))
+	assert.Equal(t, []*validationRule{
+		{Name: "in", Rule: "in", Values: []string{"a", "b"}},
+		{Name: "min_len", Rule: "min_size", Values: []string{"1"}},
+	}, rules)
+	assert.False(t, skip)
+	assert.Equal(t, []string{"const", "elem.gt"}, unsupported)
+
+	rules, skip, unsupported = fieldRules(consts.Proto, field(idlparser.Annotations{
+		"validate.rules.string": {`{ min_len: 1 max_bytes: 10 in: : ["a", "b"] email: true }`},
+		"validate.rules":        {`{ message { skip: true } }`},
+	}))
+	assert.Equal(t, []*validationRule{
+		{Name: "min_runes", Rule: "min_len", Values: []string{"1"}},
+		{Name: "max_len", Rule: "max_bytes", Values: []string{"10"}},
+		{Name: "in", Rule: "in", Values: []string{"a", "b"}},
+	}, rules)
+	assert.True(t, skip)
+	assert.Equal(t, []string{"string.email"}, unsupported)
+
+	rules, _, _ = fieldRules(consts.Proto, field(idlparser.Annotations{
+		"validate.rules.int32.gte":     {"0"},
+		"validate.rules.repeated":      {"{ min_items: 2 }"},
+		"validate.rules.string.prefix": {"x"},
+	}))
+	assert.Equal(t, []*validationRule{
+		{Name: "ge", Rule: "gte", Values: []string{"0"}},
+		{Name: "min_len", Rule: "min_items", Values: []string{"2"}},
+		{Name: "prefix", Rule: "prefix", Values: []string{"x"}},
+	}, rules)
+}
+
+func TestGenerateValidation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.thrift": `
+namespace go base
+struct Page { 1: i32 Size (vt.ge = "1", vt.le = "100") }
+`,
+		"hello.thrift": `
+namespace go hello
+include "base.thrift"
+enum Kind { A = 1 }
+struct Item {
+    1: string Name (vt.pattern = "^[a-z]+$")
+    2: list<Item> Children
+}
+struct Req {
+    1: string Name (vt.min_size = "1", vt.prefix = "u_")
+    2: optional i64 Age (vt.lt = "150", vt.not_nil = "true")
+    3: Kind Kind (vt.defined_only = "true")
+    4: list<Item> Items
+    5: base.Page Page
+    6: Item Skipped (vt.skip = "true")
+    7: bool Flag (vt.min_size = "1")
+}
+struct Plain { 1: string Name }
+service Hello {
+    Plain Get(1: Req req)
+    Plain Put(1: Plain req)
+}
+`,
+		"hello.proto": `
+syntax = "proto3";
+package hello;
+option go_package = "hello/pb";
+enum Kind { KIND_UNSPECIFIED = 0; }
+message HelloReq {
+    string name = 1 [(validate.rules).string = {min_len: 1, max_len: 10, in: ["a", "b"]}];
+    optional uint32 age = 2 [(validate.rules).uint32.gte = 18];
+    Kind kind = 3 [(validate.rules).enum.defined_only = true];
+    HelloReq parent = 4 [(validate.rules).message.required = true];
+    oneof choice { string a = 5 [(validate.rules).string.min_len = 1]; }
+}
+service Greeter { rpc SayHello(HelloReq) returns (HelloReq); }
+`,
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	c := config.NewServerArgument()
+	c.GoMod = "example.com/a"
+	c.OutDir = dir
+	err := generateValidation(c, "example.com/a/kitex_gen", []string{filepath.Join(dir, "hello.thrift"), filepath.Join(dir, "hello.proto")})
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, validationFile))
+	assert.NoError(t, err)
+	code := string(content)
+	assert.Contains(t, code, `hello "example.com/a/kitex_gen/hello"`)
+	assert.Contains(t, code, `pb "example.com/a/kitex_gen/hello/pb"`)
+	assert.Contains(t, code, "case *hello.Req:\n\t\tif r != nil {\n\t\t\tvalidateHelloReq(r, \"\", &errs)")
+	assert.Contains(t, code, "case *pb.HelloReq:")
+	assert.NotContains(t, code, "hello.Plain")
+
+	// thrift
+	assert.Contains(t, code, `if len(v.Name) < 1 {`)
+	assert.Contains(t, code, `if !strings.HasPrefix(v.Name, "u_") {`)
+	assert.Contains(t, code, "if v.Age == nil {\n\t\terrs.add(path, \"Age\", \"not_nil\", \"is required\")")
+	assert.Contains(t, code, "if v.Age != nil {\n\t\tif *v.Age >= 150 {")
+	assert.Contains(t, code, `if v.Kind.String() == "<UNSET>" {`)
+	assert.Contains(t, code, `validateHelloItem(e, index(join(path, "Items"), k), errs)`)
+	assert.Contains(t, code, `validateHelloItem(e, index(join(path, "Children"), k), errs)`)
+	assert.Contains(t, code, "if v.Page != nil {\n\t\tvalidateBasePage(v.Page, join(path, \"Page\"), errs)")
+	assert.Contains(t, code, `pattern0 = regexp.MustCompile("^[a-z]+$")`)
+	assert.NotContains(t, code, "Skipped")
+	assert.NotContains(t, code, "v.Flag")
+
+	// proto
+	assert.Contains(t, code, `if utf8.RuneCountInString(v.Name) > 10 {`)
+	assert.Contains(t, code, `if !(v.Name == "a" || v.Name == "b") {`)
+	assert.Contains(t, code, `if *v.Age < 18 {`)
+	assert.Contains(t, code, `if _, ok := pb.Kind_name[int32(v.Kind)]; !ok {`)
+	assert.Contains(t, code, "if v.Parent == nil {")
+	assert.Contains(t, code, `validatePbHelloReq(v.Parent, join(path, "parent"), errs)`)
+	assert.NotContains(t, code, "v.A ")
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/cloudwego/cwgo/config"
+)
+
+const (
+	defaultStrategy = "default"
+
+	onErrorFail = "fail"
+	onErrorSkip = "skip"
+)
+
+// Severity of a check issue, only errors fail the check.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in strategy.yaml.
+type Issue struct {
+	Severity  Severity
+	Operation string
+	Strategy  string
+	Message   string
+}
+
+func (i *Issue) String() string {
+	if i.Strategy == "" {
+		return fmt.Sprintf("%s: %s: %s", i.Severity, i.Operation, i.Message)
+	}
+	return fmt.Sprintf("%s: %s.%s: %s", i.Severity, i.Operation, i.Strategy, i.Message)
+}
+
+// Check validates strategy.yaml against the processors registered in the generated code.
+func Check(c *config.StrategyArgument) error {
+	p, err := LoadProject(c.ProjectPath, c.Service)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(p.ConfigPath())
+	if err != nil {
+		return err
+	}
+	pipelines, err := cfg.Pipelines()
+	if err != nil {
+		return err
+	}
+	selectors, err := cfg.Selectors()
+	if err != nil {
+		return err
+	}
+	policies, err := cfg.Policies()
+	if err != nil {
+		return err
+	}
+	issues := p.Check(pipelines, selectors, policies)
+	return report(os.Stdout, p.ConfigPath(), issues)
+}
+
+// Check reports unknown processors, unused processors, strategies of unknown
+// operations, operations whose strategies miss the default one, selectors
+// picking unknown strategies and invalid processor policies.
+func (p *Project) Check(pipelines Pipelines, selectors Selectors, policies Policies) []*Issue {
+	var issues []*Issue
+	methods := make(map[string]*Method, len(p.Methods))
+	for _, m := range p.Methods {
+		methods[m.ConfigKey] = m
+	}
+
+	for _, op := range sortedKeys(pipelines) {
+		if _, ok := methods[op]; !ok {
+			issues = append(issues, &Issue{Severity: SeverityWarning, Operation: op, Message: "no method is built from this operation, its strategies are never used"})
+		}
+	}
+
+	for _, op := range sortedKeys(selectors) {
+		if _, ok := methods[op]; !ok {
+			issues = append(issues, &Issue{Severity: SeverityWarning, Operation: selectorsKey + "." + op, Message: "no method is built from this operation, its selectors are never used"})
+			continue
+		}
+		strategies := pipelines[op]
+		for i, rule := range selectors[op] {
+			if _, ok := strategies[rule.Strategy]; !ok && !(len(strategies) == 0 && rule.Strategy == defaultStrategy) {
+				issues = append(issues, &Issue{Severity: SeverityError, Operation: selectorsKey + "." + op, Message: fmt.Sprintf("rule %d selects unknown strategy %q", i, rule.Strategy)})
+			}
+			if rule.Percent != nil && (*rule.Percent < 0 || *rule.Percent > 100) {
+				issues = append(issues, &Issue{Severity: SeverityError, Operation: selectorsKey + "." + op, Message: fmt.Sprintf("rule %d: percent %d out of [0, 100]", i, *rule.Percent)})
+			}
+		}
+	}
+
+	for _, op := range sortedKeys(policies) {
+		m, ok := methods[op]
+		if !ok {
+			issues = append(issues, &Issue{Severity: SeverityWarning, Operation: policiesKey + "." + op, Message: "no method is built from this operation, its policies are never used"})
+			continue
+		}
+		for _, name := range sortedKeys(policies[op]) {
+			policy := policies[op][name]
+			if found, complete := m.Registered(name); !found && complete && name != validateProcessor {
+				issues = append(issues, &Issue{Severity: SeverityWarning, Operation: policiesKey + "." + op, Message: fmt.Sprintf("policy of unknown processor %q", name)})
+			}
+			if policy.Timeout < 0 {
+				issues = append(issues, &Issue{Severity: SeverityError, Operation: policiesKey + "." + op, Message: fmt.Sprintf("processor %q: negative timeout %s", name, policy.Timeout)})
+			}
+			switch policy.OnError {
+			case "", onErrorFail, onErrorSkip:
+			default:
+				issues = append(issues, &Issue{Severity: SeverityError, Operation: policiesKey + "." + op, Message: fmt.Sprintf("processor %q: unknown on_error %q, expect %q or %q", name, policy.OnError, onErrorFail, onErrorSkip)})
+			}
+		}
+	}
+
+	for _, m := range p.Methods {
+		strategies := pipelines[m.ConfigKey]
+		if len(strategies) > 0 {
+			if _, ok := strategies[defaultStrategy]; !ok {
+				issues = append(issues, &Issue{Severity: SeverityError, Operation: m.ConfigKey, Message: fmt.Sprintf("missing the %q strategy used when no strategy is chosen", defaultStrategy)})
+			}
+		}
+
+		used := make(map[string]bool)
+		for _, name := range sortedKeys(strategies) {
+			for _, proc := range strategies[name] {
+				used[proc] = true
+				found, complete := m.Registered(proc)
+				switch {
+				case found:
+				case complete:
+					issues = append(issues, &Issue{Severity: SeverityError, Operation: m.ConfigKey, Strategy: name, Message: fmt.Sprintf("unknown processor %q, registered: %v", proc, m.ProcessorNames())})
+				default:
+					issues = append(issues, &Issue{Severity: SeverityWarning, Operation: m.ConfigKey, Strategy: name, Message: fmt.Sprintf("processor %q is not found, some registrations of %s cannot be resolved statically", proc, m.Name)})
+				}
+			}
+		}
+		for _, reg := range m.Processors {
+			if reg.Name != "" && !used[reg.Name] {
+				issues = append(issues, &Issue{Severity: SeverityWarning, Operation: m.ConfigKey, Message: fmt.Sprintf("processor %q registered at %s is not used by any strategy", reg.Name, reg.Pos)})
+			}
+		}
+	}
+	return issues
+}
+
+func report(w io.Writer, path string, issues []*Issue) error {
+	errs := 0
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			errs++
+		}
+		fmt.Fprintln(w, issue)
+	}
+	if errs > 0 {
+		return fmt.Errorf("%s: %d error(s) found", path, errs)
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(w, "%s: ok\n", path)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStrategyFile = `package hello_strategy
+
+import (
+	"fmt"
+
+	"example.com/demo/biz/processor"
+	"example.com/demo/biz/processor/common"
+	"example.com/demo/biz/processor/hello_processor"
+	"example.com/demo/biz/service/hello"
+	"example.com/demo/biz/strategy"
+)
+
+func InitEchoStrategies(cfg strategy.ServiceStrategyConfig) error {
+	reg := strategy.NewProcessorRegistry[hello.EchoState]()
+
+	if err := reg.Register(hello_processor.NewEchoEnrichProcessor()); err != nil {
+		return fmt.Errorf("register processor enrich: %w", err)
+	}
+	if err := reg.Register(processor.NewProcessorFunc[hello.EchoState]("fill", fill)); err != nil {
+		return err
+	}
+
+	if err := reg.Register(processor.WrapGenericProcessor[hello.EchoState]("log", common.LogProcessor)); err != nil {
+		return fmt.Errorf("register generic processor log: %w", err)
+	}
+
+	return BuildAndRegisterStrategies(cfg, "echo", reg, nil, nil, nil, nil)
+}
+`
+
+const testProcessorFile = `package hello_processor
+
+const (
+	enrichName = "enrich"
+	userField  = "Vars.user"
+)
+
+type EchoEnrichProcessor struct{}
+
+func NewEchoEnrichProcessor() processor.Processor[hello.EchoState] {
+	return &EchoEnrichProcessor{}
+}
+
+func (p *EchoEnrichProcessor) Name() string { return enrichName }
+
+func (p *EchoEnrichProcessor) Contract() *processor.Contract {
+	return &processor.Contract{
+		Reads:  []processor.DataField{"Req", userField},
+		Writes: []processor.DataField{"Vars.score"},
+	}
+}
+`
+
+func writeProject(t *testing.T, config string) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                                       "module example.com/demo\n",
+		"biz/strategy/hello_strategy/echo.go":          testStrategyFile,
+		"biz/strategy/hello_strategy/strategy.yaml":    config,
+		"biz/processor/hello_processor/echo_enrich.go": testProcessorFile,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestLoadProject(t *testing.T) {
+	p, err := LoadProject(writeProject(t, ""), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", p.Service)
+	assert.Len(t, p.Methods, 1)
+
+	m := p.Methods[0]
+	assert.Equal(t, "Echo", m.Name)
+	assert.Equal(t, "echo", m.ConfigKey)
+	assert.Equal(t, []string{"enrich", "fill", "log"}, m.ProcessorNames())
+}
+
+func TestCheck(t *testing.T) {
+	dir := writeProject(t, `echo:
+  default: [enrich, log]
+  vip: [enrich, typo]
+removed:
+  default: []
+selectors:
+  echo:
+    - strategy: vip
+      key: x-tier
+    - strategy: gone
+      percent: 10
+policies:
+  echo:
+    enrich:
+      timeout: 200ms
+    log:
+      on_error: ignore
+    validate:
+      on_error: skip
+`)
+	p, err := LoadProject(dir, "hello")
+	assert.NoError(t, err)
+	cfg, err := LoadConfig(p.ConfigPath())
+	assert.NoError(t, err)
+	pipelines, err := cfg.Pipelines()
+	assert.NoError(t, err)
+	selectors, err := cfg.Selectors()
+	assert.NoError(t, err)
+	policies, err := cfg.Policies()
+	assert.NoError(t, err)
+	assert.Equal(t, 200*time.Millisecond, policies["echo"]["enrich"].Timeout)
+
+	var got []string
+	for _, issue := range p.Check(pipelines, selectors, policies) {
+		got = append(got, string(issue.Severity)+" "+issue.Operation+"."+issue.Strategy+" "+strings.SplitN(issue.Message, ",", 2)[0])
+	}
+	assert.Equal(t, []string{
+		`warning removed. no method is built from this operation`,
+		`error selectors.echo. rule 1 selects unknown strategy "gone"`,
+		`error policies.echo. processor "log": unknown on_error "ignore"`,
+		`error echo.vip unknown processor "typo"`,
+		`warning echo. processor "fill" registered at ` + filepath.Join(p.StrategyDir, "echo.go") + `:19:12 is not used by any strategy`,
+	}, got)
+
+	pipelines["echo"] = map[string][]string{"vip": {"enrich", "fill", "log"}}
+	issues := p.Check(pipelines, nil, nil)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, SeverityError, issues[1].Severity)
+	assert.Contains(t, issues[1].Message, `missing the "default" strategy`)
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a strategy.yaml, kept as a yaml node so that edits preserve comments and order.
+type Config struct {
+	path string
+	root *yaml.Node
+}
+
+// Reserved top-level keys of strategy.yaml, they are not operations.
+const (
+	selectorsKey = "selectors"
+	policiesKey  = "policies"
+)
+
+// Pipelines is operation_key -> strategy_name -> ordered processor names, the same
+// shape as the generated strategy.ServiceStrategyConfig.
+type Pipelines map[string]map[string][]string
+
+// Selectors is operation_key -> ordered rules, the strategies picked per request.
+type Selectors map[string][]SelectorRule
+
+// SelectorRule is the part of the generated strategy.SelectorRule checked statically.
+type SelectorRule struct {
+	Strategy string `yaml:"strategy"`
+	Percent  *int   `yaml:"percent"`
+}
+
+// Policies is operation_key -> processor name -> policy.
+type Policies map[string]map[string]Policy
+
+// Policy mirrors the generated processor.Policy.
+type Policy struct {
+	Timeout time.Duration `yaml:"timeout"`
+	OnError string        `yaml:"on_error"`
+}
+
+// LoadConfig reads the strategy.yaml, a missing file is an empty config.
+func LoadConfig(path string) (*Config, error) {
+	c := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parse %s failed: top level must be a mapping of operations", path)
+	}
+	c.root = &doc
+	return c, nil
+}
+
+// Pipelines decodes the strategies of every operation.
+func (c *Config) Pipelines() (Pipelines, error) {
+	p := Pipelines{}
+	m := c.root.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key := m.Content[i].Value
+		if key == selectorsKey || key == policiesKey {
+			continue
+		}
+		var strategies map[string][]string
+		if err := m.Content[i+1].Decode(&strategies); err != nil {
+			return nil, fmt.Errorf("parse %s failed: %s: %v", c.path, key, err)
+		}
+		p[key] = strategies
+	}
+	return p, nil
+}
+
+// Selectors decodes the selectors section.
+func (c *Config) Selectors() (Selectors, error) {
+	s := Selectors{}
+	if node := mappingValue(c.root.Content[0], selectorsKey); node != nil {
+		if err := node.Decode(&s); err != nil {
+			return nil, fmt.Errorf("parse %s failed: %s: %v", c.path, selectorsKey, err)
+		}
+	}
+	return s, nil
+}
+
+// Strategy returns the pipeline node of the strategy, creating the operation and
+// the strategy when create is set.
+func (c *Config) Strategy(operation, strategy string, create bool) *yaml.Node {
+	op := mappingValue(c.root.Content[0], operation)
+	if op == nil || op.Kind != yaml.MappingNode {
+		if !create {
+			return nil
+		}
+		op = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(c.root.Content[0], operation, op)
+	}
+	seq := mappingValue(op, strategy)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		if !create {
+			return nil
+		}
+		seq = &yaml.Node{Kind: yaml.SequenceNode}
+		setMappingValue(op, strategy, seq)
+	}
+	return seq
+}
+
+// AppendProcessors appends processors to the pipeline node.
+func AppendProcessors(seq *yaml.Node, processors ...string) {
+	for _, name := range processors {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name})
+	}
+	if len(seq.Content) > 0 {
+		// "default: []" is written in flow style by the template
+		seq.Style = 0
+	}
+}
+
+// Policies decodes the policies section.
+func (c *Config) Policies() (Policies, error) {
+	p := Policies{}
+	if node := mappingValue(c.root.Content[0], policiesKey); node != nil {
+		if err := node.Decode(&p); err != nil {
+			return nil, fmt.Errorf("parse %s failed: %s: %v", c.path, policiesKey, err)
+		}
+	}
+	return p, nil
+}
+
+// Save writes the config back to its file.
+func (c *Config) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.root); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, buf.Bytes(), 0o644)
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
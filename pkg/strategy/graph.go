@@ -0,0 +1,280 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/cwgo/config"
+)
+
+const (
+	FormatMermaid = "mermaid"
+	FormatDot     = "dot"
+)
+
+// Step is a processor of a pipeline with the problems ValidatePipeline would report.
+type Step struct {
+	Name       string
+	Contract   *Contract // nil if unknown
+	Registered bool
+	Builtin    bool     // run first by strategy.BuildPipeline without being registered
+	Missing    []string // reads not produced by the previous steps
+}
+
+// Violated reports whether the step makes the pipeline fail at startup.
+func (s *Step) Violated() bool {
+	return !s.Registered || len(s.Missing) > 0
+}
+
+// Pipeline is a strategy of a method.
+type Pipeline struct {
+	Method   *Method
+	Strategy string
+	Steps    []*Step
+}
+
+// ResolvePipelines returns the pipelines of the methods with their contracts checked, ordered by
+// method and strategy. The method filter is the Go name or the config key, empty for all.
+func (p *Project) ResolvePipelines(pipelines Pipelines, method string) ([]*Pipeline, error) {
+	methods := p.Methods
+	if method != "" {
+		m, err := p.Method(method)
+		if err != nil {
+			return nil, err
+		}
+		methods = []*Method{m}
+	}
+
+	var result []*Pipeline
+	for _, m := range methods {
+		strategies := pipelines[m.ConfigKey]
+		if len(strategies) == 0 {
+			// the builder falls back to an empty default strategy
+			strategies = map[string][]string{defaultStrategy: nil}
+		}
+		for _, name := range sortedKeys(strategies) {
+			result = append(result, p.pipeline(m, name, strategies[name]))
+		}
+	}
+	return result, nil
+}
+
+func (p *Project) pipeline(m *Method, strategy string, processors []string) *Pipeline {
+	pl := &Pipeline{Method: m, Strategy: strategy}
+	available := make(map[string]bool)
+	for _, f := range p.Inputs {
+		available[f] = true
+	}
+	for _, name := range p.Builtins {
+		pl.Steps = append(pl.Steps, &Step{Name: name, Contract: &Contract{}, Registered: true, Builtin: true})
+	}
+	for _, name := range processors {
+		step := &Step{Name: name}
+		for _, reg := range m.Processors {
+			if reg.Name == name {
+				step.Registered = true
+				step.Contract = reg.Contract
+			}
+		}
+		if found, complete := m.Registered(name); !found && !complete {
+			// cannot tell, do not report it
+			step.Registered = true
+		}
+		if step.Contract != nil {
+			for _, read := range step.Contract.Reads {
+				if !fieldAvailable(available, read) {
+					step.Missing = append(step.Missing, read)
+				}
+			}
+			for _, write := range step.Contract.Writes {
+				available[write] = true
+			}
+		}
+		pl.Steps = append(pl.Steps, step)
+	}
+	return pl
+}
+
+// fieldAvailable follows ValidatePipeline: the field itself or one of its parents is available.
+func fieldAvailable(available map[string]bool, field string) bool {
+	if available[field] {
+		return true
+	}
+	for i := 0; i < len(field); i++ {
+		if field[i] == '.' && available[field[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// Graph writes the diagram of the strategy pipelines.
+func Graph(c *config.StrategyArgument) error {
+	p, err := LoadProject(c.ProjectPath, c.Service)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(p.ConfigPath())
+	if err != nil {
+		return err
+	}
+	pipelines, err := cfg.Pipelines()
+	if err != nil {
+		return err
+	}
+	pls, err := p.ResolvePipelines(pipelines, c.Method)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if c.OutFile != "" {
+		f, err := os.Create(c.OutFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch c.Format {
+	case "", FormatMermaid:
+		WriteMermaid(w, pls)
+	case FormatDot:
+		WriteDot(w, p.Service, pls)
+	default:
+		return fmt.Errorf("unknown graph format '%s', expect %s or %s", c.Format, FormatMermaid, FormatDot)
+	}
+	return nil
+}
+
+// WriteMermaid writes a flowchart with a subgraph per method and per strategy.
+func WriteMermaid(w io.Writer, pls []*Pipeline) {
+	fmt.Fprintln(w, "flowchart LR")
+	var violations []string
+	for i, pl := range pls {
+		if i == 0 || pls[i-1].Method != pl.Method {
+			if i > 0 {
+				fmt.Fprintln(w, "  end")
+			}
+			fmt.Fprintf(w, "  subgraph %s[\"%s (%s)\"]\n", nodeID(pl.Method.ConfigKey), pl.Method.Name, pl.Method.ConfigKey)
+		}
+		id := nodeID(pl.Method.ConfigKey, pl.Strategy)
+		fmt.Fprintf(w, "    subgraph %s[\"%s\"]\n", id, pl.Strategy)
+		fmt.Fprintln(w, "      direction LR")
+		if len(pl.Steps) == 0 {
+			fmt.Fprintf(w, "      %s_empty([\"no processors\"])\n", id)
+		}
+		for j, step := range pl.Steps {
+			stepID := fmt.Sprintf("%s_%d", id, j)
+			fmt.Fprintf(w, "      %s[\"%s\"]\n", stepID, strings.Join(stepLines(step), "<br/>"))
+			if j > 0 {
+				fmt.Fprintf(w, "      %s_%d --> %s\n", id, j-1, stepID)
+			}
+			if step.Violated() {
+				violations = append(violations, stepID)
+			}
+		}
+		fmt.Fprintln(w, "    end")
+	}
+	if len(pls) > 0 {
+		fmt.Fprintln(w, "  end")
+	}
+	if len(violations) > 0 {
+		fmt.Fprintln(w, "  classDef violation fill:#fdd,stroke:#c00,color:#900")
+		fmt.Fprintf(w, "  class %s violation\n", strings.Join(violations, ","))
+	}
+}
+
+// WriteDot writes a Graphviz digraph with a cluster per method and per strategy.
+func WriteDot(w io.Writer, service string, pls []*Pipeline) {
+	fmt.Fprintf(w, "digraph %s {\n", nodeID(service))
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box, fontname=\"Helvetica\"];")
+	for i, pl := range pls {
+		if i == 0 || pls[i-1].Method != pl.Method {
+			if i > 0 {
+				fmt.Fprintln(w, "  }")
+			}
+			fmt.Fprintf(w, "  subgraph cluster_%s {\n", nodeID(pl.Method.ConfigKey))
+			fmt.Fprintf(w, "    label=%q;\n", fmt.Sprintf("%s (%s)", pl.Method.Name, pl.Method.ConfigKey))
+		}
+		id := nodeID(pl.Method.ConfigKey, pl.Strategy)
+		fmt.Fprintf(w, "    subgraph cluster_%s {\n", id)
+		fmt.Fprintf(w, "      label=%q;\n", pl.Strategy)
+		if len(pl.Steps) == 0 {
+			fmt.Fprintf(w, "      %s_empty [label=\"no processors\", shape=plaintext];\n", id)
+		}
+		for j, step := range pl.Steps {
+			attrs := fmt.Sprintf("label=%q", strings.Join(stepLines(step), "\n"))
+			if step.Violated() {
+				attrs += ", color=red, fontcolor=red, style=filled, fillcolor=\"#ffdddd\""
+			}
+			fmt.Fprintf(w, "      %s_%d [%s];\n", id, j, attrs)
+			if j > 0 {
+				fmt.Fprintf(w, "      %s_%d -> %s_%d;\n", id, j-1, id, j)
+			}
+		}
+		fmt.Fprintln(w, "    }")
+	}
+	if len(pls) > 0 {
+		fmt.Fprintln(w, "  }")
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func stepLines(step *Step) []string {
+	lines := []string{step.Name}
+	switch {
+	case step.Builtin:
+		lines = append(lines, "builtin")
+	case !step.Registered:
+		lines = append(lines, "not registered")
+	case step.Contract == nil:
+		lines = append(lines, "contract unknown")
+	default:
+		if len(step.Contract.Reads) > 0 {
+			lines = append(lines, "reads: "+strings.Join(step.Contract.Reads, ", "))
+		}
+		if len(step.Contract.Writes) > 0 {
+			lines = append(lines, "writes: "+strings.Join(step.Contract.Writes, ", "))
+		}
+	}
+	if len(step.Missing) > 0 {
+		lines = append(lines, "missing: "+strings.Join(step.Missing, ", "))
+	}
+	for i, line := range lines {
+		// quotes end the labels of both formats
+		lines[i] = strings.ReplaceAll(line, `"`, "'")
+	}
+	return lines
+}
+
+// nodeID joins the parts into an identifier valid in both Mermaid and DOT.
+func nodeID(parts ...string) string {
+	id := strings.Join(parts, "__")
+	return strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolvePipelines(t *testing.T) {
+	p, err := LoadProject(writeProject(t, ""), "")
+	assert.NoError(t, err)
+	assert.Equal(t, &Contract{Reads: []string{"Req", "Vars.user"}, Writes: []string{"Vars.score"}}, p.Methods[0].Processors[0].Contract)
+
+	pls, err := p.ResolvePipelines(Pipelines{"echo": {"default": {"fill", "enrich"}, "vip": {"typo"}}}, "Echo")
+	assert.NoError(t, err)
+	assert.Len(t, pls, 2)
+
+	enrich := pls[0].Steps[1]
+	assert.True(t, enrich.Registered)
+	assert.Equal(t, []string{"Vars.user"}, enrich.Missing)
+	assert.True(t, enrich.Violated())
+	assert.False(t, pls[0].Steps[0].Violated())
+	assert.False(t, pls[1].Steps[0].Registered)
+
+	var buf bytes.Buffer
+	WriteMermaid(&buf, pls)
+	assert.Contains(t, buf.String(), `echo__default_1["enrich<br/>reads: Req, Vars.user<br/>writes: Vars.score<br/>missing: Vars.user"]`)
+	assert.Contains(t, buf.String(), "echo__default_0 --> echo__default_1")
+	assert.Contains(t, buf.String(), "class echo__default_1,echo__vip_0 violation")
+
+	buf.Reset()
+	WriteDot(&buf, p.Service, pls)
+	assert.Contains(t, buf.String(), `echo__vip_0 [label="typo\nnot registered", color=red`)
+}
+
+func TestResolveBuiltinPipelines(t *testing.T) {
+	dir := writeProject(t, "")
+	builder := "package hello_strategy\n\nfunc build() {\n\tstrategy.BuildPipeline(configKey, strategyName, steps, procRegistry)\n}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "biz", "strategy", "hello_strategy", "builder.go"), []byte(builder), 0o644))
+
+	// biz/processor/vars.go as generated, declaring the pipeline inputs
+	content, err := os.ReadFile(filepath.Join("..", "..", "tpl", "kitex", "server", "standard", "processor_vars.yaml"))
+	assert.NoError(t, err)
+	var tpl struct {
+		Body string `yaml:"body"`
+	}
+	assert.NoError(t, yaml.Unmarshal(content, &tpl))
+	vars := filepath.Join(dir, "biz", "processor", "vars.go")
+	assert.NoError(t, os.WriteFile(vars, []byte(tpl.Body), 0o644))
+
+	p, err := LoadProject(dir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{validateProcessor}, p.Builtins)
+	assert.Equal(t, defaultPipelineInputs, p.Inputs)
+
+	pls, err := p.ResolvePipelines(Pipelines{"echo": {"default": {"fill"}}}, "Echo")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{validateProcessor, "fill"}, []string{pls[0].Steps[0].Name, pls[0].Steps[1].Name})
+	assert.False(t, pls[0].Steps[0].Violated())
+
+	var buf bytes.Buffer
+	WriteMermaid(&buf, pls)
+	assert.Contains(t, buf.String(), `echo__default_0["validate<br/>builtin"]`)
+	assert.Contains(t, buf.String(), "echo__default_0 --> echo__default_1")
+
+	// the inputs declared by the project are used
+	assert.NoError(t, os.WriteFile(vars, []byte("package processor\n\nvar PipelineInputs = []DataField{\"Ctx\", \"Req\", \"Vars.\" + tenant}\n\nconst tenant = \"tenant\"\n"), 0o644))
+	p, err = LoadProject(dir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ctx", "Req", "Vars.tenant"}, p.Inputs)
+}
@@ -0,0 +1,603 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/kitex/tool/internal_pkg/util"
+)
+
+const (
+	strategyDir  = "biz/strategy"
+	processorDir = "biz/processor"
+	serviceDir   = "biz/service"
+	configFile   = "strategy.yaml"
+
+	strategyPkgSuffix  = "_strategy"
+	processorPkgSuffix = "_processor"
+	initFuncPrefix     = "Init"
+	initFuncSuffix     = "Strategies"
+
+	// buildPipelineFunc is strategy.BuildPipeline of biz/strategy/pipeline.go, building the pipelines
+	// of the builders generated since the builtin validate processor.
+	buildPipelineFunc = "BuildPipeline"
+	// validateProcessor is run first by BuildPipeline without being registered, see processor.ValidateProcessor.
+	validateProcessor = "validate"
+	// pipelineInputsVar is processor.PipelineInputs of biz/processor/vars.go, the fields BuildPipeline
+	// passes to ValidatePipeline.
+	pipelineInputsVar = "PipelineInputs"
+	// varStrategy is processor.VarStrategy, the Vars key holding the name of the strategy.
+	varStrategy = "strategy"
+)
+
+// defaultPipelineInputs are the fields available before the first processor of the projects not
+// declaring processor.PipelineInputs, generated before it.
+var defaultPipelineInputs = []string{"Ctx", "Req", "Vars." + varStrategy}
+
+// Project is a kitex project generated with the strategy framework, limited to one service.
+type Project struct {
+	Dir         string
+	Module      string
+	Service     string // snake name of the service, e.g. hello_service
+	StrategyDir string // absolute path of biz/strategy/<service>_strategy
+	Methods     []*Method
+	Builtins    []string // processors run first by every strategy without being registered
+	Inputs      []string // fields available before the first processor
+
+	fset  *token.FileSet
+	files map[string][]*parsedFile // parsed go files by directory
+}
+
+type parsedFile struct {
+	path string
+	file *ast.File
+}
+
+// Method is a method whose strategies are built by Init<Method>Strategies.
+type Method struct {
+	Name       string // Go name, e.g. HelloMethod
+	ConfigKey  string // key in strategy.yaml, e.g. hello_method
+	File       string
+	RegVar     string // name of the processor registry variable
+	Processors []*Registration
+}
+
+// Registration is a processor registered in Init<Method>Strategies.
+type Registration struct {
+	Name     string    // empty if the name cannot be resolved statically
+	Contract *Contract // nil if the contract cannot be resolved statically
+	Expr     string    // source of the registered expression
+	Pos      token.Position
+}
+
+// Contract is the processor.Contract declared by a processor.
+type Contract struct {
+	Reads  []string
+	Writes []string
+}
+
+// ProcessorNames returns the resolved names of the registered processors, sorted.
+func (m *Method) ProcessorNames() []string {
+	var names []string
+	for _, p := range m.Processors {
+		if p.Name != "" {
+			names = append(names, p.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Registered reports whether the processor name is registered, and whether
+// every registration of the method could be resolved.
+func (m *Method) Registered(name string) (found, complete bool) {
+	complete = true
+	for _, p := range m.Processors {
+		if p.Name == name {
+			found = true
+		}
+		if p.Name == "" {
+			complete = false
+		}
+	}
+	return found, complete
+}
+
+// LoadProject finds the strategy package of the service in the project and scans its methods.
+func LoadProject(projectPath, service string) (*Project, error) {
+	dir, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	module, _, ok := utils.SearchGoMod(dir, false)
+	if !ok {
+		return nil, fmt.Errorf("go.mod not found in '%s'", dir)
+	}
+
+	strategyDirs, err := filepath.Glob(filepath.Join(dir, strategyDir, "*"+strategyPkgSuffix))
+	if err != nil {
+		return nil, err
+	}
+	if len(strategyDirs) == 0 {
+		return nil, fmt.Errorf("no strategy package found in '%s', is it generated by the kitex standard template", filepath.Join(dir, strategyDir))
+	}
+
+	var pkgDir string
+	if service == "" {
+		if len(strategyDirs) > 1 {
+			return nil, fmt.Errorf("several strategy packages found, please specify one with --service: %s", strategyServices(strategyDirs))
+		}
+		pkgDir = strategyDirs[0]
+	} else {
+		want := strings.TrimSuffix(util.SnakeString(service), strategyPkgSuffix) + strategyPkgSuffix
+		for _, d := range strategyDirs {
+			if filepath.Base(d) == want {
+				pkgDir = d
+			}
+		}
+		if pkgDir == "" {
+			return nil, fmt.Errorf("strategy package of service '%s' not found, available: %s", service, strategyServices(strategyDirs))
+		}
+	}
+
+	p := &Project{
+		Dir:         dir,
+		Module:      module,
+		Service:     strings.TrimSuffix(filepath.Base(pkgDir), strategyPkgSuffix),
+		StrategyDir: pkgDir,
+		fset:        token.NewFileSet(),
+		files:       make(map[string][]*parsedFile),
+	}
+	if err = p.scanMethods(); err != nil {
+		return nil, err
+	}
+	if err = p.scanPipeline(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func strategyServices(dirs []string) string {
+	names := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		names = append(names, strings.TrimSuffix(filepath.Base(d), strategyPkgSuffix))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Method finds a method by its Go name or its strategy.yaml key.
+func (p *Project) Method(name string) (*Method, error) {
+	for _, m := range p.Methods {
+		if m.Name == name || m.ConfigKey == name || strings.EqualFold(m.Name, name) {
+			return m, nil
+		}
+	}
+	names := make([]string, 0, len(p.Methods))
+	for _, m := range p.Methods {
+		names = append(names, m.Name)
+	}
+	return nil, fmt.Errorf("method '%s' not found in %s, available: %s", name, p.StrategyDir, strings.Join(names, ", "))
+}
+
+// ConfigPath is the path of the strategy.yaml of the service.
+func (p *Project) ConfigPath() string {
+	return filepath.Join(p.StrategyDir, configFile)
+}
+
+func (p *Project) scanMethods() error {
+	files, err := p.parseDir(p.StrategyDir)
+	if err != nil {
+		return err
+	}
+	for _, pf := range files {
+		for _, decl := range pf.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil ||
+				!strings.HasPrefix(fn.Name.Name, initFuncPrefix) || !strings.HasSuffix(fn.Name.Name, initFuncSuffix) {
+				continue
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(fn.Name.Name, initFuncPrefix), initFuncSuffix)
+			if name == "" {
+				continue
+			}
+			m := &Method{
+				Name:      name,
+				ConfigKey: util.SnakeString(name),
+				File:      pf.path,
+				RegVar:    "reg",
+			}
+			p.scanInitFunc(pf, fn, m)
+			p.Methods = append(p.Methods, m)
+		}
+	}
+	sort.Slice(p.Methods, func(i, j int) bool { return p.Methods[i].Name < p.Methods[j].Name })
+	return nil
+}
+
+// scanPipeline finds how the builders of the service build the pipelines: strategy.BuildPipeline
+// runs the builtin validate processor first and validates them with processor.PipelineInputs.
+func (p *Project) scanPipeline() error {
+	files, err := p.parseDir(p.StrategyDir)
+	if err != nil {
+		return err
+	}
+	for _, pf := range files {
+		ast.Inspect(pf.file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && calleeName(call) == buildPipelineFunc {
+				p.Builtins = []string{validateProcessor}
+			}
+			return p.Builtins == nil
+		})
+	}
+
+	p.Inputs = defaultPipelineInputs
+	dir := filepath.Join(p.Dir, filepath.FromSlash(processorDir))
+	files, err = p.parseDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, pf := range files {
+		for _, decl := range pf.file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, n := range vs.Names {
+					if n.Name != pipelineInputsVar || i >= len(vs.Values) {
+						continue
+					}
+					if inputs, ok := p.stringsValue(dir, vs.Values[i]); ok {
+						p.Inputs = inputs
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Project) scanInitFunc(pf *parsedFile, fn *ast.FuncDecl, m *Method) {
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 1 && len(n.Rhs) == 1 && calleeName(n.Rhs[0]) == "NewProcessorRegistry" {
+				if id, ok := n.Lhs[0].(*ast.Ident); ok {
+					m.RegVar = id.Name
+				}
+			}
+		case *ast.CallExpr:
+			switch calleeName(n) {
+			case "BuildAndRegisterStrategies", "BuildAndRegisterVoidStrategies":
+				if len(n.Args) > 1 {
+					if key, ok := p.stringValue(filepath.Dir(pf.path), n.Args[1]); ok {
+						m.ConfigKey = key
+					}
+				}
+				return true
+			}
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "Register" && sel.Sel.Name != "RegisterFunc") {
+				return true
+			}
+			if id, ok := sel.X.(*ast.Ident); !ok || id.Name != m.RegVar || len(n.Args) == 0 {
+				return true
+			}
+			reg := &Registration{Expr: p.source(n.Args[0]), Pos: p.fset.Position(n.Pos())}
+			if sel.Sel.Name == "RegisterFunc" {
+				reg.Name, _ = p.stringValue(filepath.Dir(pf.path), n.Args[0])
+				reg.Contract = &Contract{}
+			} else {
+				reg.Name, reg.Contract = p.resolveProcessor(pf, n.Args[0])
+			}
+			m.Processors = append(m.Processors, reg)
+		}
+		return true
+	})
+}
+
+// resolveProcessor resolves the name and the contract of a registered processor expression,
+// supporting processor.NewProcessorFunc / WrapGenericProcessor calls, constructors of project
+// packages returning &T{} and composite literals, whose Name and Contract methods return
+// constants and literals.
+func (p *Project) resolveProcessor(pf *parsedFile, expr ast.Expr) (string, *Contract) {
+	dir := filepath.Dir(pf.path)
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		switch calleeName(e) {
+		case "NewProcessorFunc", "WrapGenericProcessor":
+			if len(e.Args) > 0 {
+				name, _ := p.stringValue(dir, e.Args[0])
+				return name, &Contract{}
+			}
+			return "", nil
+		}
+		pkgDir, funcName := p.resolveFunc(pf, e.Fun)
+		if pkgDir == "" {
+			return "", nil
+		}
+		files, err := p.parseDir(pkgDir)
+		if err != nil {
+			return "", nil
+		}
+		for _, f := range files {
+			for _, decl := range f.file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || fn.Name.Name != funcName || fn.Body == nil {
+					continue
+				}
+				if ret := singleReturn(fn); ret != nil {
+					return p.resolveProcessor(f, ret)
+				}
+			}
+		}
+	case *ast.UnaryExpr:
+		return p.resolveProcessor(pf, e.X)
+	case *ast.CompositeLit:
+		pkgDir, typeName := p.resolveFunc(pf, e.Type)
+		if pkgDir == "" {
+			return "", nil
+		}
+		var name string
+		if ret, _ := p.methodReturn(pkgDir, typeName, "Name"); ret != nil {
+			name, _ = p.stringValue(pkgDir, ret)
+		}
+		ret, f := p.methodReturn(pkgDir, typeName, "Contract")
+		if ret == nil {
+			return name, nil
+		}
+		return name, p.contractValue(f, ret)
+	}
+	return "", nil
+}
+
+// resolveFunc returns the directory and the name of a function or type referenced
+// as Name or pkg.Name, following imports of the project module only.
+func (p *Project) resolveFunc(pf *parsedFile, expr ast.Expr) (string, string) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return filepath.Dir(pf.path), e.Name
+	case *ast.IndexExpr:
+		return p.resolveFunc(pf, e.X)
+	case *ast.IndexListExpr:
+		return p.resolveFunc(pf, e.X)
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return "", ""
+		}
+		for _, imp := range pf.file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := filepath.Base(path)
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			if name != pkg.Name || !strings.HasPrefix(path, p.Module+"/") {
+				continue
+			}
+			return filepath.Join(p.Dir, filepath.FromSlash(strings.TrimPrefix(path, p.Module+"/"))), e.Sel.Name
+		}
+	}
+	return "", ""
+}
+
+// methodReturn returns the single result of the return statement of a method of the type.
+func (p *Project) methodReturn(dir, typeName, method string) (ast.Expr, *parsedFile) {
+	files, err := p.parseDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	for _, f := range files {
+		for _, decl := range f.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != method || fn.Body == nil || len(fn.Recv.List) == 0 {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if id, ok := recv.(*ast.Ident); !ok || id.Name != typeName {
+				continue
+			}
+			return singleReturn(fn), f
+		}
+	}
+	return nil, nil
+}
+
+func singleReturn(fn *ast.FuncDecl) ast.Expr {
+	for _, stmt := range fn.Body.List {
+		if ret, ok := stmt.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			return ret.Results[0]
+		}
+	}
+	return nil
+}
+
+// contractValue evaluates a &processor.Contract{Reads: ..., Writes: ...} literal, nil is an empty contract.
+func (p *Project) contractValue(pf *parsedFile, expr ast.Expr) *Contract {
+	if u, ok := expr.(*ast.UnaryExpr); ok {
+		expr = u.X
+	}
+	if id, ok := expr.(*ast.Ident); ok && id.Name == "nil" {
+		return &Contract{}
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	dir := filepath.Dir(pf.path)
+	c := &Contract{}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil
+		}
+		key, _ := kv.Key.(*ast.Ident)
+		fields, ok := p.stringsValue(dir, kv.Value)
+		if key == nil || !ok {
+			return nil
+		}
+		switch key.Name {
+		case "Reads":
+			c.Reads = fields
+		case "Writes":
+			c.Writes = fields
+		}
+	}
+	return c
+}
+
+// stringsValue evaluates a slice literal of strings.
+func (p *Project) stringsValue(dir string, expr ast.Expr) ([]string, bool) {
+	if id, ok := expr.(*ast.Ident); ok && id.Name == "nil" {
+		return nil, true
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	values := make([]string, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		v, ok := p.stringValue(dir, elt)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+// stringValue evaluates a string literal, a string constant declared in the package or their concatenation.
+func (p *Project) stringValue(dir string, expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, ok := p.stringValue(dir, e.X)
+			if !ok {
+				return "", false
+			}
+			y, ok := p.stringValue(dir, e.Y)
+			return x + y, ok
+		}
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			s, err := strconv.Unquote(e.Value)
+			return s, err == nil
+		}
+	case *ast.Ident:
+		files, err := p.parseDir(dir)
+		if err != nil {
+			return "", false
+		}
+		for _, f := range files {
+			for _, decl := range f.file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, n := range vs.Names {
+						if n.Name == e.Name && i < len(vs.Values) {
+							return p.stringValue(dir, vs.Values[i])
+						}
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func (p *Project) parseDir(dir string) ([]*parsedFile, error) {
+	if files, ok := p.files[dir]; ok {
+		return files, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var files []*parsedFile
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(p.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &parsedFile{path: path, file: f})
+	}
+	p.files[dir] = files
+	return files, nil
+}
+
+// reload drops the parsed files of dir, after they have been rewritten.
+func (p *Project) reload(dir string) {
+	delete(p.files, dir)
+}
+
+func (p *Project) source(n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, p.fset, n); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// calleeName returns the function name of a call, ignoring package and type arguments.
+func calleeName(expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	fun := call.Fun
+	for {
+		switch f := fun.(type) {
+		case *ast.IndexExpr:
+			fun = f.X
+		case *ast.IndexListExpr:
+			fun = f.X
+		case *ast.SelectorExpr:
+			return f.Sel.Name
+		case *ast.Ident:
+			return f.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+	"github.com/cloudwego/kitex/tool/internal_pkg/util"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+type processorData struct {
+	Module   string
+	Service  string
+	Package  string
+	Method   string
+	Name     string
+	TypeName string
+	Reads    []string
+	Writes   []string
+}
+
+// AddProcessor scaffolds a processor for the method, registers it in
+// Init<Method>Strategies and appends it to a pipeline of strategy.yaml.
+func AddProcessor(c *config.StrategyArgument) error {
+	if c.Method == "" || c.Name == "" {
+		return errors.New("both --method and --name are required")
+	}
+	p, err := LoadProject(c.ProjectPath, c.Service)
+	if err != nil {
+		return err
+	}
+	m, err := p.Method(c.Method)
+	if err != nil {
+		return err
+	}
+	if found, _ := m.Registered(c.Name); found {
+		return fmt.Errorf("processor '%s' is already registered for %s", c.Name, m.Name)
+	}
+
+	reads := c.Reads
+	if len(reads) == 0 {
+		reads = []string{"Req"}
+	}
+	data := &processorData{
+		Module:   p.Module,
+		Service:  p.Service,
+		Package:  p.Service + processorPkgSuffix,
+		Method:   m.Name,
+		Name:     c.Name,
+		TypeName: m.Name + toCamel(c.Name) + "Processor",
+		Reads:    reads,
+		Writes:   c.Writes,
+	}
+	pkgDir := filepath.Join(p.Dir, processorDir, data.Package)
+	file := filepath.Join(pkgDir, util.SnakeString(m.Name+toCamel(c.Name))+".go")
+	if fileExists(file) {
+		log.Warnf("%s already exists, only register it\n", file)
+	} else if err = writeGoTemplate(file, processorTpl, data); err != nil {
+		return err
+	}
+
+	pkgPath := p.Module + "/" + processorDir + "/" + data.Package
+	call := fmt.Sprintf("%s.New%s()", data.Package, data.TypeName)
+	if err = p.register(m, call, pkgPath, c.Name); err != nil {
+		return err
+	}
+
+	if c.Strategy != "" {
+		cfg, err := LoadConfig(p.ConfigPath())
+		if err != nil {
+			return err
+		}
+		AppendProcessors(cfg.Strategy(m.ConfigKey, c.Strategy, true), c.Name)
+		if err = cfg.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddStrategy adds a named pipeline of registered processors for the method to strategy.yaml.
+func AddStrategy(c *config.StrategyArgument) error {
+	if c.Method == "" || c.Name == "" {
+		return errors.New("both --method and --name are required")
+	}
+	p, err := LoadProject(c.ProjectPath, c.Service)
+	if err != nil {
+		return err
+	}
+	m, err := p.Method(c.Method)
+	if err != nil {
+		return err
+	}
+	for _, name := range c.Processors {
+		found, complete := m.Registered(name)
+		if found {
+			continue
+		}
+		if !complete {
+			log.Warnf("processor '%s' is not found in the statically resolved processors of %s\n", name, m.Name)
+			continue
+		}
+		return fmt.Errorf("processor '%s' is not registered for %s, available: %s", name, m.Name, strings.Join(m.ProcessorNames(), ", "))
+	}
+
+	cfg, err := LoadConfig(p.ConfigPath())
+	if err != nil {
+		return err
+	}
+	if cfg.Strategy(m.ConfigKey, c.Name, false) != nil {
+		return fmt.Errorf("strategy '%s' of %s already exists in %s", c.Name, m.ConfigKey, p.ConfigPath())
+	}
+	AppendProcessors(cfg.Strategy(m.ConfigKey, c.Name, true), c.Processors...)
+	return cfg.Save()
+}
+
+// List prints the registered processors and the strategies of every method.
+func List(c *config.StrategyArgument) error {
+	p, err := LoadProject(c.ProjectPath, c.Service)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(p.ConfigPath())
+	if err != nil {
+		return err
+	}
+	pipelines, err := cfg.Pipelines()
+	if err != nil {
+		return err
+	}
+	return p.list(os.Stdout, c.Method, pipelines)
+}
+
+func (p *Project) list(w io.Writer, method string, pipelines Pipelines) error {
+	methods := p.Methods
+	if method != "" {
+		m, err := p.Method(method)
+		if err != nil {
+			return err
+		}
+		methods = []*Method{m}
+	}
+	fmt.Fprintf(w, "service %s (%s)\n", p.Service, p.ConfigPath())
+	for _, m := range methods {
+		fmt.Fprintf(w, "\n%s (%s)\n", m.Name, m.ConfigKey)
+		fmt.Fprintln(w, "  processors:")
+		for _, reg := range m.Processors {
+			name := reg.Name
+			if name == "" {
+				name = "? " + reg.Expr
+			}
+			fmt.Fprintf(w, "    - %s\n", name)
+		}
+		fmt.Fprintln(w, "  strategies:")
+		strategies := pipelines[m.ConfigKey]
+		for _, name := range sortedKeys(strategies) {
+			fmt.Fprintf(w, "    %s: [%s]\n", name, strings.Join(strategies[name], ", "))
+		}
+	}
+	return nil
+}
+
+// register adds "if err := reg.Register(call); err != nil {...}" to Init<Method>Strategies,
+// after the last service-specific registration or, if none, before the generic ones.
+func (p *Project) register(m *Method, call, pkgPath, name string) error {
+	src, err := os.ReadFile(m.File)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, m.File, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	var fn *ast.FuncDecl
+	for _, decl := range f.Decls {
+		if d, ok := decl.(*ast.FuncDecl); ok && d.Recv == nil && d.Name.Name == initFuncPrefix+m.Name+initFuncSuffix {
+			fn = d
+		}
+	}
+	if fn == nil {
+		return fmt.Errorf("%s%s%s not found in %s", initFuncPrefix, m.Name, initFuncSuffix, m.File)
+	}
+
+	var lastSpecific, firstGeneric, ret ast.Stmt
+	for _, stmt := range fn.Body.List {
+		if r, ok := stmt.(*ast.ReturnStmt); ok {
+			ret = r
+			continue
+		}
+		regCall := registerCall(stmt, m.RegVar)
+		if regCall == nil {
+			continue
+		}
+		if calleeName(regCall.Args[0]) == "WrapGenericProcessor" {
+			if firstGeneric == nil {
+				firstGeneric = stmt
+			}
+		} else {
+			lastSpecific = stmt
+		}
+	}
+
+	code := fmt.Sprintf("if err := %s.Register(%s); err != nil {\n\treturn fmt.Errorf(\"register processor %s: %%w\", err)\n}\n", m.RegVar, call, name)
+	var offset int
+	switch {
+	case lastSpecific != nil:
+		offset = fset.Position(lastSpecific.End()).Offset
+		code = "\n\n" + code
+	case firstGeneric != nil:
+		offset = leadingOffset(fset, f, firstGeneric)
+		code += "\n"
+	case ret != nil:
+		offset = leadingOffset(fset, f, ret)
+		code += "\n"
+	default:
+		return fmt.Errorf("no place to register the processor in %s", m.File)
+	}
+	src = append(src[:offset:offset], append([]byte(code), src[offset:]...)...)
+
+	f, err = parser.ParseFile(fset, m.File, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	astutil.AddImport(fset, f, pkgPath)
+	astutil.AddImport(fset, f, "fmt")
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, f); err != nil {
+		return err
+	}
+	p.reload(filepath.Dir(m.File))
+	return os.WriteFile(m.File, buf.Bytes(), 0o644)
+}
+
+// registerCall returns the reg.Register call of statements like "if err := reg.Register(x); err != nil".
+func registerCall(stmt ast.Stmt, regVar string) *ast.CallExpr {
+	var expr ast.Expr
+	switch s := stmt.(type) {
+	case *ast.IfStmt:
+		if assign, ok := s.Init.(*ast.AssignStmt); ok && len(assign.Rhs) == 1 {
+			expr = assign.Rhs[0]
+		}
+	case *ast.ExprStmt:
+		expr = s.X
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Register" {
+		return nil
+	}
+	if id, ok := sel.X.(*ast.Ident); !ok || id.Name != regVar {
+		return nil
+	}
+	return call
+}
+
+// leadingOffset is the offset of the statement, including the comment right above it.
+func leadingOffset(fset *token.FileSet, f *ast.File, stmt ast.Stmt) int {
+	pos := stmt.Pos()
+	line := fset.Position(pos).Line
+	for _, cg := range f.Comments {
+		if fset.Position(cg.End()).Line == line-1 {
+			pos = cg.Pos()
+			break
+		}
+	}
+	p := fset.Position(pos)
+	return p.Offset - (p.Column - 1)
+}
+
+func writeGoTemplate(path, tpl string, data interface{}) error {
+	tmpl, err := template.New(filepath.Base(path)).Parse(tpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format %s failed: %v", path, err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// toCamel converts a snake or kebab processor name to a Go identifier part.
+func toCamel(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' || r == ' ' })
+	for i, part := range parts {
+		parts[i] = util.UpperFirst(part)
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package strategy
+
+var processorTpl = `package {{.Package}}
+
+import (
+	"{{.Module}}/biz/processor"
+	"{{.Module}}/biz/service/{{.Service}}"
+)
+
+// {{.TypeName}} is the "{{.Name}}" step of the {{.Method}} strategies.
+type {{.TypeName}} struct{}
+
+func New{{.TypeName}}() processor.Processor[{{.Service}}.{{.Method}}State] {
+	return &{{.TypeName}}{}
+}
+
+func (p *{{.TypeName}}) Name() string { return "{{.Name}}" }
+
+func (p *{{.TypeName}}) Contract() *processor.Contract {
+	return &processor.Contract{
+		Reads: []processor.DataField{ {{- range $i, $f := .Reads}}{{if $i}}, {{end}}"{{$f}}"{{end -}} },
+		Writes: []processor.DataField{ {{- range $i, $f := .Writes}}{{if $i}}, {{end}}"{{$f}}"{{end -}} },
+	}
+}
+
+func (p *{{.TypeName}}) Process(s *{{.Service}}.{{.Method}}State) error {
+	// TODO: implement the "{{.Name}}" step
+	return nil
+}
+`
@@ -0,0 +1,299 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+// defaultRef names the cache entry of the default branch.
+const defaultRef = "default"
+
+// Entry is a template repository checked out at a ref in the cache.
+type Entry struct {
+	Repo   string
+	Ref    string // empty for the default branch
+	Dir    string
+	Commit string
+}
+
+// CacheDir returns the directory caching the template repositories,
+// $CWGO_TEMPLATE_CACHE or cwgo/templates in the user cache directory.
+func CacheDir() (string, error) {
+	if dir := os.Getenv(consts.CwgoTemplateCache); dir != "" {
+		return filepath.Abs(dir)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("get user cache dir failed: %v, set %s instead", err, consts.CwgoTemplateCache)
+	}
+	return filepath.Join(dir, "cwgo", "templates"), nil
+}
+
+// repoKey maps a git url to a relative path, e.g. both https://github.com/org/tpl.git
+// and git@github.com:org/tpl.git become github.com/org/tpl.
+func repoKey(repo string) (string, error) {
+	repo = strings.TrimSpace(repo)
+	var host, p string
+	u, err := url.Parse(repo)
+	switch {
+	case err == nil && u.Scheme == "file":
+		host, p = "local", u.Path
+	case err == nil && u.Scheme != "" && u.Host != "":
+		host, p = u.Hostname(), u.Path
+	case strings.Contains(repo, ":") && !filepath.IsAbs(repo) && !strings.Contains(repo[:strings.Index(repo, ":")], consts.Slash):
+		// scp-like syntax, [user@]host:path
+		i := strings.Index(repo, ":")
+		host, p = repo[:i], repo[i+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	default:
+		host, p = "local", repo
+	}
+	p = strings.TrimSuffix(strings.Trim(filepath.ToSlash(p), consts.Slash), consts.SuffixGit)
+	if host == "" || p == "" {
+		return "", fmt.Errorf("invalid template repository '%s'", repo)
+	}
+	parts := strings.Split(p, consts.Slash)
+	for _, part := range parts {
+		if part == ".." {
+			return "", fmt.Errorf("invalid template repository '%s'", repo)
+		}
+	}
+	return host + consts.Slash + strings.Join(parts, consts.Slash), nil
+}
+
+// entryDir is the cache directory of repo at ref.
+func entryDir(repo, ref string) (string, error) {
+	root, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	key, err := repoKey(repo)
+	if err != nil {
+		return "", err
+	}
+	if ref == "" {
+		ref = defaultRef
+	}
+	return filepath.Join(root, filepath.FromSlash(key)+"@"+url.PathEscape(ref)), nil
+}
+
+// Cached returns the cache entry of repo at ref, nil when it isn't cached.
+func Cached(repo, ref string) (*Entry, error) {
+	dir, err := entryDir(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := utils.PathExist(filepath.Join(dir, consts.SuffixGit)); !ok {
+		return nil, nil
+	}
+	commit, err := utils.GitRevParse("HEAD", dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{Repo: repo, Ref: ref, Dir: dir, Commit: commit}, nil
+}
+
+// Fetch returns the cache entry of repo at ref, cloning it when it isn't cached.
+// A cached entry is used as is, so generating works offline.
+func Fetch(repo, ref string) (*Entry, error) {
+	e, err := Cached(repo, ref)
+	if err != nil || e != nil {
+		return e, err
+	}
+	dir, err := entryDir(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, err
+	}
+	// clone next to the entry and rename, an interrupted clone leaves no broken entry
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".clone-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err = utils.GitCloneTo(repo, tmp); err != nil {
+		return nil, fmt.Errorf("clone template %s failed: %v", repo, err)
+	}
+	commit, err := checkoutRef(tmp, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+	return &Entry{Repo: repo, Ref: ref, Dir: dir, Commit: commit}, nil
+}
+
+// Update fetches origin and moves the entry to the latest commit of its ref.
+func (e *Entry) Update() error {
+	if err := utils.GitFetch(e.Dir); err != nil {
+		return fmt.Errorf("fetch template %s failed: %v", e.Repo, err)
+	}
+	commit, err := checkoutRef(e.Dir, e.Ref)
+	if err != nil {
+		return err
+	}
+	e.Commit = commit
+	return nil
+}
+
+// Export writes the files of commit to dst, fetching origin when the commit is unknown. The entry
+// stays at its ref, as other projects share it.
+func (e *Entry) Export(commit, dst string) error {
+	if _, err := utils.GitRevParse(commit, e.Dir); err != nil {
+		if err = utils.GitFetch(e.Dir); err != nil {
+			return fmt.Errorf("commit %s of template %s is not cached and fetching failed: %v", commit, e.Repo, err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := utils.GitArchive(commit, e.Dir, &buf); err != nil {
+		return fmt.Errorf("export commit %s of template %s failed: %v", commit, e.Repo, err)
+	}
+	return untar(&buf, dst)
+}
+
+// untar extracts the directories and regular files of the tar archive to dst.
+func untar(r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(h.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path '%s' in the template archive", h.Name)
+		}
+		p := filepath.Join(dst, name)
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(p, 0o755)
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				return err
+			}
+			var data []byte
+			if data, err = io.ReadAll(tr); err != nil {
+				return err
+			}
+			err = os.WriteFile(p, data, 0o644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkoutRef checks out the remote branch, the tag or the commit named ref,
+// or the default branch when ref is empty.
+func checkoutRef(dir, ref string) (string, error) {
+	revs := []string{"origin/HEAD"}
+	if ref != "" {
+		revs = []string{"origin/" + ref, ref}
+	}
+	for _, rev := range revs {
+		commit, err := utils.GitRevParse(rev, dir)
+		if err != nil {
+			continue
+		}
+		if err = utils.GitCheckoutCommit(commit, dir); err != nil {
+			return "", err
+		}
+		return commit, nil
+	}
+	return "", fmt.Errorf("ref '%s' not found in the template repository", ref)
+}
+
+// Entries lists the cached templates sorted by repository and ref.
+func Entries() ([]*Entry, error) {
+	root, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		i := strings.LastIndex(d.Name(), "@")
+		if i < 0 {
+			return nil
+		}
+		if ok, _ := utils.PathExist(filepath.Join(p, consts.SuffixGit)); !ok {
+			return nil
+		}
+		ref, err := url.PathUnescape(d.Name()[i+1:])
+		if err != nil {
+			return nil
+		}
+		if ref == defaultRef {
+			ref = ""
+		}
+		e := &Entry{Ref: ref, Dir: p}
+		e.Repo, _ = utils.GitRemoteURL(p)
+		e.Commit, _ = utils.GitRevParse("HEAD", p)
+		entries = append(entries, e)
+		return filepath.SkipDir
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Repo != entries[j].Repo {
+			return entries[i].Repo < entries[j].Repo
+		}
+		return entries[i].Ref < entries[j].Ref
+	})
+	return entries, err
+}
+
+// sameRepo reports whether both urls name the same repository.
+func sameRepo(a, b string) bool {
+	ka, err := repoKey(a)
+	if err != nil {
+		return false
+	}
+	kb, err := repoKey(b)
+	return err == nil && ka == kb
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoKey(t *testing.T) {
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"https://github.com/org/tpl.git", "github.com/org/tpl"},
+		{"https://user@github.com:443/org/tpl.git", "github.com/org/tpl"},
+		{"ssh://git@github.com/org/tpl.git", "github.com/org/tpl"},
+		{"git@github.com:org/tpl.git", "github.com/org/tpl"},
+		{"github.com:org/group/tpl.git", "github.com/org/group/tpl"},
+		{"file:///srv/git/tpl.git", "local/srv/git/tpl"},
+		{"/srv/git/tpl.git", "local/srv/git/tpl"},
+	}
+	for _, tt := range tests {
+		got, err := repoKey(tt.repo)
+		assert.NoError(t, err, tt.repo)
+		assert.Equal(t, tt.want, got, tt.repo)
+	}
+
+	for _, repo := range []string{"", "https://github.com/", "https://github.com/org/../../etc.git"} {
+		_, err := repoKey(repo)
+		assert.Error(t, err, repo)
+	}
+}
+
+func TestEntryDir(t *testing.T) {
+	t.Setenv(consts.CwgoTemplateCache, t.TempDir())
+	root, err := CacheDir()
+	assert.NoError(t, err)
+
+	dir, err := entryDir("git@github.com:org/tpl.git", "")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "github.com", "org", "tpl@default"), dir)
+
+	dir, err = entryDir("https://github.com/org/tpl.git", "feature/x")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "github.com", "org", "tpl@feature%2Fx"), dir)
+
+	assert.True(t, sameRepo("git@github.com:org/tpl.git", "https://github.com/org/tpl.git"))
+	assert.False(t, sameRepo("git@github.com:org/tpl.git", "https://github.com/org/other.git"))
+}
+
+func TestProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	pc, err := LoadProjectConfig(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, pc.PinOf("https://github.com/org/tpl.git"))
+
+	pc.Template = &Pin{Repo: "git@github.com:org/tpl.git", Ref: "v1.0.0", Commit: "abc"}
+	assert.NoError(t, pc.Save(dir))
+
+	pc, err = LoadProjectConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, &Pin{Repo: "git@github.com:org/tpl.git", Ref: "v1.0.0", Commit: "abc"}, pc.PinOf("https://github.com/org/tpl.git"))
+}
+
+// TestPreparePinned prepares a project pinned to an older commit of the cached ref, the cache
+// entry shared with other projects stays at the ref.
+func TestPreparePinned(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	t.Setenv(consts.CwgoTemplateCache, t.TempDir())
+	repo := filepath.Join(t.TempDir(), "tpl.git")
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=cwgo", "-c", "user.email=cwgo@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, "git %v:\n%s", args, out)
+		return string(out)
+	}
+	commit := func(content string) string {
+		assert.NoError(t, os.WriteFile(filepath.Join(repo, "layout.yaml"), []byte(content), 0o644))
+		git("add", "-A")
+		git("commit", "-q", "-m", content)
+		return git("rev-parse", "HEAD")[:40]
+	}
+	assert.NoError(t, os.MkdirAll(repo, 0o755))
+	git("init", "-q")
+	v1 := commit("v1")
+	v2 := commit("v2")
+
+	e, err := Fetch(repo, "")
+	assert.NoError(t, err)
+	assert.Equal(t, v2, e.Commit)
+
+	project := t.TempDir()
+	pc := &ProjectConfig{Template: &Pin{Repo: repo, Commit: v1}}
+	assert.NoError(t, pc.Save(project))
+	dst, err := Prepare(repo, "", project, t.TempDir())
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dst, "layout.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	e, err = Cached(repo, "")
+	assert.NoError(t, err)
+	assert.Equal(t, v2, e.Commit)
+	dst, err = Prepare(repo, "", t.TempDir(), t.TempDir())
+	assert.NoError(t, err)
+	content, err = os.ReadFile(filepath.Join(dst, "layout.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+}
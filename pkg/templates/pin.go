@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfig is the .cwgo.yaml of a project.
+type ProjectConfig struct {
+	Template *Pin `yaml:"template,omitempty"`
+}
+
+// Pin is the template version a project is generated with.
+type Pin struct {
+	Repo   string `yaml:"repo"`
+	Ref    string `yaml:"ref,omitempty"`
+	Commit string `yaml:"commit"`
+}
+
+// LoadProjectConfig reads .cwgo.yaml of the project dir, an empty config when it doesn't exist.
+func LoadProjectConfig(dir string) (*ProjectConfig, error) {
+	c := new(ProjectConfig)
+	data, err := os.ReadFile(filepath.Join(dir, consts.ProjectConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", consts.ProjectConfigFile, err)
+	}
+	return c, nil
+}
+
+// Save writes .cwgo.yaml to the project dir.
+func (c *ProjectConfig) Save(dir string) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, consts.ProjectConfigFile), buf.Bytes(), 0o644)
+}
+
+// PinOf returns the pin of repo, nil when the project isn't pinned to it.
+func (c *ProjectConfig) PinOf(repo string) *Pin {
+	if c.Template == nil || !sameRepo(c.Template.Repo, repo) {
+		return nil
+	}
+	return c.Template
+}
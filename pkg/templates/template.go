@@ -0,0 +1,217 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package templates manages the git templates of cwgo server and client in a local
+// cache keyed by repository and ref, and pins their versions in the project config.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+// Builtin templates of cwgo, used when --template is not given.
+var builtin = []struct{ Name, Type, Usage string }{
+	{consts.Standard, consts.RPC, "kitex server and client"},
+	{consts.Standard, consts.HTTP, "hertz server and client"},
+	{consts.StandardV2, consts.HTTP, "hertz server with the router and service layers split"},
+}
+
+// List prints the builtin templates and the cached ones, the one the project is pinned to is starred.
+func List(c *config.TemplateArgument) error {
+	pc, err := LoadProjectConfig(c.ProjectPath)
+	if err != nil {
+		return err
+	}
+	entries, err := Entries()
+	if err != nil {
+		return err
+	}
+	return list(os.Stdout, pc, entries)
+}
+
+func list(w io.Writer, pc *ProjectConfig, entries []*Entry) error {
+	fmt.Fprintln(w, "builtin:")
+	for _, b := range builtin {
+		fmt.Fprintf(w, "  %-12s %-5s %s\n", b.Name, b.Type, b.Usage)
+	}
+	fmt.Fprintln(w, "cached:")
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "  none, run cwgo template pull --repo {{git_url}}")
+	}
+	for _, e := range entries {
+		mark := " "
+		if pin := pc.PinOf(e.Repo); pin != nil && pin.Ref == e.Ref {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s@%s %s %s\n", mark, e.Repo, refName(e.Ref), shortCommit(e.Commit), e.Dir)
+	}
+	if pc.Template != nil {
+		fmt.Fprintf(w, "pinned: %s@%s %s\n", pc.Template.Repo, refName(pc.Template.Ref), shortCommit(pc.Template.Commit))
+	}
+	return nil
+}
+
+// Pull caches the template at ref and, with --pin, pins the project to its commit.
+func Pull(c *config.TemplateArgument) error {
+	if c.Repo == "" {
+		return errors.New("must specify the template repository with --repo")
+	}
+	e, err := Fetch(c.Repo, c.Ref)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s@%s %s %s\n", c.Repo, refName(c.Ref), shortCommit(e.Commit), e.Dir)
+	if c.Pin {
+		return pin(c.ProjectPath, e)
+	}
+	return nil
+}
+
+// Update moves the cached templates, or the one of --repo and --ref, to the latest
+// commit of their ref. With --pin the project pin follows the update.
+func Update(c *config.TemplateArgument) error {
+	var entries []*Entry
+	if c.Repo != "" {
+		e, err := Cached(c.Repo, c.Ref)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return fmt.Errorf("template %s@%s is not cached, run cwgo template pull first", c.Repo, refName(c.Ref))
+		}
+		entries = append(entries, e)
+	} else {
+		var err error
+		if entries, err = Entries(); err != nil {
+			return err
+		}
+	}
+
+	pc, err := LoadProjectConfig(c.ProjectPath)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		old := e.Commit
+		if err = e.Update(); err != nil {
+			return err
+		}
+		fmt.Printf("%s@%s %s -> %s\n", e.Repo, refName(e.Ref), shortCommit(old), shortCommit(e.Commit))
+		if p := pc.PinOf(e.Repo); c.Pin && p != nil && p.Ref == e.Ref {
+			if err = pin(c.ProjectPath, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Prepare copies the template repo to workDir for generating the project of projectDir,
+// and returns the copy. Without ref, the version pinned by the project is used. The
+// template comes from the cache, it is only cloned when missing, and a pinned commit is
+// exported from the entry of its ref without moving it.
+func Prepare(repo, ref, projectDir, workDir string) (string, error) {
+	var commit string
+	if ref == "" && projectDir != "" {
+		pc, err := LoadProjectConfig(projectDir)
+		if err != nil {
+			return "", err
+		}
+		if p := pc.PinOf(repo); p != nil {
+			ref, commit = p.Ref, p.Commit
+		}
+	}
+	e, err := Fetch(repo, ref)
+	if err != nil {
+		return "", err
+	}
+	name, err := utils.GitPath(repo)
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(workDir, name)
+	if err = os.RemoveAll(dst); err != nil {
+		return "", err
+	}
+	if commit != "" && !strings.HasPrefix(e.Commit, commit) {
+		return dst, e.Export(commit, dst)
+	}
+	return dst, copyDir(e.Dir, dst)
+}
+
+func pin(projectDir string, e *Entry) error {
+	pc, err := LoadProjectConfig(projectDir)
+	if err != nil {
+		return err
+	}
+	pc.Template = &Pin{Repo: e.Repo, Ref: e.Ref, Commit: e.Commit}
+	if err = pc.Save(projectDir); err != nil {
+		return err
+	}
+	fmt.Printf("pinned %s@%s to %s in %s\n", e.Repo, refName(e.Ref), shortCommit(e.Commit), filepath.Join(projectDir, consts.ProjectConfigFile))
+	return nil
+}
+
+// copyDir copies the files of src to dst without the git metadata.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == consts.SuffixGit {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+}
+
+func refName(ref string) string {
+	if ref == "" {
+		return defaultRef
+	}
+	return ref
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
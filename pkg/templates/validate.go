@@ -0,0 +1,259 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	hzGenerator "github.com/cloudwego/hertz/cmd/hz/generator"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"gopkg.in/yaml.v3"
+)
+
+// updateTypes are the update behaviors kitex and hz support.
+var updateTypes = map[string]bool{"": true, "skip": true, "cover": true, "append": true}
+
+// Issue is a problem found in a template file.
+type Issue struct {
+	File    string
+	Path    string // path of the template entry, empty for problems of the whole file
+	Message string
+}
+
+func (i *Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.File, i.Path, i.Message)
+}
+
+// Validate checks that the template dir is well-formed before cwgo server or client uses it.
+func Validate(c *config.TemplateArgument) error {
+	dir := c.Dir
+	if dir == "" && c.Repo != "" {
+		e, err := Cached(c.Repo, c.Ref)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			return fmt.Errorf("template %s is not cached, run cwgo template pull first", c.Repo)
+		}
+		dir = e.Dir
+	}
+	if dir == "" {
+		return errors.New("must specify the template dir with --dir or the cached template with --repo")
+	}
+	issues, err := ValidateDir(dir, c.Type)
+	if err != nil {
+		return err
+	}
+	return report(os.Stdout, dir, issues)
+}
+
+// ValidateDir checks the hz layout.yaml and package.yaml of an HTTP template dir, or
+// the kitex template YAMLs of an RPC one. typ is detected from the files when empty.
+func ValidateDir(dir, typ string) ([]*Issue, error) {
+	if fi, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	layout := filepath.Join(dir, consts.LayoutFile)
+	pkg := filepath.Join(dir, consts.PackageLayoutFile)
+	hasLayout, _ := utils.PathExist(layout)
+	hasPkg, _ := utils.PathExist(pkg)
+	if typ == "" {
+		typ = consts.RPC
+		if hasLayout || hasPkg {
+			typ = consts.HTTP
+		}
+	}
+
+	var issues []*Issue
+	switch typ {
+	case consts.HTTP:
+		if !hasLayout && !hasPkg {
+			return nil, fmt.Errorf("%s has neither %s nor %s", dir, consts.LayoutFile, consts.PackageLayoutFile)
+		}
+		for _, f := range []string{layout, pkg} {
+			if ok, _ := utils.PathExist(f); ok {
+				is, err := validateHz(f)
+				if err != nil {
+					return nil, err
+				}
+				issues = append(issues, is...)
+			}
+		}
+	case consts.RPC:
+		files, err := kitexTemplates(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("%s has no kitex template yaml", dir)
+		}
+		for _, f := range files {
+			is, err := validateKitex(f)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, is...)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported template type '%s'", typ)
+	}
+	return issues, nil
+}
+
+// kitexTemplates lists the files kitex reads as templates.
+func kitexTemplates(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == consts.KitexExtensionYaml {
+			continue
+		}
+		if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func validateHz(file string) ([]*Issue, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(file)
+	var cfg hzGenerator.TemplateConfig
+	if err = decodeStrict(data, &cfg); err != nil {
+		return []*Issue{{File: name, Message: err.Error()}}, nil
+	}
+	if len(cfg.Layouts) == 0 {
+		return []*Issue{{File: name, Message: "no layouts"}}, nil
+	}
+
+	var issues []*Issue
+	seen := make(map[string]bool)
+	for i, l := range cfg.Layouts {
+		add := func(format string, args ...interface{}) {
+			p := l.Path
+			if p == "" {
+				p = fmt.Sprintf("layouts[%d]", i)
+			}
+			issues = append(issues, &Issue{File: name, Path: p, Message: fmt.Sprintf(format, args...)})
+		}
+		if l.Path == "" {
+			add("path is empty")
+		} else if seen[l.Path] {
+			add("duplicated path")
+		}
+		seen[l.Path] = true
+		left, right := l.Delims[0], l.Delims[1]
+		if (left == "") != (right == "") {
+			add("delims must set both the left and the right delimiter")
+			continue
+		}
+		if !updateTypes[l.UpdateBehavior.Type] {
+			add("unknown update_behavior type '%s'", l.UpdateBehavior.Type)
+		}
+		if err = parseTemplate(l.Body, left, right); err != nil {
+			add("body: %v", err)
+		}
+	}
+	return issues, nil
+}
+
+func validateKitex(file string) ([]*Issue, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(file)
+	var t generator.Template
+	if err = decodeStrict(data, &t); err != nil {
+		return []*Issue{{File: name, Message: err.Error()}}, nil
+	}
+
+	var issues []*Issue
+	add := func(format string, args ...interface{}) {
+		issues = append(issues, &Issue{File: name, Path: t.Path, Message: fmt.Sprintf(format, args...)})
+	}
+	if t.Path == "" {
+		add("path is empty")
+		return issues, nil
+	}
+	if err = parseTemplate(t.Path, "", ""); err != nil {
+		add("path: %v", err)
+	}
+	if !strings.HasSuffix(t.Path, consts.Slash) && strings.TrimSpace(t.Body) == "" {
+		add("body is empty, end the path with / to only create a directory")
+	}
+	if err = parseTemplate(t.Body, "", ""); err != nil {
+		add("body: %v", err)
+	}
+	if t.UpdateBehavior != nil && !updateTypes[t.UpdateBehavior.Type] {
+		add("unknown update_behavior type '%s'", t.UpdateBehavior.Type)
+	}
+	return issues, nil
+}
+
+// decodeStrict rejects unknown keys, they are usually typos silently ignored by kitex and hz.
+func decodeStrict(data []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// parseTemplate checks the template syntax, functions are provided by kitex and hz so they aren't checked.
+func parseTemplate(text, left, right string) error {
+	t := parse.New("template")
+	t.Mode = parse.SkipFuncCheck
+	_, err := t.Parse(text, left, right, make(map[string]*parse.Tree))
+	return err
+}
+
+func report(w io.Writer, dir string, issues []*Issue) error {
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%s: %d error(s) found", dir, len(issues))
+	}
+	fmt.Fprintf(w, "%s: ok\n", dir)
+	return nil
+}
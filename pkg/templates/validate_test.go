@@ -0,0 +1,123 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestValidateKitex(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.yaml": "path: main.go\nupdate_behavior:\n  type: skip\nbody: |-\n  package main\n  {{- range .Methods}}\n  // {{ToLower .Name}}\n  {{- end}}\n",
+		"dir.yaml":  "path: biz/service/\n",
+		// kitex ignores the extension file and the files of sub directories
+		consts.KitexExtensionYaml: "dependencies: {}\n",
+	})
+	issues, err := ValidateDir(dir, "")
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	dir = writeFiles(t, map[string]string{
+		"typo.yaml":    "path: a.go\nbody: x\nupdate_behaviour:\n  type: skip\n",
+		"empty.yaml":   "body: x\n",
+		"syntax.yaml":  "path: '{{.Name'\nbody: '{{if .X}}'\n",
+		"update.yaml":  "path: c.go\nbody: x\nupdate_behavior:\n  type: merge\n",
+		"nobody.yaml":  "path: d.go\n",
+		"readme.md":    "not a template",
+		"correct.yaml": "path: e.go\nbody: x\n",
+	})
+	issues, err = ValidateDir(dir, consts.RPC)
+	assert.NoError(t, err)
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.File+" "+issue.Path)
+	}
+	assert.Equal(t, []string{
+		"empty.yaml ",
+		"nobody.yaml d.go",
+		"syntax.yaml {{.Name",
+		"syntax.yaml {{.Name",
+		"typo.yaml ",
+		"update.yaml c.go",
+	}, got)
+}
+
+func TestValidateHz(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		consts.LayoutFile: `layouts:
+  - path: main.go
+    delims:
+      - ""
+      - ""
+    body: |-
+      package main
+      {{- if .NeedGoMod}}
+      {{end}}
+  - path: conf/conf.yaml
+    delims:
+      - "[["
+      - "]]"
+    body: "service: [[.ServiceName]]"
+`,
+	})
+	issues, err := ValidateDir(dir, "")
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+
+	dir = writeFiles(t, map[string]string{
+		consts.LayoutFile: `layouts:
+  - path: main.go
+    body: "{{end}}"
+  - path: main.go
+    delims: ["[[", ""]
+    body: x
+  - body: x
+    update_behavior:
+      type: merge
+`,
+		consts.PackageLayoutFile: "layout:\n  - path: handler.go\n",
+	})
+	issues, err = ValidateDir(dir, consts.HTTP)
+	assert.NoError(t, err)
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	assert.Len(t, got, 6)
+	assert.Contains(t, got[0], "main.go: body:")
+	assert.Equal(t, "layout.yaml: main.go: duplicated path", got[1])
+	assert.Equal(t, "layout.yaml: main.go: delims must set both the left and the right delimiter", got[2])
+	assert.Equal(t, "layout.yaml: layouts[2]: path is empty", got[3])
+	assert.Equal(t, "layout.yaml: layouts[2]: unknown update_behavior type 'merge'", got[4])
+	assert.Contains(t, got[5], "package.yaml: yaml: unmarshal errors")
+
+	_, err = ValidateDir(t.TempDir(), consts.HTTP)
+	assert.Error(t, err)
+}